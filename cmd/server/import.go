@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/importer"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+	"github.com/panaalexandrucristian/feedback-collector/internal/sentiment"
+)
+
+const importUsage = `usage: server import [-dry-run] [-format csv|json] <room-id> <file>
+
+Imports feedback into a room in a single transaction. The format defaults to
+the file extension; use - as the file to read standard input.`
+
+// runImport implements the `import` subcommand. Entries that were not analysed
+// before are classified once the import has been committed.
+func runImport(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	dryRun := flags.Bool("dry-run", false, "validate without importing")
+	format := flags.String("format", "", "csv or json")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		return errors.New(importUsage)
+	}
+	roomID, path := flags.Arg(0), flags.Arg(1)
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if *format == "jsonl" || *format == "ndjson" {
+			*format = importer.FormatJSON
+		}
+	}
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	store, err := db.Open(cfg.DatabaseDriver, cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	room, err := middleware.FindRoom(store, roomID)
+	if err != nil {
+		return err
+	}
+	roomID = room.ID
+	if room.Type != models.RoomTypeStandard {
+		return fmt.Errorf("room %s is a %s room; feedback can only be imported into standard rooms", roomID, room.Type)
+	}
+
+	result, imported, err := importer.Import(store, roomID, *format, input, *dryRun, time.Now())
+	if errors.Is(err, importer.ErrUnknownFormat) {
+		return fmt.Errorf("%w; pass -format csv or -format json", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(result.Errors) > 0 {
+		for _, problem := range result.Errors {
+			if problem.Field != "" {
+				fmt.Printf("row %d: %s: %s\n", problem.Row, problem.Field, problem.Error)
+			} else {
+				fmt.Printf("row %d: %s\n", problem.Row, problem.Error)
+			}
+		}
+		return fmt.Errorf("%d problems in %d rows; nothing was imported", len(result.Errors), result.Rows)
+	}
+	if *dryRun {
+		fmt.Printf("Dry run: %d rows are valid and would be imported\n", result.Imported)
+		return nil
+	}
+
+	analyzer := sentiment.NewLexiconAnalyzer()
+	for _, f := range imported {
+		if f.Sentiment != sentiment.Pending {
+			continue
+		}
+		if err := sentiment.Process(analyzer, store, f.ID, f.Content); err != nil {
+			log.Printf("Failed to analyse feedback %d: %v\n", f.ID, err)
+		}
+	}
+
+	fmt.Printf("Imported %d feedback entries into room %s\n", result.Imported, roomID)
+	return nil
+}
@@ -10,30 +10,100 @@ import (
 	"time"
 
 	"github.com/panaalexandrucristian/feedback-collector/internal/api"
+	"github.com/panaalexandrucristian/feedback-collector/internal/broadcast"
 	"github.com/panaalexandrucristian/feedback-collector/internal/config"
 	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/jobs"
+	"github.com/panaalexandrucristian/feedback-collector/internal/roomcode"
+	"github.com/panaalexandrucristian/feedback-collector/internal/scheduler"
+	"github.com/panaalexandrucristian/feedback-collector/internal/sentiment"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.Load()
-	log.Printf("Starting server in %s mode on port %d\n", cfg.Environment, cfg.Port)
+
+	// Dispatch subcommands; with no arguments the server is started
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+		case "migrate":
+			if err := runMigrate(cfg, os.Args[2:]); err != nil {
+				log.Fatalf("Migration failed: %v", err)
+			}
+			return
+		case "sentiment":
+			if err := runSentiment(cfg, os.Args[2:]); err != nil {
+				log.Fatalf("Sentiment command failed: %v", err)
+			}
+			return
+		case "import":
+			if err := runImport(cfg, os.Args[2:]); err != nil {
+				log.Fatalf("Import failed: %v", err)
+			}
+			return
+		default:
+			log.Fatalf("Unknown command %q (expected serve, migrate, sentiment or import)", os.Args[1])
+		}
+	}
+
+	serve(cfg)
+}
+
+// serve runs the HTTP server until it receives SIGINT or SIGTERM
+func serve(cfg *config.Config) {
+	log.Printf("Starting server in %s mode on port %d (%s storage)\n", cfg.Environment, cfg.Port, cfg.DatabaseDriver)
 
 	// Connect to database
-	database, err := db.New(cfg.DatabaseURL)
+	database, err := db.Open(cfg.DatabaseDriver, cfg.DatabaseURL)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer database.Close()
 
+	// Background tasks run until shutdown begins
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go purgeExpired(background, database)
+
+	// Room and feedback events are logged and relayed to live stream subscribers
+	bus := events.NewBus()
+	bus.Subscribe(func(event events.Event) {
+		log.Printf("Event %s for room %s\n", event.Type, event.RoomID)
+	})
+	hub := events.NewHub()
+	relay, err := broadcast.New(cfg.DatabaseDriver, cfg.DatabaseURL, hub, database)
+	if err != nil {
+		log.Fatalf("Failed to start event broadcaster: %v", err)
+	}
+	defer relay.Close()
+	bus.Subscribe(relay.Publish)
+
+	// Open and close rooms according to their schedule
+	go scheduler.New(database, bus, cfg.SchedulerInterval).Run(background)
+
+	// Room code generator
+	codes, err := roomcode.NewGenerator(cfg.RoomCodeAlphabet, cfg.RoomCodeLength)
+	if err != nil {
+		log.Fatalf("Invalid room code configuration: %v", err)
+	}
+
+	// Durable background jobs, such as sentiment analysis of new feedback
+	queue := jobs.New(database, cfg.JobWorkers, cfg.JobPollInterval)
+	queue.Register(sentiment.JobKind, sentiment.NewJobHandler(sentiment.NewLexiconAnalyzer(), database, bus))
+	queue.Start()
+
 	// Setup router
-	router := api.SetupRouter(cfg, database)
+	router := api.SetupRouter(cfg, database, codes, queue, bus, hub)
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:    cfg.GetPortString(),
 		Handler: router,
 	}
+	// End live streams so that Shutdown does not wait for them
+	server.RegisterOnShutdown(hub.Close)
 
 	// Start server in a goroutine
 	go func() {
@@ -47,6 +117,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopBackground()
 
 	// Give the server 5 seconds to finish ongoing requests
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -55,5 +126,37 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Let running jobs finish; anything left is picked up on the next start
+	jobsCtx, cancelJobs := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelJobs()
+	if err := queue.Stop(jobsCtx); err != nil {
+		log.Printf("Background jobs interrupted: %v\n", err)
+	}
+
 	log.Println("Server exited gracefully")
 }
+
+// finishedJobRetention is how long completed jobs are kept before being purged
+const finishedJobRetention = 7 * 24 * time.Hour
+
+// purgeExpired periodically removes expired refresh tokens, denylist entries and
+// old completed jobs
+func purgeExpired(ctx context.Context, store db.Store) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := store.PurgeExpiredTokens(); err != nil {
+			log.Printf("Failed to purge expired tokens: %v\n", err)
+		}
+		if err := store.PurgeFinishedJobs(time.Now().Add(-finishedJobRetention)); err != nil {
+			log.Printf("Failed to purge finished jobs: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/migrate"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up         apply all pending migrations
+  down N     revert the N most recently applied migrations
+  status     list migrations and whether they are applied
+  force V    mark the schema as being at version V without running SQL`
+
+// runMigrate implements the `migrate` subcommand
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	conn, err := db.Connect(cfg.DatabaseDriver, cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	migrator, err := migrate.New(conn, cfg.DatabaseDriver)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up()
+	case "down":
+		n, err := intArg(args, "down")
+		if err != nil {
+			return err
+		}
+		return migrator.Down(n)
+	case "force":
+		version, err := intArg(args, "force")
+		if err != nil {
+			return err
+		}
+		return migrator.Force(version)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		return printStatus(statuses)
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", args[0], migrateUsage)
+	}
+}
+
+// intArg parses the single integer argument of a migrate command
+func intArg(args []string, command string) (int, error) {
+	if len(args) != 2 {
+		return 0, fmt.Errorf("migrate %s requires exactly one numeric argument\n\n%s", command, migrateUsage)
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid argument %q for migrate %s: %w", args[1], command, err)
+	}
+	return n, nil
+}
+
+func printStatus(statuses []migrate.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, s := range statuses {
+		state := "pending"
+		switch {
+		case s.Dirty:
+			state = "dirty"
+		case s.ChecksumMismatch:
+			state = "modified"
+		case s.Applied:
+			state = "applied"
+		}
+
+		appliedAt := "-"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
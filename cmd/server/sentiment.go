@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/sentiment"
+)
+
+const sentimentUsage = `usage: server sentiment <command>
+
+commands:
+  backfill   analyse every feedback entry still marked as pending`
+
+// backfillBatchSize is the number of pending entries loaded per query
+const backfillBatchSize = 500
+
+// runSentiment implements the `sentiment` subcommand
+func runSentiment(cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "backfill" {
+		return errors.New(sentimentUsage)
+	}
+
+	store, err := db.Open(cfg.DatabaseDriver, cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	analyzer := sentiment.NewLexiconAnalyzer()
+	processed, failed := 0, 0
+	lastID := 0
+	for {
+		pending, err := store.GetPendingFeedback(lastID, backfillBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		for _, f := range pending {
+			lastID = f.ID
+			if err := sentiment.Process(analyzer, store, f.ID, f.Content); err != nil {
+				log.Printf("Failed to analyse feedback %d: %v\n", f.ID, err)
+				failed++
+				continue
+			}
+			processed++
+		}
+	}
+
+	fmt.Printf("Analysed %d feedback entries (%d failed)\n", processed, failed)
+	return nil
+}
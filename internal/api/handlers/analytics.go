@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// AnalyticsHandler handles reporting routes
+type AnalyticsHandler struct {
+	DB db.Store
+}
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler(db db.Store) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		DB: db,
+	}
+}
+
+// defaultAnalyticsPeriods is the period covered for each interval when no from is given
+var defaultAnalyticsPeriods = map[string]time.Duration{
+	models.IntervalHour: 48 * time.Hour,
+	models.IntervalDay:  30 * 24 * time.Hour,
+	models.IntervalWeek: 26 * 7 * 24 * time.Hour,
+}
+
+// GetRoomAnalytics summarises the room's feedback: submissions and average
+// sentiment score per hour, day or week, the sentiment distribution and the
+// busiest hours of the week. The period defaults to one ending now.
+func (h *AnalyticsHandler) GetRoomAnalytics(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	query, err := parseAnalyticsQuery(c, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	analytics, err := h.DB.GetRoomAnalytics(room.ID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+// GetDashboard summarises the activity of every room owned by the caller:
+// feedback and unread counts, the latest submission and the sentiment trend of
+// each room, and the same figures for the whole account
+func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	dashboard, err := h.DB.GetDashboard(userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load dashboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// parseAnalyticsQuery reads the interval, from and to parameters
+func parseAnalyticsQuery(c *gin.Context, now time.Time) (models.AnalyticsQuery, error) {
+	query := models.AnalyticsQuery{Interval: c.DefaultQuery("interval", models.IntervalDay), To: now}
+	period, ok := defaultAnalyticsPeriods[query.Interval]
+	if !ok {
+		return query, fmt.Errorf("interval must be hour, day or week")
+	}
+
+	for param, target := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+			}
+			*target = t
+		}
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-period)
+	}
+	if !query.From.Before(query.To) {
+		return query, fmt.Errorf("from must be before to")
+	}
+
+	buckets := 0
+	for start := models.IntervalStart(query.From, query.Interval); start.Before(query.To); start = models.NextInterval(start, query.Interval) {
+		if buckets++; buckets > models.MaxAnalyticsBuckets {
+			return query, fmt.Errorf("the period spans more than %d intervals; use a longer interval", models.MaxAnalyticsBuckets)
+		}
+	}
+	return query, nil
+}
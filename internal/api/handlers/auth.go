@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"errors"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
@@ -15,12 +18,12 @@ import (
 
 // AuthHandler handles authentication-related routes
 type AuthHandler struct {
-	DB  *db.Database
+	DB  db.Store
 	Cfg *config.Config
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(db *db.Database, cfg *config.Config) *AuthHandler {
+func NewAuthHandler(db db.Store, cfg *config.Config) *AuthHandler {
 	return &AuthHandler{
 		DB:  db,
 		Cfg: cfg,
@@ -41,7 +44,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		// User exists
 		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
 		return
-	} else if !errors.Is(err, errors.New("user not found")) {
+	} else if !errors.Is(err, db.ErrUserNotFound) {
 		// Database error
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check user existence"})
 		return
@@ -54,18 +57,15 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := middleware.GenerateToken(user.ID, h.Cfg)
+	// Generate access and refresh tokens for a new session
+	resp, err := h.issueTokens(user, uuid.New().String())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	// Return user and token
-	c.JSON(http.StatusCreated, models.AuthResponse{
-		Token: token,
-		User:  *user,
-	})
+	// Return user and tokens
+	c.JSON(http.StatusCreated, resp)
 }
 
 // Login handles user login
@@ -90,21 +90,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := middleware.GenerateToken(user.ID, h.Cfg)
+	// Remove password hash from response
+	user.PasswordHash = ""
+
+	// Generate access and refresh tokens for a new session
+	resp, err := h.issueTokens(user, uuid.New().String())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	// Remove password hash from response
-	user.PasswordHash = ""
-
-	// Return user and token
-	c.JSON(http.StatusOK, models.AuthResponse{
-		Token: token,
-		User:  *user,
-	})
+	// Return user and tokens
+	c.JSON(http.StatusOK, resp)
 }
 
 // GetCurrentUser returns the currently authenticated user
@@ -123,3 +120,141 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user)
 }
+
+// Refresh exchanges a refresh token for a new access token and a rotated refresh token.
+// Presenting a token that was already rotated is treated as theft: the whole token
+// family is revoked and the client has to log in again.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stored, err := h.DB.GetRefreshTokenByHash(middleware.HashRefreshToken(req.RefreshToken))
+	if errors.Is(err, db.ErrRefreshTokenNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify refresh token"})
+		return
+	}
+
+	// Reuse of a rotated token: revoke the family
+	if stored.RevokedAt != nil {
+		h.revokeFamily(stored.FamilyID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected; please log in again"})
+		return
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token expired"})
+		return
+	}
+
+	// Revoke the presented token; losing this race means it was used concurrently
+	rotated, err := h.DB.RevokeRefreshToken(stored.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate refresh token"})
+		return
+	}
+	if !rotated {
+		h.revokeFamily(stored.FamilyID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected; please log in again"})
+		return
+	}
+
+	user, err := h.DB.GetUserByID(stored.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	user.PasswordHash = ""
+
+	resp, err := h.issueTokens(user, stored.FamilyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Logout revokes the current access token and, when given, the refresh token family
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	jti, expiresAt, err := middleware.GetTokenID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	if err := h.DB.RevokeAccessToken(jti, expiresAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+
+	if req.RefreshToken != "" {
+		stored, err := h.DB.GetRefreshTokenByHash(middleware.HashRefreshToken(req.RefreshToken))
+		if err == nil && stored.UserID == userID {
+			if err := h.DB.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
+				return
+			}
+		} else if err != nil && !errors.Is(err, db.ErrRefreshTokenNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
+			return
+		}
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// issueTokens creates an access token and a refresh token belonging to familyID
+func (h *AuthHandler) issueTokens(user *models.User, familyID string) (*models.AuthResponse, error) {
+	token, claims, err := middleware.GenerateToken(user.ID, h.Cfg)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := claims.ExpiresAt.Time
+	if err := h.DB.RecordAccessToken(familyID, claims.ID, expiresAt); err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshHash, err := middleware.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := h.DB.CreateRefreshToken(user.ID, refreshHash, familyID, time.Now().Add(h.Cfg.RefreshTokenTTL)); err != nil {
+		return nil, err
+	}
+
+	return &models.AuthResponse{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+		User:         *user,
+	}, nil
+}
+
+// revokeFamily revokes a refresh token family and the access tokens issued to
+// it after reuse was detected
+func (h *AuthHandler) revokeFamily(familyID string) {
+	if err := h.DB.RevokeRefreshTokenFamily(familyID); err != nil {
+		log.Printf("Failed to revoke refresh token family %s: %v\n", familyID, err)
+	}
+}
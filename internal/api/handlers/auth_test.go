@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestRefreshDetectsReuse(t *testing.T) {
+	store := db.NewMemory()
+	cfg := &config.Config{
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}
+	h := NewAuthHandler(store, cfg)
+	router := gin.New()
+	router.POST("/refresh", h.Refresh)
+
+	refresh := func(token string) (int, *models.AuthResponse) {
+		body, _ := json.Marshal(models.RefreshRequest{RefreshToken: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/refresh", bytes.NewReader(body)))
+		resp := &models.AuthResponse{}
+		json.Unmarshal(w.Body.Bytes(), resp)
+		return w.Code, resp
+	}
+
+	user, err := store.CreateUser("owner@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	first, err := h.issueTokens(user, uuid.NewString())
+	if err != nil {
+		t.Fatalf("issueTokens() error: %v", err)
+	}
+
+	code, second := refresh(first.RefreshToken)
+	if code != http.StatusOK || second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh = %d %+v, want a rotated token", code, second)
+	}
+
+	steps := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"unknown token", "not-a-token", http.StatusUnauthorized},
+		{"rotated token reused", first.RefreshToken, http.StatusUnauthorized},
+		{"current token after reuse", second.RefreshToken, http.StatusUnauthorized},
+	}
+	for _, step := range steps {
+		if code, _ := refresh(step.token); code != step.want {
+			t.Errorf("%s: refresh = %d, want %d", step.name, code, step.want)
+		}
+	}
+
+	// Access tokens issued to the family are revoked along with it
+	for name, token := range map[string]string{"first": first.Token, "second": second.Token} {
+		if _, err := middleware.ParseAccessToken(token, cfg, store); !errors.Is(err, middleware.ErrTokenRevoked) {
+			t.Errorf("%s access token after reuse: error = %v, want %v", name, err, middleware.ErrTokenRevoked)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/export"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// exportBatch is the number of entries loaded per query while exporting
+const exportBatch = 500
+
+// ExportFeedback downloads all of the room's feedback, oldest first, as CSV
+// (default), JSON Lines or XLSX, with the answers of survey rooms, the
+// columns, groups and votes of retro cards and the state and votes of Q&A
+// questions. Entries are loaded and written in
+// batches, so the response is streamed; an error after the first batch
+// truncates it.
+func (h *FeedbackHandler) ExportFeedback(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	format := c.DefaultQuery("format", export.FormatCSV)
+	contentType, ok := export.ContentType(format)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, jsonl or xlsx"})
+		return
+	}
+
+	layout, err := h.exportLayout(room)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room layout"})
+		return
+	}
+
+	// Load the first batch before committing to a successful response
+	batch, err := h.DB.GetFeedbackAfter(room.ID, 0, exportBatch)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback"})
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="feedback-%s.%s"`, room.ID, format))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(format, c.Writer, layout)
+	if err != nil {
+		log.Printf("Failed to export room %s: %v", room.ID, err)
+		return
+	}
+	for {
+		for i := range batch {
+			if err := w.Write(&batch[i]); err != nil {
+				log.Printf("Failed to export room %s: %v", room.ID, err)
+				return
+			}
+		}
+		if len(batch) < exportBatch || c.Request.Context().Err() != nil {
+			break
+		}
+
+		batch, err = h.DB.GetFeedbackAfter(room.ID, batch[len(batch)-1].ID, exportBatch)
+		if err != nil {
+			log.Printf("Failed to export room %s: %v", room.ID, err)
+			return
+		}
+	}
+	if err := w.Close(); err != nil {
+		log.Printf("Failed to export room %s: %v", room.ID, err)
+	}
+}
+
+// exportLayout loads the questions, or the columns and groups, of a room
+func (h *FeedbackHandler) exportLayout(room *models.Room) (export.Layout, error) {
+	layout := export.Layout{QA: room.Type == models.RoomTypeQA}
+	var err error
+	if room.Type == models.RoomTypeRetro {
+		if layout.Columns, err = h.DB.GetColumns(room.ID); err != nil {
+			return layout, err
+		}
+		layout.Groups, err = h.DB.GetCardGroups(room.ID)
+		return layout, err
+	}
+	layout.Questions, err = h.DB.GetQuestions(room.ID)
+	return layout, err
+}
@@ -1,43 +1,74 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
 	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/jobs"
 	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+	"github.com/panaalexandrucristian/feedback-collector/internal/sentiment"
 )
 
 // FeedbackHandler handles feedback-related routes
 type FeedbackHandler struct {
-	DB *db.Database
+	DB     db.Store
+	Jobs   *jobs.Queue
+	Events events.Publisher
+	Hub    *events.Hub
 }
 
 // NewFeedbackHandler creates a new feedback handler
-func NewFeedbackHandler(db *db.Database) *FeedbackHandler {
-	return &FeedbackHandler{DB: db}
+func NewFeedbackHandler(db db.Store, queue *jobs.Queue, publisher events.Publisher, hub *events.Hub) *FeedbackHandler {
+	return &FeedbackHandler{
+		DB:     db,
+		Jobs:   queue,
+		Events: publisher,
+		Hub:    hub,
+	}
 }
 
 // CreateFeedback handles creating a new feedback entry
 func (h *FeedbackHandler) CreateFeedback(c *gin.Context) {
-	roomID := c.Param("id")
-
 	var req models.CreateFeedbackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Verify room exists
-	room, err := h.DB.GetRoomByID(roomID)
+	room, err := middleware.GetRoom(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	// Only open rooms within their schedule accept submissions
+	now := time.Now()
+	if !room.AcceptsFeedback(now) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  closedRoomMessage(room, now),
+			"status": room.Status,
+		})
 		return
 	}
 
 	// Create feedback
-	feedback, err := h.DB.CreateFeedback(room.ID, req.Content)
+	participantID, _ := middleware.GetParticipantID(c)
+	feedback, err := h.submit(room, participantID, req)
+	var invalid *submissionError
+	if errors.As(err, &invalid) {
+		c.JSON(invalid.status, gin.H{"error": invalid.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feedback"})
 		return
@@ -46,23 +77,383 @@ func (h *FeedbackHandler) CreateFeedback(c *gin.Context) {
 	c.JSON(http.StatusCreated, feedback)
 }
 
-// GetFeedback retrieves all feedback for a room
+// submissionError rejects a submission that does not fit the room
+type submissionError struct {
+	status  int // HTTP status of the rejection
+	message string
+}
+
+func (e *submissionError) Error() string {
+	return e.message
+}
+
+// invalidSubmission rejects a submission as a bad request
+func invalidSubmission(message string) *submissionError {
+	return &submissionError{http.StatusBadRequest, message}
+}
+
+// submit validates and stores feedback for a room, queues its sentiment analysis
+// and publishes it to live subscribers. participantID identifies the submitter
+// and may be empty where the room does not require it. Callers check that the
+// room accepts feedback.
+func (h *FeedbackHandler) submit(room *models.Room, participantID string, req models.CreateFeedbackRequest) (*models.Feedback, error) {
+	feedback := &models.Feedback{RoomID: room.ID, Content: req.Content, ParticipantID: participantID}
+
+	var err error
+	switch {
+	case room.Type == models.RoomTypeRetro:
+		err = h.checkCard(room, req, feedback)
+	case req.ColumnID != 0:
+		err = invalidSubmission("Only retro rooms have columns")
+	case room.Type == models.RoomTypeQA:
+		err = checkQuestion(room, req, feedback)
+	default:
+		err = h.checkAnswers(room, req, feedback)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.DB.CreateFeedback(feedback); err != nil {
+		return nil, err
+	}
+
+	// Classify asynchronously; entries that cannot be queued are left for the backfill
+	if _, err := h.Jobs.Enqueue(sentiment.JobKind, sentiment.JobPayload{FeedbackID: feedback.ID}); err != nil {
+		log.Printf("Failed to enqueue sentiment analysis; feedback %d left pending: %v\n", feedback.ID, err)
+	}
+
+	h.Events.Publish(events.Event{Type: events.FeedbackCreated, RoomID: room.ID, Data: feedback})
+	return feedback, nil
+}
+
+// checkAnswers validates the content or answers of a submission. Survey rooms
+// take answers validated against their questions; without a comment, the text
+// answers become the content.
+func (h *FeedbackHandler) checkAnswers(room *models.Room, req models.CreateFeedbackRequest, feedback *models.Feedback) error {
+	questions, err := h.DB.GetQuestions(room.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(questions) == 0 {
+		if len(req.Answers) > 0 {
+			return invalidSubmission("This room has no questions to answer")
+		}
+		if strings.TrimSpace(feedback.Content) == "" {
+			return invalidSubmission("content is required")
+		}
+		return nil
+	}
+
+	answers, err := models.ValidateAnswers(questions, req.Answers)
+	if err != nil {
+		return invalidSubmission(err.Error())
+	}
+	if strings.TrimSpace(feedback.Content) == "" {
+		feedback.Content = models.SurveyContent(questions, answers)
+	}
+	if feedback.Content == "" && len(answers) == 0 {
+		return invalidSubmission("Answer at least one question")
+	}
+	feedback.Answers = answers
+	return nil
+}
+
+// checkCard validates a card for a retro board. Cards are added by participants
+// who joined the room, to one of its columns, while the board is in the write
+// phase.
+func (h *FeedbackHandler) checkCard(room *models.Room, req models.CreateFeedbackRequest, feedback *models.Feedback) error {
+	if room.Phase != models.RetroPhaseWrite {
+		return &submissionError{http.StatusConflict, "Cards can only be added in the write phase"}
+	}
+	if feedback.ParticipantID == "" {
+		return &submissionError{http.StatusUnauthorized, "Join the room to add cards"}
+	}
+	if len(req.Answers) > 0 {
+		return invalidSubmission("Retro rooms have no questions to answer")
+	}
+	if strings.TrimSpace(feedback.Content) == "" {
+		return invalidSubmission("content is required")
+	}
+
+	columns, err := h.DB.GetColumns(room.ID)
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.ID == req.ColumnID {
+			feedback.ColumnID = &column.ID
+			return nil
+		}
+	}
+	return invalidSubmission("column_id must be one of the room's columns")
+}
+
+// checkQuestion validates a question for a Q&A room. Questions of moderated
+// rooms wait for approval before they are shown on the public feed.
+func checkQuestion(room *models.Room, req models.CreateFeedbackRequest, feedback *models.Feedback) error {
+	if len(req.Answers) > 0 {
+		return invalidSubmission("Q&A rooms have no questions to answer")
+	}
+	if strings.TrimSpace(feedback.Content) == "" {
+		return invalidSubmission("content is required")
+	}
+
+	feedback.QAState = models.QAStateApproved
+	if room.Moderated {
+		feedback.QAState = models.QAStatePending
+	}
+	return nil
+}
+
+// defaultFeedbackPageSize is the page size when no limit is given
+const defaultFeedbackPageSize = 50
+
+// GetFeedback lists one page of the room's feedback; access is checked by the room
+// middleware. The total number of matching entries is returned in X-Total-Count
+// and the cursor of the next page, if any, in X-Next-Cursor. Loading the first
+// page marks the room's feedback as read on the dashboard.
 func (h *FeedbackHandler) GetFeedback(c *gin.Context) {
-	roomID := c.Param("id")
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	query, err := parseFeedbackQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.DB.ListFeedback(room.ID, query)
+	if errors.Is(err, db.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback"})
+		return
+	}
 
-	// Check if room exists
-	_, err := h.DB.GetRoomByID(roomID)
+	if query.Cursor == "" {
+		if userID, err := middleware.GetUserID(c); err == nil {
+			if err := h.DB.RecordRoomVisit(userID, room.ID); err != nil {
+				log.Printf("Failed to record visit to room %s: %v\n", room.ID, err)
+			}
+		}
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+	c.JSON(http.StatusOK, page.Items)
+}
+
+// parseFeedbackQuery reads the listing parameters: limit, cursor, sentiment,
+// status, qa_state, tag, from and to (RFC 3339), sort (created_at, score or
+// votes) and order (asc or desc, default desc)
+func parseFeedbackQuery(c *gin.Context) (models.FeedbackQuery, error) {
+	query := models.FeedbackQuery{
+		Sentiment: c.Query("sentiment"),
+		Status:    models.FeedbackStatus(c.Query("status")),
+		QAState:   models.QAState(c.Query("qa_state")),
+		Sort:      c.DefaultQuery("sort", models.FeedbackSortCreatedAt),
+		Limit:     defaultFeedbackPageSize,
+		Cursor:    c.Query("cursor"),
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > models.MaxFeedbackPageSize {
+			return query, fmt.Errorf("limit must be between 1 and %d", models.MaxFeedbackPageSize)
+		}
+		query.Limit = limit
+	}
+
+	switch query.Sentiment {
+	case "", sentiment.Pending, sentiment.Positive, sentiment.Neutral, sentiment.Negative:
+	default:
+		return query, errors.New("sentiment must be pending, positive, neutral or negative")
+	}
+
+	if query.Status != "" && !query.Status.Valid() {
+		return query, errors.New("status must be new, reviewed or archived")
+	}
+
+	if query.QAState != "" && !query.QAState.Valid() {
+		return query, errors.New("qa_state must be pending, approved, answered or dismissed")
+	}
+
+	if value := c.Query("tag"); value != "" {
+		tag, err := models.NormalizeTag(value)
+		if err != nil {
+			return query, err
+		}
+		query.Tag = tag
+	}
+
+	for param, target := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+			}
+			*target = &t
+		}
+	}
+
+	switch query.Sort {
+	case models.FeedbackSortCreatedAt, models.FeedbackSortScore, models.FeedbackSortVotes:
+	default:
+		return query, errors.New("sort must be created_at, score or votes")
+	}
+
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		query.Ascending = true
+	case "desc":
+	default:
+		return query, errors.New("order must be asc or desc")
+	}
+
+	return query, nil
+}
+
+// UpdateFeedback changes the status and/or tags of a feedback entry of the room,
+// and the Q&A state and pin of questions in Q&A rooms
+func (h *FeedbackHandler) UpdateFeedback(c *gin.Context) {
+	var req models.UpdateFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, err := middleware.GetRoom(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
 		return
 	}
 
-	// Get all feedback for the room
-	feedback, err := h.DB.GetFeedbackByRoomID(roomID)
+	feedbackID, err := strconv.Atoi(c.Param("feedbackId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feedback ID"})
+		return
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		if tags, err = models.NormalizeTags(*req.Tags); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if (req.QAState != nil || req.Pinned != nil) && room.Type != models.RoomTypeQA {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "qa_state and pinned apply to Q&A rooms only"})
+		return
+	}
+
+	// Entries of other rooms are reported as missing
+	feedback, err := h.DB.GetFeedbackByID(feedbackID)
+	if errors.Is(err, db.ErrFeedbackNotFound) || (err == nil && feedback.RoomID != room.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feedback not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback"})
 		return
 	}
 
+	if req.Status != nil {
+		if err := h.DB.UpdateFeedbackStatus(feedback.ID, *req.Status); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update feedback"})
+			return
+		}
+		feedback.Status = *req.Status
+	}
+	if req.Tags != nil {
+		if err := h.DB.SetFeedbackTags(feedback.ID, tags); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update feedback"})
+			return
+		}
+		feedback.Tags = tags
+	}
+	if req.QAState != nil || req.Pinned != nil {
+		if req.QAState != nil {
+			feedback.QAState = *req.QAState
+		}
+		if req.Pinned != nil {
+			feedback.Pinned = *req.Pinned
+		}
+		if err := h.DB.UpdateQuestion(feedback.ID, feedback.QAState, feedback.Pinned); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update feedback"})
+			return
+		}
+	}
+
+	h.Events.Publish(events.Event{Type: events.FeedbackUpdated, RoomID: room.ID, Data: feedback})
+
 	c.JSON(http.StatusOK, feedback)
 }
+
+// defaultSearchResults is the page size of searches when no limit is given
+const defaultSearchResults = 20
+
+// SearchFeedback searches the content of feedback in every room owned by the
+// caller. Results are ranked best match first and paged with limit and offset;
+// the total number of matches is returned in X-Total-Count.
+func (h *FeedbackHandler) SearchFeedback(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter q is required"})
+		return
+	}
+	if len(query) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query is too long"})
+		return
+	}
+
+	limit, offset := defaultSearchResults, 0
+	if value := c.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > models.MaxSearchResults {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", models.MaxSearchResults)})
+			return
+		}
+	}
+	if value := c.Query("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+	}
+
+	page, err := h.DB.SearchFeedback(userID, query, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search feedback"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	c.JSON(http.StatusOK, page.Items)
+}
+
+// closedRoomMessage explains why a room does not accept feedback at now
+func closedRoomMessage(room *models.Room, now time.Time) string {
+	switch {
+	case (room.Status == models.RoomStatusDraft || room.Status == models.RoomStatusOpen) &&
+		room.OpensAt != nil && now.Before(*room.OpensAt):
+		return fmt.Sprintf("Room opens for feedback at %s", room.OpensAt.UTC().Format(time.RFC3339))
+	case room.ClosesAt != nil && !now.Before(*room.ClosesAt):
+		return fmt.Sprintf("Room closed for feedback at %s", room.ClosesAt.UTC().Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("Room is %s and does not accept feedback", room.Status)
+	}
+}
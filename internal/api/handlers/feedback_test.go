@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestSearchFeedback(t *testing.T) {
+	store := db.NewMemory()
+	cfg := &config.Config{JWTSecret: "test-secret", AccessTokenTTL: time.Minute}
+	owner, err := store.CreateUser("owner@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	room := &models.Room{ID: "SEARCH", Name: "Search", CreatorID: owner.ID}
+	if err := store.CreateRoom(room); err != nil {
+		t.Fatalf("CreateRoom() error: %v", err)
+	}
+	for _, content := range []string{"login fails", "login is slow", "login login login", "checkout"} {
+		if err := store.CreateFeedback(&models.Feedback{RoomID: room.ID, Content: content}); err != nil {
+			t.Fatalf("CreateFeedback() error: %v", err)
+		}
+	}
+	token, _, err := middleware.GenerateToken(owner.ID, cfg)
+	if err != nil {
+		t.Fatalf("GenerateToken() error: %v", err)
+	}
+
+	h := NewFeedbackHandler(store, nil, events.NewBus(), nil)
+	router := gin.New()
+	router.GET("/feedback/search", middleware.AuthMiddleware(cfg, store), h.SearchFeedback)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantTotal string
+		wantItems int
+	}{
+		{"all matches", "q=login", http.StatusOK, "3", 3},
+		{"first page", "q=login&limit=2", http.StatusOK, "3", 2},
+		{"last page", "q=login&limit=2&offset=2", http.StatusOK, "3", 1},
+		{"no matches", "q=refund", http.StatusOK, "0", 0},
+		{"missing query", "q=+", http.StatusBadRequest, "", 0},
+		{"limit too large", "q=login&limit=" + strconv.Itoa(models.MaxSearchResults+1), http.StatusBadRequest, "", 0},
+		{"negative offset", "q=login&offset=-1", http.StatusBadRequest, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/feedback/search?"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("GET = %d %s, want %d", w.Code, w.Body, tt.wantCode)
+			}
+			if got := w.Header().Get("X-Total-Count"); got != tt.wantTotal {
+				t.Errorf("X-Total-Count = %q, want %q", got, tt.wantTotal)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var items []models.FeedbackSearchResult
+			if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+				t.Fatalf("failed to decode results: %v", err)
+			}
+			if len(items) != tt.wantItems {
+				t.Errorf("got %d results, want %d", len(items), tt.wantItems)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/importer"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+	"github.com/panaalexandrucristian/feedback-collector/internal/sentiment"
+)
+
+// maxImportBytes caps the size of an uploaded import file
+const maxImportBytes = 10 << 20
+
+// ImportFeedback imports CSV or JSON feedback into the room, either from the
+// request body or from a multipart "file" field. The format is taken from the
+// format parameter, the file extension or the content type. Rows are validated
+// first and imported in a single transaction only if all of them are valid;
+// with dry_run=true nothing is stored. Imported entries keep their timestamps
+// and are not published to live streams. Only standard rooms can be imported into.
+func (h *FeedbackHandler) ImportFeedback(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+	if room.Type != models.RoomTypeStandard {
+		c.JSON(http.StatusConflict, gin.H{"error": "Feedback can only be imported into standard rooms"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	body, name, contentType := io.Reader(c.Request.Body), "", c.ContentType()
+	if contentType == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Multipart uploads need a file field"})
+			return
+		}
+		upload, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+			return
+		}
+		defer upload.Close()
+		body, name, contentType = upload, file.Filename, file.Header.Get("Content-Type")
+	}
+
+	format := importFormat(c.Query("format"), name, contentType)
+	if format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or json"})
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	result, imported, err := importer.Import(h.DB, room.ID, format, body, dryRun, time.Now())
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+		return
+	case errors.Is(err, importer.ErrTooManyRows):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, importer.ErrInvalidFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import feedback"})
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	if dryRun {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	// Classify entries that were not analysed before; failures are left for the backfill
+	for _, f := range imported {
+		if f.Sentiment != sentiment.Pending {
+			continue
+		}
+		if _, err := h.Jobs.Enqueue(sentiment.JobKind, sentiment.JobPayload{FeedbackID: f.ID}); err != nil {
+			log.Printf("Failed to enqueue sentiment analysis; feedback %d left pending: %v\n", f.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// importFormat picks the import format from an explicit parameter, a file name
+// or a content type, returning "" if none names a known format
+func importFormat(param, name, contentType string) string {
+	if param != "" {
+		switch param {
+		case importer.FormatCSV, importer.FormatJSON:
+			return param
+		}
+		return ""
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return importer.FormatCSV
+	case ".json", ".jsonl", ".ndjson":
+		return importer.FormatJSON
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return importer.FormatCSV
+	case "application/json", "application/x-ndjson":
+		return importer.FormatJSON
+	}
+	return ""
+}
@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/live"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+const (
+	// liveAuthTimeout is how long a new connection may take to send its auth message
+	liveAuthTimeout = 10 * time.Second
+	// liveWriteTimeout bounds every write so that a stalled client cannot hold a writer
+	liveWriteTimeout = 10 * time.Second
+	// livePongTimeout closes connections that stop answering pings
+	livePongTimeout = 60 * time.Second
+	// livePingInterval must be shorter than livePongTimeout
+	livePingInterval = 25 * time.Second
+	// liveMaxMessageSize limits incoming messages, feedback content included
+	liveMaxMessageSize = 16 << 10
+	// liveBuffer is how many events a client may fall behind before it is
+	// disconnected; it has to reconnect and reload the room
+	liveBuffer = 64
+	// liveReplyBuffer bounds direct replies (errors, pongs) waiting to be written
+	liveReplyBuffer = 16
+)
+
+// Client message types of the live protocol. Every message, in both directions,
+// is a JSON object with a "type" and an optional "data" field; server messages
+// have the shape of events.Event and also carry the room's events.
+const (
+	liveAuth         = "auth"            // {"token": "<access or room token>"}, first message only
+	livePing         = "ping"            // answered with "pong"
+	liveSubmit       = "feedback.submit" // {"content": "...", "answers": [...], "column_id": 1}
+	liveLock         = "room.lock"       // facilitator: close the room for feedback
+	liveUnlock       = "room.unlock"     // facilitator: reopen the room
+	liveReveal       = "reveal"          // facilitator: broadcast session.reveal with data
+	liveNextQuestion = "next_question"   // facilitator: broadcast session.next_question with data
+)
+
+// Server-only message types
+const (
+	liveWelcome = "welcome" // {"member": ..., "room": ..., "presence": ...}
+	livePong    = "pong"
+	liveError   = "error" // {"request": "<client message type>", "message": "..."}
+)
+
+// liveMessage is a message sent by a client
+type liveMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// LiveHandler serves the bidirectional WebSocket channel of a room
+type LiveHandler struct {
+	Feedback *FeedbackHandler
+	Cfg      *config.Config
+	Presence *live.Presence
+	upgrader websocket.Upgrader
+}
+
+// NewLiveHandler creates a live channel handler. Browser connections are only
+// accepted from the configured CORS origins.
+func NewLiveHandler(feedback *FeedbackHandler, cfg *config.Config, presence *live.Presence) *LiveHandler {
+	h := &LiveHandler{
+		Feedback: feedback,
+		Cfg:      cfg,
+		Presence: presence,
+	}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     h.checkOrigin,
+	}
+	return h
+}
+
+// checkOrigin accepts clients without an Origin header and browsers on an allowed origin
+func (h *LiveHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range h.Cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeRoom upgrades the request to a WebSocket connected to the room loaded by
+// LoadRoom. The client must authenticate with its first message, using either
+// the room owner's access token (facilitator) or a participant token from
+// JoinRoom. Other users' access tokens are accepted for rooms without a password;
+// they take part with the same participant ID as after joining.
+func (h *LiveHandler) ServeRoom(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	// Upgrade replies with an HTTP error itself when the handshake is invalid
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+
+	conn := &liveConn{
+		handler: h,
+		ws:      ws,
+		roomID:  room.ID,
+		replies: make(chan events.Event, liveReplyBuffer),
+		done:    make(chan struct{}),
+	}
+	conn.serve(room)
+}
+
+// liveConn is one client connection. Reads happen on the serving goroutine and
+// all writes on the writer goroutine.
+type liveConn struct {
+	handler *LiveHandler
+	ws      *websocket.Conn
+	roomID  string
+	member  live.Member
+	sub     *events.Subscription
+	replies chan events.Event
+	done    chan struct{}
+	once    sync.Once
+
+	// hideCards is set while a retro board hides the cards of others from this
+	// member; it is only used by the writer
+	hideCards bool
+}
+
+// serve authenticates the client and runs the connection until either side closes it
+func (conn *liveConn) serve(room *models.Room) {
+	defer conn.ws.Close()
+
+	conn.ws.SetReadLimit(liveMaxMessageSize)
+	member, err := conn.authenticate(room)
+	if err != nil {
+		conn.closeWith(websocket.ClosePolicyViolation, err.Error())
+		return
+	}
+	conn.member = member
+	conn.hideCards = member.Role == live.RoleParticipant && room.Type == models.RoomTypeRetro && room.Phase.CardsHidden()
+
+	h := conn.handler
+	conn.sub = h.Feedback.Hub.Subscribe(conn.roomID, liveBuffer)
+	defer conn.sub.Close()
+
+	counts := h.Presence.Join(conn.roomID, member)
+	defer func() {
+		h.publishPresence(conn.roomID, h.Presence.Leave(conn.roomID, member))
+	}()
+
+	// The welcome is written before anything else the subscription delivers
+	welcome := events.Event{
+		Type:   liveWelcome,
+		RoomID: conn.roomID,
+		Data:   gin.H{"member": member, "room": room.Public(), "presence": counts},
+		Time:   time.Now().UTC(),
+	}
+	conn.ws.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+	if err := conn.ws.WriteJSON(welcome); err != nil {
+		return
+	}
+	h.publishPresence(conn.roomID, counts)
+
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		conn.write()
+	}()
+
+	conn.read()
+	conn.stop()
+	writer.Wait()
+}
+
+// authenticate reads the auth message and resolves the client's identity
+func (conn *liveConn) authenticate(room *models.Room) (live.Member, error) {
+	conn.ws.SetReadDeadline(time.Now().Add(liveAuthTimeout))
+
+	var msg liveMessage
+	if err := conn.ws.ReadJSON(&msg); err != nil {
+		return live.Member{}, errors.New("expected an auth message")
+	}
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if msg.Type != liveAuth || json.Unmarshal(msg.Data, &auth) != nil || auth.Token == "" {
+		return live.Member{}, errors.New("expected an auth message")
+	}
+
+	h := conn.handler
+	if claims, err := middleware.ParseRoomToken(auth.Token, room, h.Cfg); err == nil {
+		// Signed-in users who joined keep their user participant ID
+		id := claims.Subject
+		if !strings.HasPrefix(id, "u:") {
+			id = "p:" + id
+		}
+		return live.Member{ID: id, Role: live.RoleParticipant}, nil
+	}
+
+	claims, err := middleware.ParseAccessToken(auth.Token, h.Cfg, h.Feedback.DB)
+	if err != nil {
+		return live.Member{}, errors.New("invalid or expired token")
+	}
+	member := live.Member{ID: middleware.UserParticipantID(claims.UserID), Role: live.RoleParticipant}
+	if claims.UserID == room.CreatorID {
+		member.Role = live.RoleFacilitator
+	} else if room.IsPasswordProtected {
+		return live.Member{}, errors.New("this room is password protected; join it first")
+	}
+	return member, nil
+}
+
+// read handles client messages until the connection fails or is closed
+func (conn *liveConn) read() {
+	conn.ws.SetReadDeadline(time.Now().Add(livePongTimeout))
+	conn.ws.SetPongHandler(func(string) error {
+		return conn.ws.SetReadDeadline(time.Now().Add(livePongTimeout))
+	})
+
+	for {
+		_, data, err := conn.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg liveMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			conn.replyError("", "messages must be JSON objects with a type")
+			continue
+		}
+		if err := conn.handle(msg); err != nil {
+			conn.replyError(msg.Type, err.Error())
+		}
+
+		select {
+		case <-conn.done:
+			return
+		default:
+		}
+	}
+}
+
+// handle executes one client message
+func (conn *liveConn) handle(msg liveMessage) error {
+	h := conn.handler
+	switch msg.Type {
+	case livePing:
+		conn.reply(livePong, nil)
+		return nil
+	case liveSubmit:
+		return h.submit(conn.roomID, conn.participantID(), msg.Data)
+	case liveLock, liveUnlock, liveReveal, liveNextQuestion:
+		if conn.member.Role != live.RoleFacilitator {
+			return errors.New("only the facilitator can do this")
+		}
+	default:
+		return fmt.Errorf("unknown message type %q", msg.Type)
+	}
+
+	switch msg.Type {
+	case liveLock:
+		return h.setOpen(conn.roomID, false)
+	case liveUnlock:
+		return h.setOpen(conn.roomID, true)
+	case liveReveal:
+		return h.command(conn.roomID, events.SessionReveal, msg.Data)
+	default:
+		return h.command(conn.roomID, events.SessionNextQuestion, msg.Data)
+	}
+}
+
+// write sends room events, replies and pings until the connection stops
+func (conn *liveConn) write() {
+	ping := time.NewTicker(livePingInterval)
+	defer ping.Stop()
+
+	for {
+		var event events.Event
+		select {
+		case <-conn.done:
+			return
+		case <-ping.C:
+			conn.ws.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+			if err := conn.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				conn.stop()
+				return
+			}
+			continue
+		case event = <-conn.replies:
+		case e, ok := <-conn.sub.Events():
+			// Closed on shutdown or when this client fell behind
+			if !ok {
+				conn.closeWith(websocket.CloseTryAgainLater, "connection fell behind; reconnect")
+				conn.stop()
+				return
+			}
+			event = conn.redact(e)
+		}
+
+		conn.ws.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+		if err := conn.ws.WriteJSON(event); err != nil {
+			conn.stop()
+			return
+		}
+	}
+}
+
+// participantID identifies the member as the author of cards and votes. It is
+// the same participant ID as on the HTTP endpoints: the subject of the room
+// token, or the user participant ID that JoinRoom gives signed-in users.
+func (conn *liveConn) participantID() string {
+	return strings.TrimPrefix(conn.member.ID, "p:")
+}
+
+// redact withholds the content of feedback events from participants while a
+// retro board hides cards, and for questions of Q&A rooms that are not on the
+// public feed; they only learn the card's column or the question's state.
+// Phase changes of retro boards are tracked from the events themselves.
+func (conn *liveConn) redact(event events.Event) events.Event {
+	if event.Type == events.RetroUpdated {
+		var update struct {
+			Phase models.RetroPhase `json:"phase"`
+		}
+		decodeEventData(event, &update)
+		conn.hideCards = conn.member.Role == live.RoleParticipant && update.Phase.CardsHidden()
+	}
+	if conn.member.Role != live.RoleParticipant || !strings.HasPrefix(event.Type, "feedback.") {
+		return event
+	}
+
+	var entry struct {
+		ID       int            `json:"id"`
+		ColumnID *int           `json:"column_id"`
+		QAState  models.QAState `json:"qa_state"`
+	}
+	decodeEventData(event, &entry)
+	switch {
+	case conn.hideCards:
+		event.Data = gin.H{"id": entry.ID, "column_id": entry.ColumnID}
+	case entry.QAState != "" && !entry.QAState.Public():
+		event.Data = gin.H{"id": entry.ID, "qa_state": entry.QAState}
+	}
+	return event
+}
+
+// decodeEventData decodes the data of an event, whether published as a value or
+// as raw JSON, into v
+func decodeEventData(event events.Event, v interface{}) {
+	if data, err := json.Marshal(event.Data); err == nil {
+		json.Unmarshal(data, v)
+	}
+}
+
+// reply queues a message for this client only. A client that does not read its
+// replies is disconnected.
+func (conn *liveConn) reply(eventType string, data interface{}) {
+	select {
+	case conn.replies <- events.Event{Type: eventType, RoomID: conn.roomID, Data: data, Time: time.Now().UTC()}:
+	default:
+		conn.stop()
+	}
+}
+
+// replyError tells the client why one of its messages was rejected
+func (conn *liveConn) replyError(requestType, message string) {
+	conn.reply(liveError, gin.H{"request": requestType, "message": message})
+}
+
+// stop ends the connection; closing the socket unblocks the reader
+func (conn *liveConn) stop() {
+	conn.once.Do(func() {
+		close(conn.done)
+		conn.ws.Close()
+	})
+}
+
+// closeWith sends a close frame with the given code and reason
+func (conn *liveConn) closeWith(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	conn.ws.WriteControl(websocket.CloseMessage, message, time.Now().Add(liveWriteTimeout))
+}
+
+// submit stores feedback sent over the channel, with the same rules as the HTTP endpoint
+func (h *LiveHandler) submit(roomID, participantID string, data json.RawMessage) error {
+	var req models.CreateFeedbackRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errors.New("content or answers are required")
+	}
+
+	// Reload the room; it may have been locked since the client connected
+	room, err := h.Feedback.DB.GetRoomByID(roomID)
+	if err != nil {
+		return errors.New("failed to retrieve room")
+	}
+	now := time.Now()
+	if !room.AcceptsFeedback(now) {
+		return errors.New(closedRoomMessage(room, now))
+	}
+
+	_, err = h.Feedback.submit(room, participantID, req)
+	var invalid *submissionError
+	if errors.As(err, &invalid) {
+		return invalid
+	}
+	if err != nil {
+		log.Printf("Failed to save live feedback for room %s: %v\n", roomID, err)
+		return errors.New("failed to save feedback")
+	}
+	return nil
+}
+
+// setOpen opens or closes the room for feedback and announces the change
+func (h *LiveHandler) setOpen(roomID string, open bool) error {
+	from, to, eventType := models.RoomStatusOpen, models.RoomStatusClosed, events.RoomClosed
+	if open {
+		from, to, eventType = models.RoomStatusClosed, models.RoomStatusOpen, events.RoomOpened
+	}
+
+	store := h.Feedback.DB
+	room, err := store.GetRoomByID(roomID)
+	if err != nil {
+		return errors.New("failed to retrieve room")
+	}
+	// The scheduler would close the room again straight away
+	if open && room.ClosesAt != nil && !time.Now().Before(*room.ClosesAt) {
+		return errors.New("room closes_at has passed; set a later closes_at to reopen it")
+	}
+
+	moved, err := store.TransitionRoomStatus(roomID, from, to)
+	if err != nil {
+		return errors.New("failed to update room")
+	}
+	if !moved {
+		return fmt.Errorf("room is %s, not %s", room.Status, from)
+	}
+
+	room.Status = to
+	h.Feedback.Events.Publish(events.Event{Type: eventType, RoomID: roomID, Data: room.Public()})
+	return nil
+}
+
+// command broadcasts a facilitator command to everyone in the room. The data,
+// if any, must be a JSON object and is passed through unchanged.
+func (h *LiveHandler) command(roomID, eventType string, data json.RawMessage) error {
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return errors.New("data must be a JSON object")
+	}
+
+	h.Feedback.Events.Publish(events.Event{Type: eventType, RoomID: roomID, Data: data})
+	return nil
+}
+
+// publishPresence announces a room's connection counts to its local connections.
+// Counts cover this instance only.
+func (h *LiveHandler) publishPresence(roomID string, counts live.Counts) {
+	h.Feedback.Hub.Publish(events.Event{
+		Type:   events.Presence,
+		RoomID: roomID,
+		Data:   counts,
+		Time:   time.Now().UTC(),
+	})
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/jobs"
+	"github.com/panaalexandrucristian/feedback-collector/internal/live"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// liveTest is a live channel served over HTTP for one open room
+type liveTest struct {
+	url   string
+	store db.Store
+	cfg   *config.Config
+	hub   *events.Hub
+	room  *models.Room
+	owner *models.User
+}
+
+func newLiveTest(t *testing.T, password string) *liveTest {
+	t.Helper()
+
+	store := db.NewMemory()
+	cfg := &config.Config{JWTSecret: "test-secret", AccessTokenTTL: time.Minute}
+	owner, err := store.CreateUser("owner@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	room := &models.Room{ID: "LIVEROOM", Name: "Live", CreatorID: owner.ID, Password: password}
+	if err := store.CreateRoom(room); err != nil {
+		t.Fatalf("CreateRoom() error: %v", err)
+	}
+	if room, err = store.GetRoomByID(room.ID); err != nil {
+		t.Fatalf("GetRoomByID() error: %v", err)
+	}
+
+	bus := events.NewBus()
+	hub := events.NewHub()
+	bus.Subscribe(hub.Publish)
+	feedback := NewFeedbackHandler(store, jobs.New(store, 1, time.Second), bus, hub)
+	h := NewLiveHandler(feedback, cfg, live.NewPresence())
+
+	router := gin.New()
+	router.GET("/rooms/:id/ws", middleware.LoadRoom(store), h.ServeRoom)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &liveTest{
+		url:   "ws" + strings.TrimPrefix(server.URL, "http") + "/rooms/" + room.ID + "/ws",
+		store: store,
+		cfg:   cfg,
+		hub:   hub,
+		room:  room,
+		owner: owner,
+	}
+}
+
+// accessToken signs an access token for userID
+func (lt *liveTest) accessToken(t *testing.T, userID int) string {
+	t.Helper()
+	token, _, err := middleware.GenerateToken(userID, lt.cfg)
+	if err != nil {
+		t.Fatalf("GenerateToken() error: %v", err)
+	}
+	return token
+}
+
+// roomToken signs a participant token for the room
+func (lt *liveTest) roomToken(t *testing.T, participantID string) string {
+	t.Helper()
+	token, _, err := middleware.GenerateRoomToken(lt.room, participantID, lt.cfg)
+	if err != nil {
+		t.Fatalf("GenerateRoomToken() error: %v", err)
+	}
+	return token
+}
+
+// dial connects and sends msg as the first message
+func (lt *liveTest) dial(t *testing.T, msg liveMessage) *websocket.Conn {
+	t.Helper()
+
+	ws, _, err := websocket.DefaultDialer.Dial(lt.url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+
+	if err := ws.WriteJSON(msg); err != nil {
+		t.Fatalf("failed to send %s: %v", msg.Type, err)
+	}
+	return ws
+}
+
+// connect authenticates with token and returns the connection and its member
+func (lt *liveTest) connect(t *testing.T, token string) (*websocket.Conn, live.Member) {
+	t.Helper()
+
+	ws := lt.dial(t, authMessage(token))
+	return ws, expectWelcome(t, ws)
+}
+
+// expectWelcome reads the welcome message and returns the member it announces
+func expectWelcome(t *testing.T, ws *websocket.Conn) live.Member {
+	t.Helper()
+
+	var welcome struct {
+		Member live.Member `json:"member"`
+	}
+	if err := json.Unmarshal(expectEvent(t, ws, liveWelcome), &welcome); err != nil {
+		t.Fatalf("failed to decode welcome: %v", err)
+	}
+	return welcome.Member
+}
+
+func authMessage(token string) liveMessage {
+	data, _ := json.Marshal(map[string]string{"token": token})
+	return liveMessage{Type: liveAuth, Data: data}
+}
+
+// liveEvent is a server message as seen by a client
+type liveEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// expectEvent reads messages until one of eventType arrives and returns its data
+func expectEvent(t *testing.T, ws *websocket.Conn, eventType string) json.RawMessage {
+	t.Helper()
+
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var event liveEvent
+		if err := ws.ReadJSON(&event); err != nil {
+			t.Fatalf("waiting for %s: %v", eventType, err)
+		}
+		if event.Type == eventType {
+			return event.Data
+		}
+	}
+}
+
+// expectClose reads until the server closes the connection and returns the close code
+func expectClose(t *testing.T, ws *websocket.Conn) int {
+	t.Helper()
+
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, _, err := ws.ReadMessage(); err != nil {
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) {
+				t.Fatalf("connection ended without a close frame: %v", err)
+			}
+			return closeErr.Code
+		}
+	}
+}
+
+func TestLiveAuthentication(t *testing.T) {
+	lt := newLiveTest(t, "")
+	guest, err := lt.store.CreateUser("guest@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	guestID := middleware.UserParticipantID(guest.ID)
+
+	tests := []struct {
+		name string
+		msg  liveMessage
+		want live.Member
+	}{
+		{"room token", authMessage(lt.roomToken(t, "anon")), live.Member{ID: "p:anon", Role: live.RoleParticipant}},
+		{"owner", authMessage(lt.accessToken(t, lt.owner.ID)), live.Member{ID: middleware.UserParticipantID(lt.owner.ID), Role: live.RoleFacilitator}},
+		// Signed-in users are the same participant with either token
+		{"other user", authMessage(lt.accessToken(t, guest.ID)), live.Member{ID: guestID, Role: live.RoleParticipant}},
+		{"room token of user", authMessage(lt.roomToken(t, guestID)), live.Member{ID: guestID, Role: live.RoleParticipant}},
+		{"invalid token", authMessage("not-a-token"), live.Member{}},
+		{"no auth message", liveMessage{Type: livePing}, live.Member{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := lt.dial(t, tt.msg)
+			if tt.want.ID == "" {
+				if code := expectClose(t, ws); code != websocket.ClosePolicyViolation {
+					t.Errorf("close code = %d, want %d", code, websocket.ClosePolicyViolation)
+				}
+				return
+			}
+
+			if member := expectWelcome(t, ws); member != tt.want {
+				t.Errorf("member = %+v, want %+v", member, tt.want)
+			}
+			ws.Close()
+		})
+	}
+}
+
+func TestLivePasswordProtectedRoom(t *testing.T) {
+	lt := newLiveTest(t, "$2a$10$hash")
+	guest, err := lt.store.CreateUser("guest@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+
+	ws := lt.dial(t, authMessage(lt.accessToken(t, guest.ID)))
+	if code := expectClose(t, ws); code != websocket.ClosePolicyViolation {
+		t.Errorf("close code for a user who did not join = %d, want %d", code, websocket.ClosePolicyViolation)
+	}
+	if _, member := lt.connect(t, lt.roomToken(t, "anon")); member.Role != live.RoleParticipant {
+		t.Errorf("member with a room token = %+v, want a participant", member)
+	}
+}
+
+func TestLiveFacilitatorCommands(t *testing.T) {
+	lt := newLiveTest(t, "")
+	facilitator, _ := lt.connect(t, lt.accessToken(t, lt.owner.ID))
+	participant, _ := lt.connect(t, lt.roomToken(t, "anon"))
+
+	if err := participant.WriteJSON(liveMessage{Type: liveLock}); err != nil {
+		t.Fatalf("failed to send %s: %v", liveLock, err)
+	}
+	var rejected struct {
+		Request string `json:"request"`
+	}
+	json.Unmarshal(expectEvent(t, participant, liveError), &rejected)
+	if rejected.Request != liveLock {
+		t.Errorf("error for request %q, want %q", rejected.Request, liveLock)
+	}
+	if room, _ := lt.store.GetRoomByID(lt.room.ID); room.Status != models.RoomStatusOpen {
+		t.Fatalf("room status = %s after a participant lock, want open", room.Status)
+	}
+
+	if err := facilitator.WriteJSON(liveMessage{Type: liveLock}); err != nil {
+		t.Fatalf("failed to send %s: %v", liveLock, err)
+	}
+	for name, ws := range map[string]*websocket.Conn{"facilitator": facilitator, "participant": participant} {
+		var room models.Room
+		json.Unmarshal(expectEvent(t, ws, events.RoomClosed), &room)
+		if room.Status != models.RoomStatusClosed || room.Password != "" {
+			t.Errorf("%s: %s = %+v, want a closed room without its password", name, events.RoomClosed, room)
+		}
+	}
+
+	// Participants still talk to the closed room, but cannot submit
+	if err := participant.WriteJSON(liveMessage{Type: liveSubmit, Data: json.RawMessage(`{"content":"late"}`)}); err != nil {
+		t.Fatalf("failed to send %s: %v", liveSubmit, err)
+	}
+	json.Unmarshal(expectEvent(t, participant, liveError), &rejected)
+	if rejected.Request != liveSubmit {
+		t.Errorf("error for request %q, want %q", rejected.Request, liveSubmit)
+	}
+}
+
+func TestLivePresence(t *testing.T) {
+	lt := newLiveTest(t, "")
+	facilitator, _ := lt.connect(t, lt.accessToken(t, lt.owner.ID))
+	expectPresence := func(want live.Counts) {
+		t.Helper()
+		var counts live.Counts
+		json.Unmarshal(expectEvent(t, facilitator, events.Presence), &counts)
+		if counts != want {
+			t.Errorf("presence = %+v, want %+v", counts, want)
+		}
+	}
+	expectPresence(live.Counts{Facilitators: 1})
+
+	token := lt.roomToken(t, "anon")
+	first, _ := lt.connect(t, token)
+	expectPresence(live.Counts{Participants: 1, Facilitators: 1})
+	// A second tab of the same participant is not counted twice
+	second, _ := lt.connect(t, token)
+	expectPresence(live.Counts{Participants: 1, Facilitators: 1})
+
+	first.Close()
+	expectPresence(live.Counts{Participants: 1, Facilitators: 1})
+	second.Close()
+	expectPresence(live.Counts{Facilitators: 1})
+}
+
+func TestLiveDisconnectsSlowClient(t *testing.T) {
+	lt := newLiveTest(t, "")
+	ws, _ := lt.connect(t, lt.accessToken(t, lt.owner.ID))
+
+	// Without reading, the socket buffers fill up and then the subscription
+	// overflows; the client is dropped and told to reconnect
+	data := strings.Repeat("x", 64<<10)
+	for i := 0; i < 1000; i++ {
+		lt.hub.Publish(events.Event{Type: events.FeedbackCreated, RoomID: lt.room.ID, Data: data})
+	}
+
+	if code := expectClose(t, ws); code != websocket.CloseTryAgainLater {
+		t.Errorf("close code = %d, want %d", code, websocket.CloseTryAgainLater)
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// GetQAFeed returns the approved and answered questions of a Q&A room, ranked
+// for the audience, with the questions the participant has upvoted
+func (h *FeedbackHandler) GetQAFeed(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	entries, err := h.DB.GetFeedbackByRoomID(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve questions"})
+		return
+	}
+
+	feed := models.QAFeed{Questions: []models.Feedback{}, Voted: []int{}}
+	for _, f := range entries {
+		if f.QAState.Public() {
+			feed.Questions = append(feed.Questions, f)
+		}
+	}
+	sort.Slice(feed.Questions, func(i, j int) bool {
+		return models.RankQuestion(&feed.Questions[i], &feed.Questions[j])
+	})
+
+	if participantID, ok := middleware.GetParticipantID(c); ok {
+		votes, err := h.DB.GetParticipantVotes(room.ID, participantID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve votes"})
+			return
+		}
+		for id := range votes {
+			feed.Voted = append(feed.Voted, id)
+		}
+		sort.Ints(feed.Voted)
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/live"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestQAFeedAndUpvotes(t *testing.T) {
+	store := db.NewMemory()
+	cfg := &config.Config{JWTSecret: "test-secret"}
+	user, err := store.CreateUser("owner@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	room := &models.Room{ID: "QAROOM", Name: "Q&A", CreatorID: user.ID, Type: models.RoomTypeQA}
+	if err := store.CreateRoom(room); err != nil {
+		t.Fatalf("CreateRoom() error: %v", err)
+	}
+
+	questions := map[string]int{}
+	for _, q := range []struct {
+		content string
+		state   models.QAState
+		pinned  bool
+		votes   int
+	}{
+		{"answered", models.QAStateAnswered, false, 5},
+		{"popular", models.QAStateApproved, false, 2},
+		{"quiet", models.QAStateApproved, false, 0},
+		{"pinned", models.QAStateAnswered, true, 0},
+		{"pending", models.QAStatePending, false, 0},
+		{"dismissed", models.QAStateDismissed, false, 9},
+	} {
+		f := &models.Feedback{RoomID: room.ID, Content: q.content, QAState: q.state}
+		if err := store.CreateFeedback(f); err != nil {
+			t.Fatalf("CreateFeedback() error: %v", err)
+		}
+		if err := store.UpdateQuestion(f.ID, q.state, q.pinned); err != nil {
+			t.Fatalf("UpdateQuestion() error: %v", err)
+		}
+		for i := 0; i < q.votes; i++ {
+			if _, err := store.AddVote(f.ID, fmt.Sprintf("voter-%d", i), models.VoteLimits{}); err != nil {
+				t.Fatalf("AddVote() error: %v", err)
+			}
+		}
+		questions[q.content] = f.ID
+	}
+
+	h := NewFeedbackHandler(store, nil, events.NewBus(), nil)
+	router := gin.New()
+	public := router.Group("/rooms/:id", middleware.LoadRoom(store), middleware.RoomParticipant(cfg))
+	public.GET("/feed", h.GetQAFeed)
+	public.POST("/feedback/:feedbackId/votes", h.AddVote)
+
+	token, _, err := middleware.GenerateRoomToken(room, "participant", cfg)
+	if err != nil {
+		t.Fatalf("GenerateRoomToken() error: %v", err)
+	}
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/rooms/"+room.ID+path, nil)
+		req.Header.Set(middleware.RoomTokenHeader, token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	vote := func(content string) int {
+		return request(http.MethodPost, fmt.Sprintf("/feedback/%d/votes", questions[content])).Code
+	}
+
+	steps := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"open question", "quiet", http.StatusCreated},
+		{"second upvote", "quiet", http.StatusConflict},
+		{"answered question", "answered", http.StatusConflict},
+		{"pending question", "pending", http.StatusConflict},
+	}
+	for _, step := range steps {
+		if got := vote(step.content); got != step.want {
+			t.Errorf("%s: upvote = %d, want %d", step.name, got, step.want)
+		}
+	}
+
+	w := request(http.MethodGet, "/feed")
+	if w.Code != http.StatusOK {
+		t.Fatalf("feed = %d %s", w.Code, w.Body)
+	}
+	var feed models.QAFeed
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("failed to decode feed: %v", err)
+	}
+	got := []string{}
+	for _, q := range feed.Questions {
+		got = append(got, q.Content)
+	}
+	if want := []string{"pinned", "popular", "quiet", "answered"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("feed = %v, want %v", got, want)
+	}
+	if want := []int{questions["quiet"]}; !reflect.DeepEqual(feed.Voted, want) {
+		t.Errorf("voted = %v, want %v", feed.Voted, want)
+	}
+}
+
+func TestLiveRedactsUnpublishedQuestions(t *testing.T) {
+	tests := []struct {
+		state       models.QAState
+		role        live.Role
+		wantContent bool
+	}{
+		{models.QAStateApproved, live.RoleParticipant, true},
+		{models.QAStateAnswered, live.RoleParticipant, true},
+		{models.QAStatePending, live.RoleParticipant, false},
+		{models.QAStateDismissed, live.RoleParticipant, false},
+		{models.QAStatePending, live.RoleFacilitator, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.state)+" to "+string(tt.role), func(t *testing.T) {
+			conn := &liveConn{member: live.Member{ID: "p:someone", Role: tt.role}}
+			event := conn.redact(events.Event{
+				Type: events.FeedbackUpdated,
+				Data: models.Feedback{ID: 3, Content: "question", QAState: tt.state},
+			})
+
+			var got models.Feedback
+			decodeEventData(event, &got)
+			if got.ID != 3 || got.QAState != tt.state {
+				t.Errorf("redacted event %+v should keep the question's ID and state", got)
+			}
+			if (got.Content != "") != tt.wantContent {
+				t.Errorf("content %q, want shown %v", got.Content, tt.wantContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// GetQuestions lists the room's survey questions in order; the list is empty
+// for rooms that collect free-text feedback only
+func (h *RoomHandler) GetQuestions(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	questions, err := h.DB.GetQuestions(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve questions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+// SetQuestions replaces the room's survey questions. Questions sent with their
+// id keep their answers; the others are deleted, and answered ones only with
+// force, along with their answers.
+func (h *RoomHandler) SetQuestions(c *gin.Context) {
+	var req models.SetQuestionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+	if room.Type != models.RoomTypeStandard {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only standard rooms can be surveys"})
+		return
+	}
+
+	existing, err := h.DB.GetQuestions(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve questions"})
+		return
+	}
+	usage, err := h.DB.GetQuestionUsage(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve answers"})
+		return
+	}
+	questions, err := req.Validate(existing, usage)
+	var inUse *models.AnswersInUseError
+	if errors.As(err, &inUse) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	saved, err := h.DB.SetQuestions(room.ID, questions)
+	if errors.Is(err, db.ErrQuestionNotFound) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The questions were changed concurrently; reload and try again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save questions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// reactionsResponse lists the reactions offered in a room and those the
+// participant left, per feedback entry
+type reactionsResponse struct {
+	Available []string         `json:"available"`
+	Mine      map[int][]string `json:"mine"`
+}
+
+// reactionResponse reports the reaction counts of a feedback entry after a
+// change, with the participant's own reactions to it
+type reactionResponse struct {
+	FeedbackID int            `json:"feedback_id"`
+	Reactions  map[string]int `json:"reactions"`
+	Mine       []string       `json:"mine"`
+}
+
+// GetReactions lists the reactions offered in the room and, for participants,
+// the reactions they left
+func (h *FeedbackHandler) GetReactions(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	resp := reactionsResponse{Available: room.AvailableReactions(), Mine: map[int][]string{}}
+	if participantID, ok := middleware.GetParticipantID(c); ok {
+		resp.Mine, err = h.DB.GetParticipantReactions(room.ID, participantID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reactions"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AddReaction leaves a reaction of the participant on a feedback entry of the
+// room; leaving the same reaction twice counts once
+func (h *FeedbackHandler) AddReaction(c *gin.Context) {
+	h.react(c, true)
+}
+
+// RemoveReaction takes back a reaction of the participant
+func (h *FeedbackHandler) RemoveReaction(c *gin.Context) {
+	h.react(c, false)
+}
+
+// react adds or removes a reaction after checking that the room offers it and
+// that the entry is visible to the participant
+func (h *FeedbackHandler) react(c *gin.Context, add bool) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	reaction := c.Param("reaction")
+	if !room.AcceptsReaction(reaction) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This room does not offer that reaction"})
+		return
+	}
+	// Cards of others cannot be reacted to while they are hidden
+	if room.Type == models.RoomTypeRetro && room.Phase.CardsHidden() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reactions open once the cards are revealed"})
+		return
+	}
+
+	participantID, ok := middleware.GetParticipantID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Join the room to react"})
+		return
+	}
+
+	feedbackID, err := strconv.Atoi(c.Param("feedbackId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feedback ID"})
+		return
+	}
+
+	// Entries of other rooms are reported as missing
+	feedback, err := h.DB.GetFeedbackByID(feedbackID)
+	if errors.Is(err, db.ErrFeedbackNotFound) || (err == nil && feedback.RoomID != room.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feedback not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback"})
+		return
+	}
+	// Pending and dismissed questions are not on the feed
+	if add && room.Type == models.RoomTypeQA && !feedback.QAState.Public() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only questions on the feed take reactions"})
+		return
+	}
+
+	// Leaving a reaction again changes nothing and is answered with 200
+	changed := true
+	if add {
+		changed, err = h.DB.AddReaction(feedbackID, participantID, reaction)
+	} else {
+		err = h.DB.RemoveReaction(feedbackID, participantID, reaction)
+	}
+	switch {
+	case errors.Is(err, db.ErrReactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No reaction to remove"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reaction"})
+		return
+	}
+
+	feedback, err = h.DB.GetFeedbackByID(feedbackID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback"})
+		return
+	}
+	mine, err := h.DB.GetParticipantReactions(room.ID, participantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reactions"})
+		return
+	}
+
+	resp := reactionResponse{FeedbackID: feedbackID, Reactions: feedback.Reactions, Mine: mine[feedbackID]}
+	if resp.Reactions == nil {
+		resp.Reactions = map[string]int{}
+	}
+	if resp.Mine == nil {
+		resp.Mine = []string{}
+	}
+
+	status := http.StatusOK
+	if changed {
+		h.Events.Publish(events.Event{Type: events.FeedbackUpdated, RoomID: room.ID, Data: feedback})
+		if add {
+			status = http.StatusCreated
+		}
+	}
+	c.JSON(status, resp)
+}
@@ -0,0 +1,485 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/export"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// RetroHandler handles the boards of retro rooms. Routes run after
+// RequireRoomType(models.RoomTypeRetro).
+type RetroHandler struct {
+	DB     db.Store
+	Events events.Publisher
+}
+
+// NewRetroHandler creates a new retro board handler
+func NewRetroHandler(db db.Store, publisher events.Publisher) *RetroHandler {
+	return &RetroHandler{
+		DB:     db,
+		Events: publisher,
+	}
+}
+
+// GetBoard returns the board with its cards by column, groups and action items.
+// The owner sees everything. Participants see only their own cards in the write
+// phase and no vote totals before the discuss phase; their own votes are
+// included.
+func (h *RetroHandler) GetBoard(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	// Owner routes authenticate the user; public routes identify the participant
+	_, err = middleware.GetUserID(c)
+	facilitator := err == nil
+	participantID, _ := middleware.GetParticipantID(c)
+
+	board, err := h.board(room, participantID, facilitator)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve board"})
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// board assembles the board of a room as seen by the facilitator or by one
+// participant, who may be anonymous
+func (h *RetroHandler) board(room *models.Room, participantID string, facilitator bool) (*models.RetroBoard, error) {
+	columns, err := h.DB.GetColumns(room.ID)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := h.DB.GetFeedbackByRoomID(room.ID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := h.DB.GetCardGroups(room.ID)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := h.DB.GetActionItems(room.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &models.RetroBoard{
+		Phase:               room.Phase,
+		VotesPerParticipant: room.VotesPerParticipant,
+		MyVotes:             map[int]int{},
+		Columns:             make([]models.BoardColumn, len(columns)),
+		Groups:              groups,
+		ActionItems:         actions,
+	}
+	if participantID != "" {
+		if board.MyVotes, err = h.DB.GetParticipantVotes(room.ID, participantID); err != nil {
+			return nil, err
+		}
+		for _, n := range board.MyVotes {
+			board.VotesUsed += n
+		}
+	}
+
+	hideCards := !facilitator && room.Phase.CardsHidden()
+	hideVotes := !facilitator && room.Phase.VotesHidden()
+	if hideVotes {
+		for i := range board.Groups {
+			board.Groups[i].Votes = 0
+		}
+	}
+
+	index := make(map[int]int, len(columns))
+	for i, column := range columns {
+		board.Columns[i] = models.BoardColumn{RetroColumn: column, Cards: []models.Feedback{}}
+		index[column.ID] = i
+	}
+	for _, card := range cards {
+		if card.ColumnID == nil {
+			continue
+		}
+		i, ok := index[*card.ColumnID]
+		if !ok {
+			continue
+		}
+		column := &board.Columns[i]
+		if hideCards && (participantID == "" || card.ParticipantID != participantID) {
+			column.Hidden++
+			continue
+		}
+		if hideVotes {
+			card.Votes = 0
+		}
+		column.Cards = append(column.Cards, card)
+	}
+
+	// Cards read oldest first; once totals are revealed, the most voted lead
+	for _, column := range board.Columns {
+		cards := column.Cards
+		sort.SliceStable(cards, func(i, j int) bool {
+			if room.Phase == models.RetroPhaseDiscuss && cards[i].Votes != cards[j].Votes {
+				return cards[i].Votes > cards[j].Votes
+			}
+			return cards[i].ID < cards[j].ID
+		})
+	}
+	return board, nil
+}
+
+// publishUpdate tells the room's live clients to reload the board
+func (h *RetroHandler) publishUpdate(room *models.Room, changed string) {
+	h.Events.Publish(events.Event{
+		Type:   events.RetroUpdated,
+		RoomID: room.ID,
+		Data:   gin.H{"phase": room.Phase, "changed": changed},
+	})
+}
+
+// GetColumns lists the room's columns from left to right
+func (h *RetroHandler) GetColumns(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	columns, err := h.DB.GetColumns(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve columns"})
+		return
+	}
+
+	c.JSON(http.StatusOK, columns)
+}
+
+// SetColumns replaces the room's columns. Columns sent with their id keep their
+// cards; columns left out must be empty.
+func (h *RetroHandler) SetColumns(c *gin.Context) {
+	var req models.SetColumnsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	existing, err := h.DB.GetColumns(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve columns"})
+		return
+	}
+	columns, err := req.Validate(existing)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	saved, err := h.DB.SetColumns(room.ID, columns)
+	switch {
+	case errors.Is(err, db.ErrColumnNotEmpty):
+		c.JSON(http.StatusConflict, gin.H{"error": "Move or delete the cards of a column before removing it"})
+		return
+	case errors.Is(err, db.ErrColumnNotFound):
+		c.JSON(http.StatusConflict, gin.H{"error": "The columns were changed concurrently; reload and try again"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save columns"})
+		return
+	}
+
+	h.publishUpdate(room, "columns")
+	c.JSON(http.StatusOK, saved)
+}
+
+// SetPhase moves the board to another phase, in any order, and optionally
+// changes the number of dots each participant may spend. Dots already spent
+// are kept when the number is lowered.
+func (h *RetroHandler) SetPhase(c *gin.Context) {
+	var req models.RetroPhaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	room.Phase = req.Phase
+	if req.VotesPerParticipant != nil {
+		room.VotesPerParticipant = *req.VotesPerParticipant
+	}
+	if err := h.DB.SetRetroPhase(room.ID, room.Phase, room.VotesPerParticipant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update room"})
+		return
+	}
+
+	h.publishUpdate(room, "phase")
+	c.JSON(http.StatusOK, room.Public())
+}
+
+// CreateGroup groups cards of the board under a title
+func (h *RetroHandler) CreateGroup(c *gin.Context) {
+	h.saveGroup(c, 0, http.StatusCreated)
+}
+
+// UpdateGroup renames a group and replaces its cards. Groups left without cards
+// are deleted.
+func (h *RetroHandler) UpdateGroup(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("groupId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	h.saveGroup(c, id, http.StatusOK)
+}
+
+// saveGroup creates the group when id is 0 and otherwise updates it
+func (h *RetroHandler) saveGroup(c *gin.Context, id, status int) {
+	var req models.CardGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	group := &models.CardGroup{ID: id, RoomID: room.ID, Title: req.Title, FeedbackIDs: req.FeedbackIDs}
+	err = h.DB.SaveCardGroup(group)
+	switch {
+	case errors.Is(err, db.ErrFeedbackNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "feedback_ids must be cards of this room"})
+		return
+	case errors.Is(err, db.ErrCardGroupNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save group"})
+		return
+	}
+
+	h.publishUpdate(room, "groups")
+	c.JSON(status, group)
+}
+
+// DeleteGroup deletes a group; its cards stay on the board
+func (h *RetroHandler) DeleteGroup(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("groupId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	err = h.DB.DeleteCardGroup(room.ID, id)
+	if errors.Is(err, db.ErrCardGroupNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
+		return
+	}
+
+	h.publishUpdate(room, "groups")
+	c.Status(http.StatusNoContent)
+}
+
+// GetActionItems lists the room's action items in creation order
+func (h *RetroHandler) GetActionItems(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	items, err := h.DB.GetActionItems(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve action items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// CreateActionItem adds an action item, optionally linked to a card of the room
+func (h *RetroHandler) CreateActionItem(c *gin.Context) {
+	var req models.ActionItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	if req.FeedbackID != nil {
+		feedback, err := h.DB.GetFeedbackByID(*req.FeedbackID)
+		if errors.Is(err, db.ErrFeedbackNotFound) || (err == nil && feedback.RoomID != room.ID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "feedback_id must be a card of this room"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback"})
+			return
+		}
+	}
+
+	item := &models.ActionItem{RoomID: room.ID, FeedbackID: req.FeedbackID, Title: req.Title, Owner: req.Owner}
+	if err := h.DB.CreateActionItem(item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save action item"})
+		return
+	}
+
+	h.publishUpdate(room, "action_items")
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateActionItem changes the title, owner or done flag of an action item
+func (h *RetroHandler) UpdateActionItem(c *gin.Context) {
+	var req models.UpdateActionItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("actionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action item ID"})
+		return
+	}
+
+	item, err := h.DB.GetActionItem(room.ID, id)
+	if errors.Is(err, db.ErrActionItemNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Action item not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve action item"})
+		return
+	}
+
+	if err := req.Apply(item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = h.DB.UpdateActionItem(item)
+	if errors.Is(err, db.ErrActionItemNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Action item not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update action item"})
+		return
+	}
+
+	h.publishUpdate(room, "action_items")
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteActionItem deletes an action item
+func (h *RetroHandler) DeleteActionItem(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("actionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action item ID"})
+		return
+	}
+
+	err = h.DB.DeleteActionItem(room.ID, id)
+	if errors.Is(err, db.ErrActionItemNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Action item not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete action item"})
+		return
+	}
+
+	h.publishUpdate(room, "action_items")
+	c.Status(http.StatusNoContent)
+}
+
+// ExportActionItems downloads the room's action items as CSV (default), JSON
+// Lines or XLSX
+func (h *RetroHandler) ExportActionItems(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	format := c.DefaultQuery("format", export.FormatCSV)
+	contentType, ok := export.ContentType(format)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, jsonl or xlsx"})
+		return
+	}
+
+	items, err := h.DB.GetActionItems(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve action items"})
+		return
+	}
+
+	// Action items are few; encode them in full so that errors can still be reported
+	var buf bytes.Buffer
+	if err := export.WriteActionItems(format, &buf, items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export action items"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="action-items-%s.%s"`, room.ID, format))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
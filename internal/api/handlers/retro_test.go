@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/live"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestVoteLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		room    models.Room
+		want    models.VoteLimits
+		wantErr bool
+	}{
+		{"retro vote phase", models.Room{Type: models.RoomTypeRetro, Phase: models.RetroPhaseVote, VotesPerParticipant: 5},
+			models.VoteLimits{PerRoom: 5}, false},
+		{"retro write phase", models.Room{Type: models.RoomTypeRetro, Phase: models.RetroPhaseWrite}, models.VoteLimits{}, true},
+		{"retro discuss phase", models.Room{Type: models.RoomTypeRetro, Phase: models.RetroPhaseDiscuss}, models.VoteLimits{}, true},
+		{"q&a", models.Room{Type: models.RoomTypeQA}, models.VoteLimits{PerEntry: 1}, false},
+		{"standard", models.Room{Type: models.RoomTypeStandard}, models.VoteLimits{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := voteLimits(&tt.room)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("voteLimits() = %+v, %v, want %+v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetroBoardHiding(t *testing.T) {
+	store := db.NewMemory()
+	user, err := store.CreateUser("owner@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	room := &models.Room{ID: "RETRO1", Name: "Retro", CreatorID: user.ID, Type: models.RoomTypeRetro, VotesPerParticipant: 3}
+	if err := store.CreateRoom(room); err != nil {
+		t.Fatalf("CreateRoom() error: %v", err)
+	}
+	columns, err := store.SetColumns(room.ID, []models.RetroColumn{{Position: 1, Title: "Went well"}})
+	if err != nil {
+		t.Fatalf("SetColumns() error: %v", err)
+	}
+	for _, author := range []string{"alice", "bob", "bob"} {
+		card := &models.Feedback{RoomID: room.ID, Content: "card by " + author, ColumnID: &columns[0].ID, ParticipantID: author}
+		if err := store.CreateFeedback(card); err != nil {
+			t.Fatalf("CreateFeedback() error: %v", err)
+		}
+		if _, err := store.AddVote(card.ID, "alice", models.VoteLimits{}); err != nil {
+			t.Fatalf("AddVote() error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		phase       models.RetroPhase
+		participant string
+		facilitator bool
+		wantCards   int
+		wantHidden  int
+		wantVotes   bool
+	}{
+		{"write phase, author", models.RetroPhaseWrite, "bob", false, 2, 1, false},
+		{"write phase, anonymous", models.RetroPhaseWrite, "", false, 0, 3, false},
+		{"write phase, facilitator", models.RetroPhaseWrite, "", true, 3, 0, true},
+		{"vote phase", models.RetroPhaseVote, "bob", false, 3, 0, false},
+		{"discuss phase", models.RetroPhaseDiscuss, "bob", false, 3, 0, true},
+	}
+
+	h := NewRetroHandler(store, events.NewBus())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room.Phase = tt.phase
+			board, err := h.board(room, tt.participant, tt.facilitator)
+			if err != nil {
+				t.Fatalf("board() error: %v", err)
+			}
+			column := board.Columns[0]
+			if len(column.Cards) != tt.wantCards || column.Hidden != tt.wantHidden {
+				t.Errorf("board() shows %d cards and hides %d, want %d and %d",
+					len(column.Cards), column.Hidden, tt.wantCards, tt.wantHidden)
+			}
+			for _, card := range column.Cards {
+				if (card.Votes > 0) != tt.wantVotes {
+					t.Errorf("card %d shows %d votes, want totals shown %v", card.ID, card.Votes, tt.wantVotes)
+				}
+			}
+		})
+	}
+}
+
+func TestLiveRedactsHiddenCards(t *testing.T) {
+	columnID := 4
+	card := models.Feedback{ID: 7, Content: "secret", ColumnID: &columnID}
+	phase := func(p models.RetroPhase) events.Event {
+		return events.Event{Type: events.RetroUpdated, Data: map[string]interface{}{"phase": p}}
+	}
+	created := events.Event{Type: events.FeedbackCreated, Data: card}
+
+	tests := []struct {
+		name        string
+		role        live.Role
+		events      []events.Event
+		wantContent bool
+	}{
+		{"participant in write phase", live.RoleParticipant, []events.Event{phase(models.RetroPhaseWrite), created}, false},
+		{"participant after reveal", live.RoleParticipant,
+			[]events.Event{phase(models.RetroPhaseWrite), phase(models.RetroPhaseGroup), created}, true},
+		{"facilitator in write phase", live.RoleFacilitator, []events.Event{phase(models.RetroPhaseWrite), created}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &liveConn{member: live.Member{ID: "p:someone", Role: tt.role}}
+			var last events.Event
+			for _, event := range tt.events {
+				last = conn.redact(event)
+			}
+
+			var got struct {
+				ID       int    `json:"id"`
+				ColumnID *int   `json:"column_id"`
+				Content  string `json:"content"`
+			}
+			decodeEventData(last, &got)
+			if got.ID != card.ID || got.ColumnID == nil || *got.ColumnID != columnID {
+				t.Errorf("redacted event %+v should keep the card's ID and column", got)
+			}
+			if (got.Content != "") != tt.wantContent {
+				t.Errorf("content %q, want shown %v", got.Content, tt.wantContent)
+			}
+		})
+	}
+}
@@ -1,25 +1,39 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
 	"github.com/panaalexandrucristian/feedback-collector/internal/db"
 	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+	"github.com/panaalexandrucristian/feedback-collector/internal/roomcode"
 )
 
 // RoomHandler handles room-related routes
 type RoomHandler struct {
-	DB *db.Database
+	DB    db.Store
+	Cfg   *config.Config
+	Codes *roomcode.Generator
 }
 
+// maxRoomCodeAttempts bounds the retries when a generated room code is already taken
+const maxRoomCodeAttempts = 5
+
 // NewRoomHandler creates a new room handler
-func NewRoomHandler(db *db.Database) *RoomHandler {
-	return &RoomHandler{DB: db}
+func NewRoomHandler(db db.Store, cfg *config.Config, codes *roomcode.Generator) *RoomHandler {
+	return &RoomHandler{
+		DB:    db,
+		Cfg:   cfg,
+		Codes: codes,
+	}
 }
 
 // CreateRoom handles creating a new feedback room
@@ -30,14 +44,26 @@ func (h *RoomHandler) CreateRoom(c *gin.Context) {
 		return
 	}
 
+	now := time.Now()
+	if err := req.Validate(now); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	userID, err := middleware.GetUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
 		return
 	}
 
-	// Generate a unique room ID - 6 characters alphanumeric
-	roomID := uuid.New().String()[:6]
+	// Vanity slugs are checked up front; generated codes are retried on collision below
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if slug != "" {
+		if err := roomcode.ValidateSlug(slug); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	// Hash password if provided
 	var passwordHash string
@@ -51,15 +77,104 @@ func (h *RoomHandler) CreateRoom(c *gin.Context) {
 	}
 
 	// Create room
-	room, err := h.DB.CreateRoom(roomID, req.Name, userID, passwordHash)
+	room := &models.Room{
+		Name:      req.Name,
+		Password:  passwordHash,
+		CreatorID: userID,
+		Status:    req.Status,
+		OpensAt:   req.OpensAt,
+		ClosesAt:  req.ClosesAt,
+		Type:      req.Type,
+		Moderated: req.Moderated,
+		Reactions: req.Reactions,
+	}
+
+	// Retro boards start with participants writing cards
+	if room.Type == models.RoomTypeRetro {
+		room.Phase = models.RetroPhaseWrite
+		room.VotesPerParticipant = req.VotesPerParticipant
+		if room.VotesPerParticipant == 0 {
+			room.VotesPerParticipant = models.DefaultRetroVotes
+		}
+	}
+
+	// Rooms scheduled to open later stay in draft until the scheduler opens them
+	if room.OpensAt != nil && room.OpensAt.After(now) {
+		room.Status = models.RoomStatusDraft
+	}
+	if slug != "" {
+		room.ID = slug
+		err = h.createUnlessTaken(room)
+		if errors.Is(err, db.ErrRoomIDTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Slug is already in use"})
+			return
+		}
+	} else {
+		err = h.createWithGeneratedCode(room)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
 		return
 	}
+	if room.Type == models.RoomTypeRetro {
+		if err := h.createColumns(room, req.Columns); err != nil {
+			h.DB.DeleteRoom(room.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
+			return
+		}
+	}
+
+	// Remove password from response
+	room.Password = ""
 
 	c.JSON(http.StatusCreated, room)
 }
 
+// createWithGeneratedCode inserts the room under a fresh random code, retrying on collisions
+func (h *RoomHandler) createWithGeneratedCode(room *models.Room) error {
+	for attempt := 0; attempt < maxRoomCodeAttempts; attempt++ {
+		code, err := h.Codes.Generate()
+		if err != nil {
+			return err
+		}
+
+		room.ID = code
+		err = h.createUnlessTaken(room)
+		if !errors.Is(err, db.ErrRoomIDTaken) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("no free room code after %d attempts", maxRoomCodeAttempts)
+}
+
+// createUnlessTaken inserts the room unless its ID is in use in any case, so
+// that a slug never shadows a generated code typed in lower case or the reverse
+func (h *RoomHandler) createUnlessTaken(room *models.Room) error {
+	_, err := middleware.FindRoom(h.DB, room.ID)
+	if err == nil {
+		return db.ErrRoomIDTaken
+	}
+	if !errors.Is(err, db.ErrRoomNotFound) {
+		return err
+	}
+	return h.DB.CreateRoom(room)
+}
+
+// createColumns sets up the columns of a new retro room, using the default
+// columns when none are given
+func (h *RoomHandler) createColumns(room *models.Room, titles []string) error {
+	if len(titles) == 0 {
+		titles = models.DefaultRetroColumns
+	}
+	columns := make([]models.RetroColumn, len(titles))
+	for i, title := range titles {
+		columns[i] = models.RetroColumn{Position: i + 1, Title: title}
+	}
+	_, err := h.DB.SetColumns(room.ID, columns)
+	return err
+}
+
 // GetRooms returns all rooms created by the authenticated user
 func (h *RoomHandler) GetRooms(c *gin.Context) {
 	userID, err := middleware.GetUserID(c)
@@ -77,13 +192,11 @@ func (h *RoomHandler) GetRooms(c *gin.Context) {
 	c.JSON(http.StatusOK, rooms)
 }
 
-// GetRoomByID retrieves a room by ID
+// GetRoomByID returns the room loaded by the room-access middleware
 func (h *RoomHandler) GetRoomByID(c *gin.Context) {
-	roomID := c.Param("id")
-
-	room, err := h.DB.GetRoomByID(roomID)
+	room, err := middleware.GetRoom(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
 		return
 	}
 
@@ -93,20 +206,17 @@ func (h *RoomHandler) GetRoomByID(c *gin.Context) {
 	c.JSON(http.StatusOK, room)
 }
 
-// JoinRoom handles joining a password-protected room
+// JoinRoom verifies the room password, if any, and issues a room-scoped participant token
 func (h *RoomHandler) JoinRoom(c *gin.Context) {
-	roomID := c.Param("id")
-
 	var req models.JoinRoomRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get room
-	room, err := h.DB.GetRoomByID(roomID)
+	room, err := middleware.GetRoom(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
 		return
 	}
 
@@ -120,8 +230,129 @@ func (h *RoomHandler) JoinRoom(c *gin.Context) {
 		}
 	}
 
+	// Issue a participant token for this room
+	participantID := middleware.JoinParticipantID(c, room, h.Cfg, h.DB)
+	token, expiresAt, err := middleware.GenerateRoomToken(room, participantID, h.Cfg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	// Remove password from response
+	room.Password = ""
+
+	c.JSON(http.StatusOK, models.JoinRoomResponse{
+		Room:      *room,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// UpdateRoom applies a partial update to a room: rename, change or remove the password,
+// or move it to another lifecycle status
+func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+	var req models.UpdateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	if req.Name != nil {
+		room.Name = *req.Name
+	}
+
+	if req.Reactions != nil {
+		reactions := *req.Reactions
+		if reactions == nil {
+			reactions = []string{}
+		}
+		if err := models.ValidateReactions(reactions); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		room.Reactions = reactions
+	}
+
+	if req.Password != nil {
+		room.Password = ""
+		if *req.Password != "" {
+			hash, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+				return
+			}
+			room.Password = string(hash)
+		}
+	}
+
+	now := time.Now()
+	if req.OpensAt.Set || req.ClosesAt.Set {
+		if req.OpensAt.Set {
+			room.OpensAt = req.OpensAt.Time
+		}
+		if req.ClosesAt.Set {
+			room.ClosesAt = req.ClosesAt.Time
+		}
+		if err := models.ValidateSchedule(room.OpensAt, room.ClosesAt, now); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if req.Status != nil && *req.Status != room.Status {
+		if !room.Status.CanTransitionTo(*req.Status) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": fmt.Sprintf("Cannot change room status from %s to %s", room.Status, *req.Status),
+			})
+			return
+		}
+		// The scheduler would close the room again straight away
+		if *req.Status == models.RoomStatusOpen && room.ClosesAt != nil && !now.Before(*room.ClosesAt) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Room closes_at has passed; set a later closes_at to reopen it"})
+			return
+		}
+		room.Status = *req.Status
+	}
+
+	// As on creation, open rooms scheduled to open later go back to draft
+	// until the scheduler opens them
+	if room.Status == models.RoomStatusOpen && room.OpensAt != nil && room.OpensAt.After(now) {
+		if req.Status != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Room opens_at is in the future; clear it or set an earlier opens_at to open the room now"})
+			return
+		}
+		room.Status = models.RoomStatusDraft
+	}
+
+	if err := h.DB.UpdateRoom(room); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update room"})
+		return
+	}
+
 	// Remove password from response
 	room.Password = ""
 
 	c.JSON(http.StatusOK, room)
 }
+
+// DeleteRoom deletes a room and all of its feedback
+func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	if err := h.DB.DeleteRoom(room.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete room"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
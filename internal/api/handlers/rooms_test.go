@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// newJoinRouter routes joining, voting and reacting on the public endpoints of a
+// Q&A room holding one open question
+func newJoinRouter(t *testing.T) (*gin.Engine, db.Store, *config.Config, *models.Room, int) {
+	t.Helper()
+
+	store := db.NewMemory()
+	cfg := &config.Config{JWTSecret: "test-secret", AccessTokenTTL: time.Minute}
+	user, err := store.CreateUser("owner@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	room := &models.Room{ID: "JOINROOM", Name: "Q&A", CreatorID: user.ID, Type: models.RoomTypeQA}
+	if err := store.CreateRoom(room); err != nil {
+		t.Fatalf("CreateRoom() error: %v", err)
+	}
+	question := &models.Feedback{RoomID: room.ID, Content: "question", QAState: models.QAStateApproved}
+	if err := store.CreateFeedback(question); err != nil {
+		t.Fatalf("CreateFeedback() error: %v", err)
+	}
+	if err := store.UpdateQuestion(question.ID, models.QAStateApproved, false); err != nil {
+		t.Fatalf("UpdateQuestion() error: %v", err)
+	}
+
+	rooms := NewRoomHandler(store, cfg, nil)
+	feedback := NewFeedbackHandler(store, nil, events.NewBus(), nil)
+	router := gin.New()
+	public := router.Group("/rooms/:id", middleware.LoadRoom(store))
+	public.POST("/join", rooms.JoinRoom)
+	public.POST("/feedback/:feedbackId/votes", middleware.RoomParticipant(cfg), feedback.AddVote)
+	public.PUT("/feedback/:feedbackId/reactions/:reaction", middleware.RoomParticipant(cfg), feedback.AddReaction)
+	return router, store, cfg, room, question.ID
+}
+
+// joinRoom joins room with the given request headers and returns the participant token
+func joinRoom(t *testing.T, router *gin.Engine, roomID string, header http.Header) string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/rooms/"+roomID+"/join", strings.NewReader(`{}`))
+	req.Header = header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("join = %d %s", w.Code, w.Body)
+	}
+
+	var resp models.JoinRoomResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode join response: %v", err)
+	}
+	return resp.Token
+}
+
+func TestRejoinKeepsParticipant(t *testing.T) {
+	tests := []struct {
+		name   string
+		header func(t *testing.T, cfg *config.Config, user int, token string) http.Header
+	}{
+		{"room token", func(t *testing.T, cfg *config.Config, user int, token string) http.Header {
+			return http.Header{middleware.RoomTokenHeader: {token}}
+		}},
+		{"signed in", func(t *testing.T, cfg *config.Config, user int, token string) http.Header {
+			access, _, err := middleware.GenerateToken(user, cfg)
+			if err != nil {
+				t.Fatalf("GenerateToken() error: %v", err)
+			}
+			return http.Header{"Authorization": {"Bearer " + access}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, store, cfg, room, questionID := newJoinRouter(t)
+			user, err := store.CreateUser("participant@example.com", "password")
+			if err != nil {
+				t.Fatalf("CreateUser() error: %v", err)
+			}
+			upvote := func(token string) int {
+				req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/rooms/%s/feedback/%d/votes", room.ID, questionID), nil)
+				req.Header.Set(middleware.RoomTokenHeader, token)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w.Code
+			}
+
+			first := joinRoom(t, router, room.ID, tt.header(t, cfg, user.ID, ""))
+			if got := upvote(first); got != http.StatusCreated {
+				t.Fatalf("first upvote = %d, want %d", got, http.StatusCreated)
+			}
+
+			again := joinRoom(t, router, room.ID, tt.header(t, cfg, user.ID, first))
+			if got := upvote(again); got != http.StatusConflict {
+				t.Errorf("upvote after rejoining = %d, want %d", got, http.StatusConflict)
+			}
+			question, err := store.GetFeedbackByID(questionID)
+			if err != nil {
+				t.Fatalf("GetFeedbackByID() error: %v", err)
+			}
+			if question.Votes != 1 {
+				t.Errorf("votes = %d, want 1", question.Votes)
+			}
+		})
+	}
+}
+
+func TestRejoinKeepsReactions(t *testing.T) {
+	router, store, _, room, questionID := newJoinRouter(t)
+	react := func(token string) int {
+		path := fmt.Sprintf("/rooms/%s/feedback/%d/reactions/%s", room.ID, questionID, url.PathEscape("👍"))
+		req := httptest.NewRequest(http.MethodPut, path, nil)
+		req.Header.Set(middleware.RoomTokenHeader, token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	first := joinRoom(t, router, room.ID, http.Header{})
+	if got := react(first); got != http.StatusCreated {
+		t.Fatalf("first reaction = %d, want %d", got, http.StatusCreated)
+	}
+
+	again := joinRoom(t, router, room.ID, http.Header{middleware.RoomTokenHeader: {first}})
+	if got := react(again); got != http.StatusOK {
+		t.Errorf("reaction after rejoining = %d, want %d", got, http.StatusOK)
+	}
+	question, err := store.GetFeedbackByID(questionID)
+	if err != nil {
+		t.Fatalf("GetFeedbackByID() error: %v", err)
+	}
+	if got := question.Reactions["👍"]; got != 1 {
+		t.Errorf("reactions = %d, want 1", got)
+	}
+}
+
+func TestUpdateRoomStatus(t *testing.T) {
+	now := time.Now().UTC()
+	at := func(d time.Duration) *time.Time {
+		ts := now.Add(d)
+		return &ts
+	}
+	later := now.Add(time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name       string
+		status     models.RoomStatus
+		opensAt    *time.Time
+		closesAt   *time.Time
+		body       string
+		wantCode   int
+		wantStatus models.RoomStatus
+	}{
+		{"close", models.RoomStatusOpen, nil, nil, `{"status":"closed"}`, http.StatusOK, models.RoomStatusClosed},
+		{"reopen", models.RoomStatusClosed, nil, at(time.Hour), `{"status":"open"}`, http.StatusOK, models.RoomStatusOpen},
+		{"open draft", models.RoomStatusDraft, nil, nil, `{"status":"open"}`, http.StatusOK, models.RoomStatusOpen},
+		{"unarchive", models.RoomStatusArchived, nil, nil, `{"status":"closed"}`, http.StatusOK, models.RoomStatusClosed},
+		{"same status", models.RoomStatusOpen, nil, nil, `{"status":"open"}`, http.StatusOK, models.RoomStatusOpen},
+		{"close draft", models.RoomStatusDraft, nil, nil, `{"status":"closed"}`, http.StatusConflict, models.RoomStatusDraft},
+		{"reopen archived", models.RoomStatusArchived, nil, nil, `{"status":"open"}`, http.StatusConflict, models.RoomStatusArchived},
+		{"unknown status", models.RoomStatusOpen, nil, nil, `{"status":"deleted"}`, http.StatusBadRequest, models.RoomStatusOpen},
+		// The scheduler would close the room again straight away
+		{"reopen after closes_at", models.RoomStatusClosed, nil, at(-time.Minute), `{"status":"open"}`,
+			http.StatusConflict, models.RoomStatusClosed},
+		{"reopen with a later closes_at", models.RoomStatusClosed, nil, at(-time.Minute),
+			`{"status":"open","closes_at":"` + later + `"}`, http.StatusOK, models.RoomStatusOpen},
+		{"reopen clearing closes_at", models.RoomStatusClosed, nil, at(-time.Minute), `{"status":"open","closes_at":null}`,
+			http.StatusOK, models.RoomStatusOpen},
+		{"open before opens_at", models.RoomStatusDraft, at(time.Hour), nil, `{"status":"open"}`,
+			http.StatusConflict, models.RoomStatusDraft},
+		{"schedule open room", models.RoomStatusOpen, nil, nil, `{"opens_at":"` + later + `"}`,
+			http.StatusOK, models.RoomStatusDraft},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := db.NewMemory()
+			user, err := store.CreateUser("owner@example.com", "password")
+			if err != nil {
+				t.Fatalf("CreateUser() error: %v", err)
+			}
+			room := &models.Room{
+				ID:        "LIFECYCLE",
+				Name:      "Lifecycle",
+				CreatorID: user.ID,
+				Status:    tt.status,
+				OpensAt:   tt.opensAt,
+				ClosesAt:  tt.closesAt,
+			}
+			if err := store.CreateRoom(room); err != nil {
+				t.Fatalf("CreateRoom() error: %v", err)
+			}
+
+			h := NewRoomHandler(store, &config.Config{}, nil)
+			router := gin.New()
+			router.PATCH("/rooms/:id", middleware.LoadRoom(store), h.UpdateRoom)
+			req := httptest.NewRequest(http.MethodPatch, "/rooms/"+room.ID, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("PATCH = %d %s, want %d", w.Code, w.Body, tt.wantCode)
+			}
+			stored, err := store.GetRoomByID(room.ID)
+			if err != nil {
+				t.Fatalf("GetRoomByID() error: %v", err)
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+const (
+	// streamHeartbeatInterval keeps idle connections alive through proxies
+	streamHeartbeatInterval = 15 * time.Second
+	// streamBuffer is how many events a slow client may fall behind before it is
+	// disconnected and has to resume with Last-Event-ID
+	streamBuffer = 64
+	// streamReplayBatch is the number of missed entries loaded per query on resume
+	streamReplayBatch = 500
+	// streamRetry is the reconnection delay suggested to clients, in milliseconds
+	streamRetry = 3000
+)
+
+// StreamFeedback pushes the room's new feedback, sentiment updates and lifecycle
+// events as Server-Sent Events. feedback.created events carry the feedback ID as
+// their event ID; a client reconnecting with Last-Event-ID first receives the
+// entries it missed, with their current sentiment.
+func (h *FeedbackHandler) StreamFeedback(c *gin.Context) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	lastID := 0
+	header := c.GetHeader("Last-Event-ID")
+	if header != "" {
+		lastID, err = strconv.Atoi(header)
+		if err != nil || lastID < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	// Subscribe before replaying so that nothing published in between is lost
+	sub := h.Hub.Subscribe(room.ID, streamBuffer)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteString("retry: " + strconv.Itoa(streamRetry) + "\n\n")
+
+	if header != "" {
+		for {
+			missed, err := h.DB.GetFeedbackAfter(room.ID, lastID, streamReplayBatch)
+			if err != nil {
+				return
+			}
+			for i := range missed {
+				lastID = missed[i].ID
+				writeStreamEvent(c, events.Event{
+					Type:   events.FeedbackCreated,
+					RoomID: room.ID,
+					Data:   &missed[i],
+					Time:   missed[i].CreatedAt,
+				}, lastID)
+			}
+			if len(missed) < streamReplayBatch {
+				break
+			}
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			// Closed on shutdown or when the client fell behind; it will reconnect
+			if !ok {
+				return
+			}
+
+			id := 0
+			if event.Type == events.FeedbackCreated {
+				feedback, ok := event.Data.(*models.Feedback)
+				if !ok || feedback.ID <= lastID {
+					continue
+				}
+				id, lastID = feedback.ID, feedback.ID
+			}
+			writeStreamEvent(c, event, id)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			c.Writer.WriteString(": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeStreamEvent writes an event named after its type with the JSON-encoded
+// event as data; id is omitted when zero
+func writeStreamEvent(c *gin.Context, event events.Event, id int) {
+	e := sse.Event{Event: event.Type, Data: event}
+	if id > 0 {
+		e.Id = strconv.Itoa(id)
+	}
+	sse.Encode(c.Writer, e)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// streamTest serves the feedback stream of one room over HTTP
+type streamTest struct {
+	url   string
+	store db.Store
+	cfg   *config.Config
+	bus   *events.Bus
+	hub   *events.Hub
+	room  *models.Room
+	owner *models.User
+}
+
+func newStreamTest(t *testing.T) *streamTest {
+	t.Helper()
+
+	store := db.NewMemory()
+	cfg := &config.Config{JWTSecret: "test-secret", AccessTokenTTL: time.Minute}
+	owner, err := store.CreateUser("owner@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	room := &models.Room{ID: "STREAM", Name: "Stream", CreatorID: owner.ID}
+	if err := store.CreateRoom(room); err != nil {
+		t.Fatalf("CreateRoom() error: %v", err)
+	}
+
+	bus := events.NewBus()
+	hub := events.NewHub()
+	bus.Subscribe(hub.Publish)
+	h := NewFeedbackHandler(store, nil, bus, hub)
+
+	router := gin.New()
+	router.GET("/rooms/:id/feedback/stream",
+		middleware.AuthMiddleware(cfg, store), middleware.LoadRoom(store), middleware.RequireRoomOwner(),
+		h.StreamFeedback)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &streamTest{url: server.URL, store: store, cfg: cfg, bus: bus, hub: hub, room: room, owner: owner}
+}
+
+// open requests the stream of roomID as userID, or anonymously when userID is zero
+func (st *streamTest) open(t *testing.T, ctx context.Context, roomID string, userID int, header http.Header) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, st.url+"/rooms/"+roomID+"/feedback/stream", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error: %v", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if userID != 0 {
+		token, _, err := middleware.GenerateToken(userID, st.cfg)
+		if err != nil {
+			t.Fatalf("GenerateToken() error: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET stream error: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// streamEvent is one Server-Sent Event
+type streamEvent struct {
+	id    string
+	event string
+	data  events.Event
+}
+
+// readStreamEvent reads the next event, skipping the retry hint and heartbeats
+func readStreamEvent(t *testing.T, r *bufio.Reader) streamEvent {
+	t.Helper()
+
+	var e streamEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		field, value, _ := strings.Cut(line, ":")
+		switch field {
+		case "id":
+			e.id = value
+		case "event":
+			e.event = value
+		case "data":
+			if err := json.Unmarshal([]byte(value), &e.data); err != nil {
+				t.Fatalf("failed to decode event data %q: %v", value, err)
+			}
+		case "":
+			if e.event != "" {
+				return e
+			}
+		}
+	}
+}
+
+func TestStreamFeedbackAccess(t *testing.T) {
+	st := newStreamTest(t)
+	other, err := st.store.CreateUser("other@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		roomID string
+		userID int
+		want   int
+	}{
+		{"anonymous", st.room.ID, 0, http.StatusUnauthorized},
+		{"other user", st.room.ID, other.ID, http.StatusForbidden},
+		{"unknown room", "MISSING", st.owner.ID, http.StatusNotFound},
+		{"owner", st.room.ID, st.owner.ID, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			resp := st.open(t, ctx, tt.roomID, tt.userID, nil)
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want == http.StatusOK && resp.Header.Get("Content-Type") != "text/event-stream" {
+				t.Errorf("Content-Type = %q, want text/event-stream", resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestStreamFeedbackDeliversEvents(t *testing.T) {
+	st := newStreamTest(t)
+	missed := &models.Feedback{RoomID: st.room.ID, Content: "before connecting"}
+	if err := st.store.CreateFeedback(missed); err != nil {
+		t.Fatalf("CreateFeedback() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resp := st.open(t, ctx, st.room.ID, st.owner.ID, http.Header{"Last-Event-Id": {"0"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	body := bufio.NewReader(resp.Body)
+
+	// Resuming replays the entries after Last-Event-ID
+	replayed := readStreamEvent(t, body)
+	if replayed.event != events.FeedbackCreated || replayed.id != "1" || replayed.data.RoomID != st.room.ID {
+		t.Errorf("replayed event = %+v, want feedback 1", replayed)
+	}
+
+	created := &models.Feedback{ID: 2, RoomID: st.room.ID, Content: "live"}
+	st.bus.Publish(events.Event{Type: events.FeedbackCreated, RoomID: st.room.ID, Data: created})
+	// Entries already sent are skipped
+	st.bus.Publish(events.Event{Type: events.FeedbackCreated, RoomID: st.room.ID, Data: missed})
+	st.bus.Publish(events.Event{Type: events.RoomClosed, RoomID: st.room.ID, Data: st.room.Public()})
+	// Events of other rooms are not delivered
+	st.bus.Publish(events.Event{Type: events.FeedbackCreated, RoomID: "OTHER", Data: &models.Feedback{ID: 3}})
+
+	live := readStreamEvent(t, body)
+	data, _ := json.Marshal(live.data.Data)
+	var feedback models.Feedback
+	json.Unmarshal(data, &feedback)
+	if live.event != events.FeedbackCreated || live.id != "2" || feedback.Content != "live" {
+		t.Errorf("live event = %+v, want feedback 2", live)
+	}
+	if closed := readStreamEvent(t, body); closed.event != events.RoomClosed || closed.id != "" {
+		t.Errorf("next event = %+v, want %s without an ID", closed, events.RoomClosed)
+	}
+}
+
+func TestStreamFeedbackReleasesSubscription(t *testing.T) {
+	st := newStreamTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := st.open(t, ctx, st.room.ID, st.owner.ID, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := st.hub.Subscribers(st.room.ID); got != 1 {
+		t.Fatalf("subscribers while connected = %d, want 1", got)
+	}
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for st.hub.Subscribers(st.room.ID) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscription was not released after the client disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
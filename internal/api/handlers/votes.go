@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// voteResponse reports the participant's votes after a change. The entry's
+// total is left out where totals are hidden until the votes are revealed.
+type voteResponse struct {
+	FeedbackID int  `json:"feedback_id"`
+	Votes      int  `json:"votes"`           // Of the participant on this entry
+	VotesUsed  int  `json:"votes_used"`      // Of the participant across the room
+	Total      *int `json:"total,omitempty"` // Of the entry, in Q&A rooms
+}
+
+// AddVote spends one of the participant's votes on a feedback entry of the room
+func (h *FeedbackHandler) AddVote(c *gin.Context) {
+	h.vote(c, true)
+}
+
+// RemoveVote takes back one of the participant's votes on a feedback entry
+func (h *FeedbackHandler) RemoveVote(c *gin.Context) {
+	h.vote(c, false)
+}
+
+// vote adds or removes a vote after checking that the room takes votes now
+func (h *FeedbackHandler) vote(c *gin.Context, add bool) {
+	room, err := middleware.GetRoom(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	limits, err := voteLimits(room)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	participantID, ok := middleware.GetParticipantID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Join the room to vote"})
+		return
+	}
+
+	feedbackID, err := strconv.Atoi(c.Param("feedbackId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feedback ID"})
+		return
+	}
+
+	// Entries of other rooms are reported as missing
+	feedback, err := h.DB.GetFeedbackByID(feedbackID)
+	if errors.Is(err, db.ErrFeedbackNotFound) || (err == nil && feedback.RoomID != room.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feedback not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback"})
+		return
+	}
+	// Answered, dismissed and pending questions are closed for votes
+	if add && room.Type == models.RoomTypeQA && feedback.QAState != models.QAStateApproved {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only open questions can be upvoted"})
+		return
+	}
+
+	var total int
+	if add {
+		total, err = h.DB.AddVote(feedbackID, participantID, limits)
+	} else {
+		total, err = h.DB.RemoveVote(feedbackID, participantID)
+	}
+	switch {
+	case errors.Is(err, db.ErrVoteLimit) && room.Type == models.RoomTypeQA:
+		c.JSON(http.StatusConflict, gin.H{"error": "You already upvoted this question"})
+		return
+	case errors.Is(err, db.ErrVoteLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": "No votes left"})
+		return
+	case errors.Is(err, db.ErrVoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No vote to remove"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save vote"})
+		return
+	}
+
+	votes, err := h.DB.GetParticipantVotes(room.ID, participantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve votes"})
+		return
+	}
+	resp := voteResponse{FeedbackID: feedbackID, Votes: votes[feedbackID]}
+	for _, n := range votes {
+		resp.VotesUsed += n
+	}
+
+	// Q&A feeds are ranked live by votes
+	if room.Type == models.RoomTypeQA {
+		resp.Total = &total
+		feedback.Votes = total
+		h.Events.Publish(events.Event{Type: events.FeedbackUpdated, RoomID: room.ID, Data: feedback})
+	}
+
+	status := http.StatusOK
+	if add {
+		status = http.StatusCreated
+	}
+	c.JSON(status, resp)
+}
+
+// voteLimits returns the limits on the votes of one participant in the room,
+// or an error when the room does not take votes at the moment. Retro boards
+// take dots in the vote phase only; Q&A rooms take one upvote per question.
+func voteLimits(room *models.Room) (models.VoteLimits, error) {
+	switch room.Type {
+	case models.RoomTypeRetro:
+		if room.Phase != models.RetroPhaseVote {
+			return models.VoteLimits{}, errors.New("votes can only be cast in the vote phase")
+		}
+		return models.VoteLimits{PerRoom: room.VotesPerParticipant}, nil
+	case models.RoomTypeQA:
+		return models.VoteLimits{PerEntry: 1}, nil
+	}
+	return models.VoteLimits{}, errors.New("this room does not take votes")
+}
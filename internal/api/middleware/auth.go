@@ -9,29 +9,65 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 
 	"github.com/panaalexandrucristian/feedback-collector/internal/config"
 )
 
+const (
+	tokenIssuer = "feedback-collector"
+
+	// Audiences keep user tokens and room participant tokens from being used in place of each other
+	userAudience = "feedback-collector/api"
+	roomAudience = "feedback-collector/room"
+)
+
 // Claims represents the JWT claims
 type Claims struct {
 	UserID int `json:"user_id"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a new JWT token for a user
-func GenerateToken(userID int, cfg *config.Config) (string, error) {
+const (
+	userContextKey           = "userID"
+	tokenIDContextKey        = "tokenID"
+	tokenExpiresAtContextKey = "tokenExpiresAt"
+)
+
+// TokenDenylist reports whether an access token has been revoked before its expiry
+type TokenDenylist interface {
+	IsAccessTokenRevoked(jti string) (bool, error)
+}
+
+// GenerateToken generates a new short-lived JWT access token for a user and
+// returns it with its claims. Every token carries a unique ID (jti) so that it
+// can be revoked on logout or when its refresh token family is revoked.
+func GenerateToken(userID int, cfg *config.Config) (string, *Claims, error) {
+	expiresAt := time.Now().Add(cfg.AccessTokenTTL)
+
 	// Create claims with user ID and standard claims
 	claims := &Claims{
 		UserID: userID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Token expires in 24 hours
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "feedback-collector",
+			Issuer:    tokenIssuer,
+			Audience:  jwt.ClaimStrings{userAudience},
 		},
 	}
 
+	tokenString, err := signToken(claims, cfg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tokenString, claims, nil
+}
+
+// signToken signs claims with HS256 and the configured secret
+func signToken(claims jwt.Claims, cfg *config.Config) (string, error) {
 	// Create token using claims and signing method
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
@@ -44,8 +80,51 @@ func GenerateToken(userID int, cfg *config.Config) (string, error) {
 	return tokenString, nil
 }
 
-// AuthMiddleware checks if the request has a valid JWT token
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// parseToken validates the signature, expiry, issuer and audience of a token and fills claims
+func parseToken(tokenString string, claims jwt.Claims, audience string, cfg *config.Config) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Validate signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(cfg.JWTSecret), nil
+	}, jwt.WithIssuer(tokenIssuer), jwt.WithAudience(audience))
+	if err != nil {
+		return err
+	}
+
+	// Check if token is valid
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+
+	return nil
+}
+
+// ErrTokenRevoked is returned by ParseAccessToken for tokens on the denylist
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// ParseAccessToken validates a user access token outside of AuthMiddleware, for
+// connections that authenticate after the HTTP request, such as WebSockets
+func ParseAccessToken(tokenString string, cfg *config.Config, denylist TokenDenylist) (*Claims, error) {
+	claims := &Claims{}
+	if err := parseToken(tokenString, claims, userAudience, cfg); err != nil {
+		return nil, err
+	}
+
+	revoked, err := denylist.IsAccessTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// AuthMiddleware checks if the request has a valid, unrevoked JWT access token
+func AuthMiddleware(cfg *config.Config, denylist TokenDenylist) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -65,34 +144,33 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 
 		// Parse and validate token
 		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(cfg.JWTSecret), nil
-		})
-
-		if err != nil {
+		if err := parseToken(tokenString, claims, userAudience, cfg); err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			return
 		}
 
-		// Check if token is valid
-		if !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		// Reject tokens revoked by logout or refresh token reuse
+		revoked, err := denylist.IsAccessTokenRevoked(claims.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
 			return
 		}
 
-		// Set user ID in context
-		c.Set("userID", claims.UserID)
+		// Set user ID and token identity in context
+		c.Set(userContextKey, claims.UserID)
+		c.Set(tokenIDContextKey, claims.ID)
+		c.Set(tokenExpiresAtContextKey, claims.ExpiresAt.Time)
 		c.Next()
 	}
 }
 
 // GetUserID extracts user ID from context after auth middleware
 func GetUserID(c *gin.Context) (int, error) {
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get(userContextKey)
 	if !exists {
 		return 0, errors.New("user ID not found in context")
 	}
@@ -104,3 +182,13 @@ func GetUserID(c *gin.Context) (int, error) {
 
 	return id, nil
 }
+
+// GetTokenID returns the ID and expiry of the access token validated by AuthMiddleware
+func GetTokenID(c *gin.Context) (string, time.Time, error) {
+	jti := c.GetString(tokenIDContextKey)
+	if jti == "" {
+		return "", time.Time{}, errors.New("token ID not found in context")
+	}
+
+	return jti, c.GetTime(tokenExpiresAtContextKey), nil
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// RoomTokenHeader carries the participant token issued by JoinRoom
+const RoomTokenHeader = "X-Room-Token"
+
+// RoomTokenTTL is how long a participant token stays valid after joining a room
+const RoomTokenTTL = 2 * time.Hour
+
+const participantContextKey = "participantID"
+
+// RoomClaims represents the claims of a room participant token.
+// The participant ID is carried in the standard subject claim.
+type RoomClaims struct {
+	RoomID string `json:"room_id"`
+	// PasswordVersion fingerprints the room password the participant joined
+	// with, so that changing the password signs everyone out
+	PasswordVersion string `json:"pwv,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// passwordVersion fingerprints the stored password hash of a room. The bcrypt
+// salt changes on every update, so setting the same password again also
+// changes the version.
+func passwordVersion(room *models.Room) string {
+	if room.Password == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(room.Password))
+	return hex.EncodeToString(sum[:8])
+}
+
+// GenerateRoomToken issues a short-lived token that lets a participant submit to one room
+func GenerateRoomToken(room *models.Room, participantID string, cfg *config.Config) (string, time.Time, error) {
+	expiresAt := time.Now().Add(RoomTokenTTL)
+	claims := &RoomClaims{
+		RoomID:          room.ID,
+		PasswordVersion: passwordVersion(room),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   participantID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    tokenIssuer,
+			Audience:  jwt.ClaimStrings{roomAudience},
+		},
+	}
+
+	tokenString, err := signToken(claims, cfg)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// ParseRoomToken validates a participant token and checks that it was issued
+// for room and, in password-protected rooms, for its current password
+func ParseRoomToken(tokenString string, room *models.Room, cfg *config.Config) (*RoomClaims, error) {
+	claims := &RoomClaims{}
+	if err := parseToken(tokenString, claims, roomAudience, cfg); err != nil {
+		return nil, err
+	}
+
+	if claims.RoomID != room.ID {
+		return nil, errors.New("token was issued for a different room")
+	}
+	if room.IsPasswordProtected && claims.PasswordVersion != passwordVersion(room) {
+		return nil, errors.New("room password has changed since the token was issued")
+	}
+
+	return claims, nil
+}
+
+// UserParticipantID is the participant ID of a signed-in user, the same on the
+// HTTP endpoints and on live connections
+func UserParticipantID(userID int) string {
+	return "u:" + strconv.Itoa(userID)
+}
+
+// JoinParticipantID picks the participant ID for a join: the subject of a
+// still-valid room token presented for room, the signed-in user of a valid access
+// token, or else a new anonymous ID. Keeping the ID stable when rejoining stops
+// participants from voting or reacting twice.
+func JoinParticipantID(c *gin.Context, room *models.Room, cfg *config.Config, denylist TokenDenylist) string {
+	if tokenString := c.GetHeader(RoomTokenHeader); tokenString != "" {
+		if claims, err := ParseRoomToken(tokenString, room, cfg); err == nil && claims.Subject != "" {
+			return claims.Subject
+		}
+	}
+
+	if tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		if claims, err := ParseAccessToken(tokenString, cfg, denylist); err == nil {
+			return UserParticipantID(claims.UserID)
+		}
+	}
+
+	return uuid.New().String()
+}
+
+// RoomParticipant validates the participant token of the room loaded by LoadRoom.
+// A token is mandatory for password-protected rooms and optional otherwise; when
+// present and valid the participant ID is stored in the context.
+func RoomParticipant(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		room, err := GetRoom(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Room not loaded"})
+			return
+		}
+
+		tokenString := c.GetHeader(RoomTokenHeader)
+		if tokenString == "" {
+			if room.IsPasswordProtected {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "This room is password protected; join it first"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		claims, err := ParseRoomToken(tokenString, room, cfg)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired room token"})
+			return
+		}
+
+		c.Set(participantContextKey, claims.Subject)
+		c.Next()
+	}
+}
+
+// GetParticipantID returns the participant ID set by RoomParticipant, if any
+func GetParticipantID(c *gin.Context) (string, bool) {
+	value, exists := c.Get(participantContextKey)
+	if !exists {
+		return "", false
+	}
+
+	id, ok := value.(string)
+	return id, ok && id != ""
+}
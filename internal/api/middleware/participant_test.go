@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestParseRoomTokenChecksPassword(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "test-secret"}
+	room := &models.Room{ID: "ABC234", Password: "$2a$10$first", IsPasswordProtected: true}
+	token, _, err := GenerateRoomToken(room, "participant", cfg)
+	if err != nil {
+		t.Fatalf("GenerateRoomToken: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		room    models.Room
+		wantErr bool
+	}{
+		{"same password", *room, false},
+		{"other room", models.Room{ID: "XYZ789", Password: room.Password, IsPasswordProtected: true}, true},
+		{"password changed", models.Room{ID: room.ID, Password: "$2a$10$second", IsPasswordProtected: true}, true},
+		{"password removed", models.Room{ID: room.ID}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ParseRoomToken(token, &tt.room, cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRoomToken() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && claims.Subject != "participant" {
+				t.Errorf("subject = %q, want participant", claims.Subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+	"github.com/panaalexandrucristian/feedback-collector/internal/roomcode"
+	"github.com/panaalexandrucristian/feedback-collector/internal/utils"
+)
+
+const roomContextKey = "room"
+
+// LoadRoom validates the :id parameter, loads the room once and stores it in the
+// context for the handlers and access checks that follow
+func LoadRoom(store db.RoomStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roomID := c.Param("id")
+		if !utils.ValidateRoomID(roomID) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+			return
+		}
+
+		room, err := FindRoom(store, roomID)
+		if errors.Is(err, db.ErrRoomNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+			return
+		}
+
+		c.Set(roomContextKey, room)
+		c.Next()
+	}
+}
+
+// FindRoom looks a room up by an ID typed in any case: generated codes are
+// stored in upper case and slugs in lower case
+func FindRoom(store db.RoomStore, roomID string) (*models.Room, error) {
+	for _, id := range roomcode.Variants(roomID) {
+		room, err := store.GetRoomByID(id)
+		if !errors.Is(err, db.ErrRoomNotFound) {
+			return room, err
+		}
+	}
+	return nil, db.ErrRoomNotFound
+}
+
+// RequireRoomOwner allows the request only when the authenticated user created
+// the room loaded by LoadRoom. It must run after AuthMiddleware and LoadRoom.
+func RequireRoomOwner() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		room, err := GetRoom(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Room not loaded"})
+			return
+		}
+
+		userID, err := GetUserID(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+			return
+		}
+
+		if room.CreatorID != userID {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have access to this room"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRoomType allows the request only for rooms of the given type, reporting
+// the route as missing for other rooms. It must run after LoadRoom.
+func RequireRoomType(roomType models.RoomType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		room, err := GetRoom(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Room not loaded"})
+			return
+		}
+
+		if room.Type != roomType {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Only " + string(roomType) + " rooms have this resource"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetRoom returns the room loaded by LoadRoom
+func GetRoom(c *gin.Context) (*models.Room, error) {
+	value, exists := c.Get(roomContextKey)
+	if !exists {
+		return nil, errors.New("room not found in context")
+	}
+
+	room, ok := value.(*models.Room)
+	if !ok {
+		return nil, errors.New("room is of invalid type")
+	}
+
+	return room, nil
+}
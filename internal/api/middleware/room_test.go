@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/config"
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestRoomAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := db.NewMemory()
+	cfg := &config.Config{JWTSecret: "test-secret", AccessTokenTTL: time.Minute}
+	owner, err := store.CreateUser("owner@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	other, err := store.CreateUser("other@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	for _, room := range []*models.Room{
+		{ID: "ABC234", Name: "Code", CreatorID: owner.ID},
+		{ID: "team-retro", Name: "Slug", CreatorID: owner.ID, Type: models.RoomTypeRetro},
+	} {
+		if err := store.CreateRoom(room); err != nil {
+			t.Fatalf("CreateRoom() error: %v", err)
+		}
+	}
+
+	router := gin.New()
+	owned := router.Group("/rooms/:id", AuthMiddleware(cfg, store), LoadRoom(store), RequireRoomOwner())
+	owned.GET("", func(c *gin.Context) {
+		room, _ := GetRoom(c)
+		c.JSON(http.StatusOK, room)
+	})
+	owned.GET("/board", RequireRoomType(models.RoomTypeRetro), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		userID int
+		want   int
+	}{
+		{"owner", "/rooms/ABC234", owner.ID, http.StatusOK},
+		{"code in lower case", "/rooms/abc234", owner.ID, http.StatusOK},
+		{"slug in upper case", "/rooms/TEAM-RETRO", owner.ID, http.StatusOK},
+		{"other user", "/rooms/ABC234", other.ID, http.StatusForbidden},
+		{"anonymous", "/rooms/ABC234", 0, http.StatusUnauthorized},
+		// Missing rooms are reported as such to every signed-in user
+		{"missing room", "/rooms/MISSING", owner.ID, http.StatusNotFound},
+		{"missing room for other user", "/rooms/MISSING", other.ID, http.StatusNotFound},
+		{"invalid room ID", "/rooms/a_b", owner.ID, http.StatusBadRequest},
+		{"room of the type", "/rooms/team-retro/board", owner.ID, http.StatusOK},
+		{"room of another type", "/rooms/ABC234/board", owner.ID, http.StatusNotFound},
+		{"room of the type for other user", "/rooms/team-retro/board", other.ID, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.userID != 0 {
+				token, _, err := GenerateToken(tt.userID, cfg)
+				if err != nil {
+					t.Fatalf("GenerateToken() error: %v", err)
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("GET %s = %d %s, want %d", tt.path, w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
@@ -8,10 +8,15 @@ import (
 	"github.com/panaalexandrucristian/feedback-collector/internal/api/middleware"
 	"github.com/panaalexandrucristian/feedback-collector/internal/config"
 	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/jobs"
+	"github.com/panaalexandrucristian/feedback-collector/internal/live"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+	"github.com/panaalexandrucristian/feedback-collector/internal/roomcode"
 )
 
 // SetupRouter configures the HTTP router
-func SetupRouter(cfg *config.Config, db *db.Database) *gin.Engine {
+func SetupRouter(cfg *config.Config, db db.Store, codes *roomcode.Generator, queue *jobs.Queue, publisher events.Publisher, hub *events.Hub) *gin.Engine {
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -22,51 +27,95 @@ func SetupRouter(cfg *config.Config, db *db.Database) *gin.Engine {
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", middleware.RoomTokenHeader},
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition", "X-Total-Count", "X-Next-Cursor"},
 		AllowCredentials: true,
 	}))
 
 	// Create handlers
 	authHandler := handlers.NewAuthHandler(db, cfg)
-	roomHandler := handlers.NewRoomHandler(db)
-	feedbackHandler := handlers.NewFeedbackHandler(db)
+	roomHandler := handlers.NewRoomHandler(db, cfg, codes)
+	feedbackHandler := handlers.NewFeedbackHandler(db, queue, publisher, hub)
+	liveHandler := handlers.NewLiveHandler(feedbackHandler, cfg, live.NewPresence())
+	analyticsHandler := handlers.NewAnalyticsHandler(db)
+	retroHandler := handlers.NewRetroHandler(db, publisher)
+
+	// Authentication middleware, consulting the store's access token denylist
+	requireAuth := middleware.AuthMiddleware(cfg, db)
 
 	// Auth routes
 	auth := router.Group("/api/auth")
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
-		auth.GET("/me", middleware.AuthMiddleware(cfg), authHandler.GetCurrentUser)
+		auth.POST("/refresh", authHandler.Refresh)
+		auth.POST("/logout", requireAuth, authHandler.Logout)
+		auth.GET("/me", requireAuth, authHandler.GetCurrentUser)
 	}
 
 	// Room routes
 	rooms := router.Group("/api/rooms")
 	{
-		rooms.Use(middleware.AuthMiddleware(cfg))
+		rooms.Use(requireAuth)
 		rooms.POST("", roomHandler.CreateRoom)
 		rooms.GET("", roomHandler.GetRooms)
-		rooms.GET("/:id", roomHandler.GetRoomByID)
-	}
 
-	// Public room access
-	publicRooms := router.Group("/api/public/rooms")
-	{
-		publicRooms.GET("/:id", roomHandler.GetRoomByID)
-		publicRooms.POST("/:id/join", roomHandler.JoinRoom)
+		// Room-scoped routes are only available to the room creator
+		room := rooms.Group("/:id", middleware.LoadRoom(db), middleware.RequireRoomOwner())
+		room.GET("", roomHandler.GetRoomByID)
+		room.PATCH("", roomHandler.UpdateRoom)
+		room.DELETE("", roomHandler.DeleteRoom)
+		room.GET("/analytics", analyticsHandler.GetRoomAnalytics)
+		room.GET("/questions", roomHandler.GetQuestions)
+		room.PUT("/questions", roomHandler.SetQuestions)
+		room.GET("/feedback", feedbackHandler.GetFeedback)
+		room.GET("/feedback/stream", feedbackHandler.StreamFeedback)
+		room.GET("/feedback/export", feedbackHandler.ExportFeedback)
+		room.POST("/feedback/import", feedbackHandler.ImportFeedback)
+		room.PATCH("/feedback/:feedbackId", feedbackHandler.UpdateFeedback)
+
+		// Retro boards
+		retro := room.Group("", middleware.RequireRoomType(models.RoomTypeRetro))
+		retro.GET("/board", retroHandler.GetBoard)
+		retro.GET("/columns", retroHandler.GetColumns)
+		retro.PUT("/columns", retroHandler.SetColumns)
+		retro.PUT("/phase", retroHandler.SetPhase)
+		retro.POST("/groups", retroHandler.CreateGroup)
+		retro.PUT("/groups/:groupId", retroHandler.UpdateGroup)
+		retro.DELETE("/groups/:groupId", retroHandler.DeleteGroup)
+		retro.GET("/actions", retroHandler.GetActionItems)
+		retro.POST("/actions", retroHandler.CreateActionItem)
+		retro.GET("/actions/export", retroHandler.ExportActionItems)
+		retro.PATCH("/actions/:actionId", retroHandler.UpdateActionItem)
+		retro.DELETE("/actions/:actionId", retroHandler.DeleteActionItem)
 	}
 
-	// Feedback routes
-	feedback := router.Group("/api/public/rooms/:id/feedback")
+	// Activity summary across all of the caller's rooms
+	router.GET("/api/dashboard", requireAuth, analyticsHandler.GetDashboard)
+
+	// Feedback across all of the caller's rooms
+	feedback := router.Group("/api/feedback")
 	{
-		feedback.POST("", feedbackHandler.CreateFeedback)
+		feedback.Use(requireAuth)
+		feedback.GET("/search", feedbackHandler.SearchFeedback)
 	}
 
-	// Protected feedback retrieval (only for room creators)
-	protectedFeedback := router.Group("/api/rooms/:id/feedback")
+	// Public room access and feedback submission
+	publicRoom := router.Group("/api/public/rooms/:id")
 	{
-		protectedFeedback.Use(middleware.AuthMiddleware(cfg))
-		protectedFeedback.GET("", feedbackHandler.GetFeedback)
+		publicRoom.Use(middleware.LoadRoom(db))
+		publicRoom.GET("", roomHandler.GetRoomByID)
+		publicRoom.POST("/join", roomHandler.JoinRoom)
+		publicRoom.GET("/questions", middleware.RoomParticipant(cfg), roomHandler.GetQuestions)
+		publicRoom.POST("/feedback", middleware.RoomParticipant(cfg), feedbackHandler.CreateFeedback)
+		publicRoom.POST("/feedback/:feedbackId/votes", middleware.RoomParticipant(cfg), feedbackHandler.AddVote)
+		publicRoom.DELETE("/feedback/:feedbackId/votes", middleware.RoomParticipant(cfg), feedbackHandler.RemoveVote)
+		publicRoom.GET("/reactions", middleware.RoomParticipant(cfg), feedbackHandler.GetReactions)
+		publicRoom.PUT("/feedback/:feedbackId/reactions/:reaction", middleware.RoomParticipant(cfg), feedbackHandler.AddReaction)
+		publicRoom.DELETE("/feedback/:feedbackId/reactions/:reaction", middleware.RoomParticipant(cfg), feedbackHandler.RemoveReaction)
+		publicRoom.GET("/board", middleware.RoomParticipant(cfg), middleware.RequireRoomType(models.RoomTypeRetro), retroHandler.GetBoard)
+		publicRoom.GET("/feed", middleware.RoomParticipant(cfg), middleware.RequireRoomType(models.RoomTypeQA), feedbackHandler.GetQAFeed)
+		publicRoom.GET("/ws", liveHandler.ServeRoom)
 	}
 
 	// Health check
@@ -0,0 +1,50 @@
+// Package broadcast relays room and feedback events to the live subscribers of
+// every server instance. With PostgreSQL, events are fanned out between
+// instances with LISTEN/NOTIFY; the other drivers run a single instance and
+// deliver events locally only.
+package broadcast
+
+import (
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// Broadcaster publishes events to the hubs of all instances
+type Broadcaster interface {
+	Publish(event events.Event)
+	Close() error
+}
+
+// FeedbackLoader reloads feedback whose event was too large to send inline
+type FeedbackLoader interface {
+	GetFeedbackByID(id int) (*models.Feedback, error)
+}
+
+// New returns the broadcaster suited to the storage driver
+func New(driver, databaseURL string, hub *events.Hub, loader FeedbackLoader) (Broadcaster, error) {
+	if driver == db.DriverPostgres {
+		return NewPostgres(databaseURL, hub, loader)
+	}
+	return NewLocal(hub), nil
+}
+
+// local delivers events to the hub of this instance only
+type local struct {
+	hub *events.Hub
+}
+
+// NewLocal returns a broadcaster for single-instance deployments
+func NewLocal(hub *events.Hub) Broadcaster {
+	return &local{hub: hub}
+}
+
+// Publish delivers the event to local subscribers
+func (l *local) Publish(event events.Event) {
+	l.hub.Publish(event)
+}
+
+// Close is a no-op for the local broadcaster
+func (l *local) Close() error {
+	return nil
+}
@@ -0,0 +1,258 @@
+package broadcast
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+const (
+	// channel is the NOTIFY channel shared by all instances
+	channel = "feedback_collector_events"
+	// maxPayload stays below PostgreSQL's 8000 byte NOTIFY payload limit
+	maxPayload = 7900
+	// outboxSize bounds the events waiting to be sent with NOTIFY
+	outboxSize = 1024
+	// pingInterval checks an idle listener connection so that drops are noticed
+	pingInterval = time.Minute
+)
+
+// notification is the NOTIFY payload. Feedback events too large to send inline
+// carry only the feedback ID and are reloaded by the receiving instances. The
+// author of feedback is not part of its JSON and travels alongside it.
+type notification struct {
+	Origin        string          `json:"origin"`
+	Type          string          `json:"type"`
+	RoomID        string          `json:"room_id"`
+	Time          time.Time       `json:"time"`
+	Data          json.RawMessage `json:"data,omitempty"`
+	FeedbackID    int             `json:"feedback_id,omitempty"`
+	ParticipantID string          `json:"participant_id,omitempty"`
+}
+
+// postgres delivers events locally right away and sends them to the other
+// instances with NOTIFY; notifications from other instances are relayed to the
+// local hub
+type postgres struct {
+	origin   string
+	hub      *events.Hub
+	loader   FeedbackLoader
+	conn     *sql.DB
+	listener *pq.Listener
+	outbox   chan notification
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewPostgres connects the NOTIFY sender and the LISTEN connection. The listener
+// reconnects on its own; after a reconnection local subscriptions are closed so
+// that clients resume from the database and catch up on anything missed.
+func NewPostgres(databaseURL string, hub *events.Hub, loader FeedbackLoader) (Broadcaster, error) {
+	conn, err := db.Connect(db.DriverPostgres, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			log.Printf("Event listener disconnected: %v\n", err)
+		case pq.ListenerEventReconnected:
+			log.Println("Event listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Printf("Event listener failed to reconnect: %v\n", err)
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to listen for events: %w", err)
+	}
+
+	b := &postgres{
+		origin:   uuid.NewString(),
+		hub:      hub,
+		loader:   loader,
+		conn:     conn,
+		listener: listener,
+		outbox:   make(chan notification, outboxSize),
+		done:     make(chan struct{}),
+	}
+	b.wg.Add(2)
+	go b.send()
+	go b.listen()
+
+	return b, nil
+}
+
+// Publish delivers the event locally and queues it for the other instances
+// without blocking; events are dropped with a log line when the queue is full
+func (b *postgres) Publish(event events.Event) {
+	b.hub.Publish(event)
+
+	n, err := b.encode(event)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v\n", event.Type, err)
+		return
+	}
+
+	select {
+	case b.outbox <- n:
+	default:
+		log.Printf("Event outbox full; %s event for room %s not broadcast\n", event.Type, event.RoomID)
+	}
+}
+
+// Close stops relaying and closes both connections
+func (b *postgres) Close() error {
+	close(b.done)
+	b.wg.Wait()
+
+	err := b.listener.Close()
+	if cerr := b.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// encode builds the notification for an event, falling back to a reference
+// for feedback events that do not fit in a NOTIFY payload
+func (b *postgres) encode(event events.Event) (notification, error) {
+	n := notification{Origin: b.origin, Type: event.Type, RoomID: event.RoomID, Time: event.Time}
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return n, err
+	}
+	n.Data = data
+	feedback, isFeedback := event.Data.(*models.Feedback)
+	if isFeedback {
+		n.ParticipantID = feedback.ParticipantID
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return n, err
+	}
+	if len(payload) <= maxPayload {
+		return n, nil
+	}
+
+	if !isFeedback {
+		return n, fmt.Errorf("payload of %d bytes exceeds the NOTIFY limit", len(payload))
+	}
+	n.Data, n.ParticipantID = nil, ""
+	n.FeedbackID = feedback.ID
+	return n, nil
+}
+
+// send runs NOTIFY for queued events until Close, flushing what is left
+func (b *postgres) send() {
+	defer b.wg.Done()
+
+	for {
+		select {
+		case n := <-b.outbox:
+			b.notify(n)
+		case <-b.done:
+			for {
+				select {
+				case n := <-b.outbox:
+					b.notify(n)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (b *postgres) notify(n notification) {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		log.Printf("Failed to encode %s notification: %v\n", n.Type, err)
+		return
+	}
+	if _, err := b.conn.Exec(`SELECT pg_notify($1, $2)`, channel, string(payload)); err != nil {
+		log.Printf("Failed to broadcast %s event for room %s: %v\n", n.Type, n.RoomID, err)
+	}
+}
+
+// listen relays notifications from other instances to the local hub
+func (b *postgres) listen() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case n := <-b.listener.Notify:
+			// A nil notification follows a reconnection; anything sent while the
+			// connection was down has been lost
+			if n == nil {
+				b.hub.CloseSubscriptions()
+				continue
+			}
+			b.relay(n.Extra)
+		case <-ticker.C:
+			go b.listener.Ping()
+		}
+	}
+}
+
+// relay decodes a notification and publishes it to the local hub
+func (b *postgres) relay(payload string) {
+	var n notification
+	if err := json.NewDecoder(strings.NewReader(payload)).Decode(&n); err != nil {
+		log.Printf("Ignoring malformed event notification: %v\n", err)
+		return
+	}
+	if n.Origin == b.origin {
+		return
+	}
+
+	event := events.Event{Type: n.Type, RoomID: n.RoomID, Time: n.Time}
+	data, err := b.decodeData(&n)
+	if err != nil {
+		log.Printf("Ignoring %s notification for room %s: %v\n", n.Type, n.RoomID, err)
+		return
+	}
+	event.Data = data
+
+	b.hub.Publish(event)
+}
+
+// decodeData restores the payload types published by this application so that
+// subscribers see the same values as for local events
+func (b *postgres) decodeData(n *notification) (interface{}, error) {
+	switch {
+	case n.FeedbackID != 0:
+		return b.loader.GetFeedbackByID(n.FeedbackID)
+	case strings.HasPrefix(n.Type, "feedback."):
+		feedback := &models.Feedback{}
+		err := json.Unmarshal(n.Data, feedback)
+		feedback.ParticipantID = n.ParticipantID
+		return feedback, err
+	case strings.HasPrefix(n.Type, "room."):
+		var room models.Room
+		err := json.Unmarshal(n.Data, &room)
+		return room, err
+	case len(n.Data) == 0:
+		return nil, nil
+	default:
+		return n.Data, nil
+	}
+}
@@ -0,0 +1,30 @@
+package broadcast
+
+import (
+	"testing"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestRelayedFeedbackKeepsAuthor(t *testing.T) {
+	b := &postgres{origin: "sender"}
+	feedback := &models.Feedback{ID: 7, RoomID: "ABC234", Content: "hi", ParticipantID: "p-1"}
+
+	n, err := b.encode(events.Event{Type: events.FeedbackCreated, RoomID: "ABC234", Data: feedback})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	data, err := b.decodeData(&n)
+	if err != nil {
+		t.Fatalf("decodeData: %v", err)
+	}
+
+	relayed, ok := data.(*models.Feedback)
+	if !ok {
+		t.Fatalf("decodeData returned %T, want *models.Feedback", data)
+	}
+	if relayed.ID != feedback.ID || relayed.Content != feedback.Content || relayed.ParticipantID != feedback.ParticipantID {
+		t.Errorf("relayed feedback = %+v, want %+v", relayed, feedback)
+	}
+}
@@ -5,16 +5,34 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/roomcode"
 )
 
 type Config struct {
-	Port           int
-	DatabaseURL    string
-	JWTSecret      string
-	AllowedOrigins []string
-	Environment    string
+	Port            int
+	DatabaseURL     string
+	DatabaseDriver  string
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	AllowedOrigins  []string
+	Environment     string
+
+	// SchedulerInterval is how often room opening and closing times are applied
+	SchedulerInterval time.Duration
+
+	// Background job workers and how often idle workers poll for new jobs
+	JobWorkers      int
+	JobPollInterval time.Duration
+
+	// Generated room codes
+	RoomCodeAlphabet string
+	RoomCodeLength   int
 }
 
 // Load loads configuration from environment variables
@@ -25,13 +43,27 @@ func Load() *Config {
 	}
 
 	port, _ := strconv.Atoi(getEnv("PORT", "8080"))
+	jobWorkers, _ := strconv.Atoi(getEnv("JOB_WORKERS", "2"))
+	roomCodeLength, _ := strconv.Atoi(getEnv("ROOM_CODE_LENGTH", strconv.Itoa(roomcode.DefaultLength)))
+	databaseURL := getEnv("DATABASE_URL", "postgres://localhost:5432/feedback_collector?sslmode=disable")
 
 	return &Config{
-		Port:           port,
-		DatabaseURL:    getEnv("DATABASE_URL", "postgres://localhost:5432/feedback_collector?sslmode=disable"),
-		JWTSecret:      getEnv("JWT_SECRET", "super_secret_key_change_this_in_production"),
-		AllowedOrigins: []string{getEnv("ALLOWED_ORIGIN", "http://localhost:3000")},
-		Environment:    getEnv("ENVIRONMENT", "development"),
+		Port:            port,
+		DatabaseURL:     databaseURL,
+		DatabaseDriver:  databaseDriver(databaseURL),
+		JWTSecret:       getEnv("JWT_SECRET", "super_secret_key_change_this_in_production"),
+		AccessTokenTTL:  getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		AllowedOrigins:  []string{getEnv("ALLOWED_ORIGIN", "http://localhost:3000")},
+		Environment:     getEnv("ENVIRONMENT", "development"),
+
+		SchedulerInterval: getDuration("SCHEDULER_INTERVAL", 30*time.Second),
+
+		JobWorkers:      jobWorkers,
+		JobPollInterval: getDuration("JOB_POLL_INTERVAL", time.Second),
+
+		RoomCodeAlphabet: getEnv("ROOM_CODE_ALPHABET", roomcode.DefaultAlphabet),
+		RoomCodeLength:   roomCodeLength,
 	}
 }
 
@@ -44,6 +76,34 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// Helper function to get a duration environment variable (e.g. "15m") with a default value
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// databaseDriver derives the storage driver from the scheme of the database URL
+func databaseDriver(databaseURL string) string {
+	scheme, _, found := strings.Cut(databaseURL, ":")
+	if !found {
+		return ""
+	}
+
+	switch strings.ToLower(scheme) {
+	case "postgres", "postgresql":
+		return "postgres"
+	case "sqlite", "sqlite3", "file":
+		return "sqlite"
+	case "memory":
+		return "memory"
+	default:
+		return strings.ToLower(scheme)
+	}
+}
+
 // GetPortString returns the port as a formatted string for HTTP server
 func (c *Config) GetPortString() string {
 	return fmt.Sprintf(":%d", c.Port)
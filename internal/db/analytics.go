@@ -0,0 +1,318 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// peakWindowCount is the number of busiest hours of the week reported
+const peakWindowCount = 5
+
+// bucketExpressions render the start of the interval of a timestamp column,
+// given as the first argument, as sqliteTimeFormat text
+var bucketExpressions = map[string]map[string]string{
+	DriverPostgres: {
+		models.IntervalHour: `to_char(date_trunc('hour', %[1]s AT TIME ZONE 'UTC'), 'YYYY-MM-DD HH24:MI:SS')`,
+		models.IntervalDay:  `to_char(date_trunc('day', %[1]s AT TIME ZONE 'UTC'), 'YYYY-MM-DD HH24:MI:SS')`,
+		models.IntervalWeek: `to_char(date_trunc('week', %[1]s AT TIME ZONE 'UTC'), 'YYYY-MM-DD HH24:MI:SS')`,
+	},
+	DriverSQLite: {
+		models.IntervalHour: `strftime('%%Y-%%m-%%d %%H:00:00', %[1]s)`,
+		models.IntervalDay:  `strftime('%%Y-%%m-%%d 00:00:00', %[1]s)`,
+		models.IntervalWeek: `strftime('%%Y-%%m-%%d 00:00:00', %[1]s,
+			'-' || ((CAST(strftime('%%w', %[1]s) AS INTEGER) + 6) %% 7) || ' days')`,
+	},
+}
+
+// integerAnswerExpressions read the integer value of a rating or NPS answer
+var integerAnswerExpressions = map[string]string{
+	DriverPostgres: `CAST(a.value #>> '{}' AS INTEGER)`,
+	DriverSQLite:   `CAST(a.value AS INTEGER)`,
+}
+
+// bucketExpression renders the start of the interval of column
+func (s *sqlStore) bucketExpression(interval, column string) string {
+	return fmt.Sprintf(bucketExpressions[s.driver][interval], column)
+}
+
+// weekHourExpressions select the UTC weekday (0 is Sunday) and hour of created_at
+var weekHourExpressions = map[string]string{
+	DriverPostgres: `CAST(EXTRACT(DOW FROM created_at AT TIME ZONE 'UTC') AS INTEGER),
+		CAST(EXTRACT(HOUR FROM created_at AT TIME ZONE 'UTC') AS INTEGER)`,
+	DriverSQLite: `CAST(strftime('%w', created_at) AS INTEGER),
+		CAST(strftime('%H', created_at) AS INTEGER)`,
+}
+
+// GetRoomAnalytics aggregates a room's feedback over a period in the database:
+// counts and score sums per interval and sentiment, counts per hour of the week
+// and, for survey rooms, NPS and rating answers per interval and value
+func (s *sqlStore) GetRoomAnalytics(roomID string, q models.AnalyticsQuery) (*models.RoomAnalytics, error) {
+	questions, err := s.GetQuestions(roomID)
+	if err != nil {
+		return nil, err
+	}
+	a := newAnalytics(q, questions)
+	from, to := s.timeArg(a.result.From), s.timeArg(a.result.To)
+
+	rows, err := s.query(
+		`SELECT `+s.bucketExpression(q.Interval, "created_at")+` AS bucket, sentiment,
+			COUNT(*), COALESCE(SUM(sentiment_score), 0), COUNT(sentiment_score)
+		 FROM feedback
+		 WHERE room_id = $1 AND created_at >= $2 AND created_at < $3
+		 GROUP BY bucket, sentiment`,
+		roomID, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate feedback: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, label string
+		var count, scored int
+		var scoreSum float64
+		if err := rows.Scan(&bucket, &label, &count, &scoreSum, &scored); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback aggregate: %w", err)
+		}
+		start, err := time.ParseInLocation(sqliteTimeFormat, bucket, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse interval %q: %w", bucket, err)
+		}
+		a.add(start, label, count, scoreSum, scored)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	rows, err = s.query(
+		`SELECT `+weekHourExpressions[s.driver]+`, COUNT(*) AS submissions
+		 FROM feedback
+		 WHERE room_id = $1 AND created_at >= $2 AND created_at < $3
+		 GROUP BY 1, 2
+		 ORDER BY submissions DESC, 1, 2
+		 LIMIT $4`,
+		roomID, from, to, peakWindowCount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate submission windows: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var weekday, hour, count int
+		if err := rows.Scan(&weekday, &hour, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan submission window: %w", err)
+		}
+		a.result.PeakWindows = append(a.result.PeakWindows, models.SubmissionWindow{
+			Weekday: time.Weekday(weekday).String(),
+			Hour:    hour,
+			Count:   count,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(a.questions) > 0 {
+		if err := s.aggregateScores(roomID, q.Interval, from, to, a); err != nil {
+			return nil, err
+		}
+	}
+	return a.finish(), nil
+}
+
+// aggregateScores counts the answers to a room's NPS and rating questions per
+// interval, question and value
+func (s *sqlStore) aggregateScores(roomID, interval string, from, to interface{}, a *analytics) error {
+	rows, err := s.query(
+		`SELECT `+s.bucketExpression(interval, "f.created_at")+` AS bucket, a.question_id,
+			`+integerAnswerExpressions[s.driver]+` AS answer, COUNT(*)
+		 FROM answers a
+		 JOIN questions q ON q.id = a.question_id
+		 JOIN feedback f ON f.id = a.feedback_id
+		 WHERE q.room_id = $1 AND q.type IN ($2, $3) AND f.created_at >= $4 AND f.created_at < $5
+		 GROUP BY bucket, a.question_id, answer`,
+		roomID, models.QuestionNPS, models.QuestionRating, from, to,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate answers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket string
+		var questionID, value, count int
+		if err := rows.Scan(&bucket, &questionID, &value, &count); err != nil {
+			return fmt.Errorf("failed to scan answer aggregate: %w", err)
+		}
+		start, err := time.ParseInLocation(sqliteTimeFormat, bucket, time.UTC)
+		if err != nil {
+			return fmt.Errorf("failed to parse interval %q: %w", bucket, err)
+		}
+		a.addAnswers(start, questionID, value, count)
+	}
+	return rows.Err()
+}
+
+// analytics accumulates aggregates into a RoomAnalytics
+type analytics struct {
+	result    *models.RoomAnalytics
+	index     map[time.Time]int // Series position of each interval start
+	scoreSum  []float64         // Per interval, then overall in the last element
+	scored    []int
+	questions map[int]int // Questions position of each NPS and rating question
+}
+
+// newAnalytics prepares an empty series covering the period of q, with NPS and
+// CSAT scores when questions include NPS or rating questions
+func newAnalytics(q models.AnalyticsQuery, questions []models.Question) *analytics {
+	a := &analytics{
+		result: &models.RoomAnalytics{
+			Interval:    q.Interval,
+			From:        models.IntervalStart(q.From, q.Interval),
+			To:          q.To.UTC(),
+			Series:      []models.AnalyticsBucket{},
+			PeakWindows: []models.SubmissionWindow{},
+		},
+		index: make(map[time.Time]int),
+	}
+	for start := a.result.From; start.Before(a.result.To); start = models.NextInterval(start, q.Interval) {
+		a.index[start] = len(a.result.Series)
+		a.result.Series = append(a.result.Series, models.AnalyticsBucket{Start: start})
+	}
+	a.scoreSum = make([]float64, len(a.result.Series)+1)
+	a.scored = make([]int, len(a.result.Series)+1)
+
+	a.questions = make(map[int]int)
+	for _, question := range questions {
+		score := models.QuestionScore{QuestionID: question.ID, Prompt: question.Prompt, Type: question.Type}
+		switch question.Type {
+		case models.QuestionNPS:
+			score.NPS = &models.NPSScore{}
+			a.result.NPS = &models.NPSScore{}
+			for i := range a.result.Series {
+				a.result.Series[i].NPS = &models.NPSScore{}
+			}
+		case models.QuestionRating:
+			score.CSAT = &models.CSATScore{}
+			a.result.CSAT = &models.CSATScore{}
+			for i := range a.result.Series {
+				a.result.Series[i].CSAT = &models.CSATScore{}
+			}
+		default:
+			continue
+		}
+		a.questions[question.ID] = len(a.result.Questions)
+		a.result.Questions = append(a.result.Questions, score)
+	}
+	return a
+}
+
+// add records count entries with a sentiment label in the interval starting at
+// start, scored of which have scores adding up to scoreSum
+func (a *analytics) add(start time.Time, label string, count int, scoreSum float64, scored int) {
+	i, ok := a.index[start]
+	if !ok {
+		return
+	}
+	bucket := &a.result.Series[i]
+	bucket.Count += count
+	addSentiment(&bucket.Sentiment, label, count)
+	a.result.Total += count
+	addSentiment(&a.result.Sentiment, label, count)
+
+	overall := len(a.scoreSum) - 1
+	a.scoreSum[i] += scoreSum
+	a.scored[i] += scored
+	a.scoreSum[overall] += scoreSum
+	a.scored[overall] += scored
+}
+
+// addAnswers records count answers of value to an NPS or rating question in
+// the interval starting at start
+func (a *analytics) addAnswers(start time.Time, questionID, value, count int) {
+	i, ok := a.index[start]
+	q, scored := a.questions[questionID]
+	if !ok || !scored {
+		return
+	}
+	bucket, question := &a.result.Series[i], &a.result.Questions[q]
+	if question.NPS != nil {
+		question.NPS.Add(value, count)
+		bucket.NPS.Add(value, count)
+		a.result.NPS.Add(value, count)
+	} else {
+		question.CSAT.Add(value, count)
+		bucket.CSAT.Add(value, count)
+		a.result.CSAT.Add(value, count)
+	}
+}
+
+// finish computes the average scores
+func (a *analytics) finish() *models.RoomAnalytics {
+	for i := range a.result.Series {
+		a.result.Series[i].AverageScore = average(a.scoreSum[i], a.scored[i])
+	}
+	overall := len(a.scoreSum) - 1
+	a.result.AverageScore = average(a.scoreSum[overall], a.scored[overall])
+	return a.result
+}
+
+// addSentiment adds n entries to the count of a sentiment label
+func addSentiment(counts *models.SentimentCounts, label string, n int) {
+	switch label {
+	case "positive":
+		counts.Positive += n
+	case "neutral":
+		counts.Neutral += n
+	case "negative":
+		counts.Negative += n
+	default:
+		counts.Pending += n
+	}
+}
+
+// average returns sum/n rounded to three decimals, or nil if n is zero
+func average(sum float64, n int) *float64 {
+	if n == 0 {
+		return nil
+	}
+	avg := math.Round(sum/float64(n)*1000) / 1000
+	return &avg
+}
+
+// peakWindows orders per hour-of-week counts busiest first and keeps the top
+// peakWindowCount, in the order of the SQL query; used by the memory store
+func peakWindows(counts map[[2]int]int) []models.SubmissionWindow {
+	keys := make([][2]int, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	if len(keys) > peakWindowCount {
+		keys = keys[:peakWindowCount]
+	}
+
+	windows := []models.SubmissionWindow{}
+	for _, key := range keys {
+		windows = append(windows, models.SubmissionWindow{
+			Weekday: time.Weekday(key[0]).String(),
+			Hour:    key[1],
+			Count:   counts[key],
+		})
+	}
+	return windows
+}
@@ -0,0 +1,268 @@
+package db
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// importTestFeedback imports new, untagged feedback entries into a room
+func importTestFeedback(t *testing.T, store Store, roomID string, entries []models.FeedbackImport) {
+	t.Helper()
+
+	for i := range entries {
+		entries[i].Status = models.FeedbackStatusNew
+		entries[i].Tags = []string{}
+	}
+	if _, err := store.ImportFeedback(roomID, entries, false); err != nil {
+		t.Fatalf("ImportFeedback() error: %v", err)
+	}
+}
+
+func TestGetRoomAnalytics(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	score := func(s float64) *float64 { return &s }
+	// 2025-03-03 is a Monday
+	entries := []models.FeedbackImport{
+		{Content: "before the period", Sentiment: "negative", SentimentScore: score(-1), CreatedAt: at(2, 23, 59)},
+		{Content: "a", Sentiment: "positive", SentimentScore: score(0.8), CreatedAt: at(3, 9, 15)},
+		{Content: "b", Sentiment: "negative", SentimentScore: score(-0.4), CreatedAt: at(3, 9, 45)},
+		{Content: "c", Sentiment: "pending", CreatedAt: at(5, 14, 0)},
+		{Content: "d", Sentiment: "neutral", SentimentScore: score(0.2), CreatedAt: at(9, 23, 59)},
+		{Content: "e", Sentiment: "positive", SentimentScore: score(0.6), CreatedAt: at(10, 0, 0)},
+	}
+
+	tests := []struct {
+		name          string
+		empty         bool
+		query         models.AnalyticsQuery
+		wantStarts    []time.Time
+		wantCounts    []int
+		wantSentiment models.SentimentCounts
+		wantAverage   *float64
+		wantPeak      *models.SubmissionWindow
+	}{
+		{
+			name:          "days",
+			query:         models.AnalyticsQuery{Interval: models.IntervalDay, From: at(3, 12, 0), To: at(10, 0, 0)},
+			wantStarts:    []time.Time{at(3, 0, 0), at(4, 0, 0), at(5, 0, 0), at(6, 0, 0), at(7, 0, 0), at(8, 0, 0), at(9, 0, 0)},
+			wantCounts:    []int{2, 0, 1, 0, 0, 0, 1},
+			wantSentiment: models.SentimentCounts{Positive: 1, Neutral: 1, Negative: 1, Pending: 1},
+			wantAverage:   score(0.2),
+			wantPeak:      &models.SubmissionWindow{Weekday: "Monday", Hour: 9, Count: 2},
+		},
+		{
+			name:          "weeks start on Monday",
+			query:         models.AnalyticsQuery{Interval: models.IntervalWeek, From: at(5, 0, 0), To: at(17, 0, 0)},
+			wantStarts:    []time.Time{at(3, 0, 0), at(10, 0, 0)},
+			wantCounts:    []int{4, 1},
+			wantSentiment: models.SentimentCounts{Positive: 2, Neutral: 1, Negative: 1, Pending: 1},
+			wantAverage:   score(0.3),
+			wantPeak:      &models.SubmissionWindow{Weekday: "Monday", Hour: 9, Count: 2},
+		},
+		{
+			name:          "hours",
+			query:         models.AnalyticsQuery{Interval: models.IntervalHour, From: at(3, 9, 30), To: at(3, 11, 0)},
+			wantStarts:    []time.Time{at(3, 9, 0), at(3, 10, 0)},
+			wantCounts:    []int{2, 0},
+			wantSentiment: models.SentimentCounts{Positive: 1, Negative: 1},
+			wantAverage:   score(0.2),
+			wantPeak:      &models.SubmissionWindow{Weekday: "Monday", Hour: 9, Count: 2},
+		},
+		{
+			name:       "empty room",
+			empty:      true,
+			query:      models.AnalyticsQuery{Interval: models.IntervalDay, From: at(3, 0, 0), To: at(5, 0, 0)},
+			wantStarts: []time.Time{at(3, 0, 0), at(4, 0, 0)},
+			wantCounts: []int{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachStore(t, func(t *testing.T, store Store) {
+				room := createTestRoom(t, store, &models.Room{ID: "ANALYTICS"})
+				if !tt.empty {
+					importTestFeedback(t, store, room.ID, entries)
+				}
+
+				got, err := store.GetRoomAnalytics(room.ID, tt.query)
+				if err != nil {
+					t.Fatalf("GetRoomAnalytics() error: %v", err)
+				}
+
+				starts, counts := []time.Time{}, []int{}
+				total := 0
+				for _, bucket := range got.Series {
+					starts = append(starts, bucket.Start)
+					counts = append(counts, bucket.Count)
+					total += bucket.Count
+				}
+				if !reflect.DeepEqual(starts, tt.wantStarts) {
+					t.Errorf("bucket starts = %v, want %v", starts, tt.wantStarts)
+				}
+				if !reflect.DeepEqual(counts, tt.wantCounts) {
+					t.Errorf("bucket counts = %v, want %v", counts, tt.wantCounts)
+				}
+				if got.Total != total {
+					t.Errorf("total = %d, want %d", got.Total, total)
+				}
+				if got.Sentiment != tt.wantSentiment {
+					t.Errorf("sentiment = %+v, want %+v", got.Sentiment, tt.wantSentiment)
+				}
+				if !reflect.DeepEqual(got.AverageScore, tt.wantAverage) {
+					t.Errorf("average score = %v, want %v", got.AverageScore, tt.wantAverage)
+				}
+				if got.NPS != nil || got.CSAT != nil || len(got.Questions) != 0 {
+					t.Errorf("scores = %+v, %+v, %+v, want none without survey questions", got.NPS, got.CSAT, got.Questions)
+				}
+
+				if tt.wantPeak == nil {
+					if len(got.PeakWindows) != 0 {
+						t.Errorf("peak windows = %+v, want none", got.PeakWindows)
+					}
+				} else if len(got.PeakWindows) == 0 || got.PeakWindows[0] != *tt.wantPeak {
+					t.Errorf("peak windows = %+v, want %+v first", got.PeakWindows, *tt.wantPeak)
+				}
+			})
+		})
+	}
+}
+
+func TestGetRoomAnalyticsBucketSentiment(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		room := createTestRoom(t, store, &models.Room{ID: "BUCKETS"})
+		positive, negative := 0.9, -0.5
+		monday := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+		entries := []models.FeedbackImport{
+			{Content: "a", Sentiment: "positive", SentimentScore: &positive, CreatedAt: monday.Add(10 * time.Hour)},
+			{Content: "b", Sentiment: "pending", CreatedAt: monday.Add(11 * time.Hour)},
+			{Content: "c", Sentiment: "negative", SentimentScore: &negative, CreatedAt: monday.AddDate(0, 0, 2)},
+		}
+		importTestFeedback(t, store, room.ID, entries)
+
+		got, err := store.GetRoomAnalytics(room.ID, models.AnalyticsQuery{
+			Interval: models.IntervalDay, From: monday, To: monday.AddDate(0, 0, 3),
+		})
+		if err != nil {
+			t.Fatalf("GetRoomAnalytics() error: %v", err)
+		}
+		if len(got.Series) != 3 {
+			t.Fatalf("series has %d buckets, want 3", len(got.Series))
+		}
+
+		want := []struct {
+			sentiment models.SentimentCounts
+			average   *float64
+		}{
+			{models.SentimentCounts{Positive: 1, Pending: 1}, &positive},
+			{models.SentimentCounts{}, nil},
+			{models.SentimentCounts{Negative: 1}, &negative},
+		}
+		for i, w := range want {
+			bucket := got.Series[i]
+			if bucket.Sentiment != w.sentiment || !reflect.DeepEqual(bucket.AverageScore, w.average) {
+				t.Errorf("bucket %s = %+v with average %v, want %+v with average %v",
+					bucket.Start.Format(time.DateOnly), bucket.Sentiment, bucket.AverageScore, w.sentiment, w.average)
+			}
+		}
+	})
+}
+
+func TestGetRoomAnalyticsScores(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		room := createTestRoom(t, store, &models.Room{ID: "SURVEY"})
+		questions, err := store.SetQuestions(room.ID, []models.Question{
+			{Position: 1, Type: models.QuestionNPS, Prompt: "Recommend us?"},
+			{Position: 2, Type: models.QuestionRating, Prompt: "Rate us"},
+			{Position: 3, Type: models.QuestionText, Prompt: "Anything else?"},
+		})
+		if err != nil {
+			t.Fatalf("SetQuestions() error: %v", err)
+		}
+		nps, rating, text := questions[0].ID, questions[1].ID, questions[2].ID
+
+		answer := func(questionID int, value string) models.Answer {
+			return models.Answer{QuestionID: questionID, Value: json.RawMessage(value)}
+		}
+		submissions := [][]models.Answer{
+			{answer(nps, "10"), answer(rating, "5"), answer(text, `"great"`)},
+			{answer(nps, "9"), answer(rating, "4")},
+			{answer(nps, "9"), answer(rating, "3")},
+			{answer(nps, "7"), answer(rating, "1")},
+			{answer(nps, "3")},
+		}
+		for _, answers := range submissions {
+			if err := store.CreateFeedback(&models.Feedback{RoomID: room.ID, Answers: answers}); err != nil {
+				t.Fatalf("CreateFeedback() error: %v", err)
+			}
+		}
+
+		percent := func(p float64) *float64 { return &p }
+		// Promoters (9-10) minus detractors (0-6), as a share of responses
+		wantNPS := models.NPSScore{Responses: 5, Promoters: 3, Passives: 1, Detractors: 1, Score: percent(40)}
+		// Ratings of 4 or 5, as a share of responses
+		wantCSAT := models.CSATScore{Responses: 4, Satisfied: 2, Score: percent(50), AverageRating: percent(3.25)}
+		emptyNPS, emptyCSAT := models.NPSScore{}, models.CSATScore{}
+
+		now := time.Now().UTC()
+		got, err := store.GetRoomAnalytics(room.ID, models.AnalyticsQuery{
+			Interval: models.IntervalDay, From: now.AddDate(0, 0, -1), To: now.Add(time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("GetRoomAnalytics() error: %v", err)
+		}
+		checkNPS := func(name string, got *models.NPSScore, want models.NPSScore) {
+			t.Helper()
+			if got == nil || !reflect.DeepEqual(*got, want) {
+				t.Errorf("%s NPS = %+v, want %+v", name, got, want)
+			}
+		}
+		checkCSAT := func(name string, got *models.CSATScore, want models.CSATScore) {
+			t.Helper()
+			if got == nil || got.Responses != want.Responses || got.Satisfied != want.Satisfied ||
+				!reflect.DeepEqual(got.Score, want.Score) || !reflect.DeepEqual(got.AverageRating, want.AverageRating) {
+				t.Errorf("%s CSAT = %+v, want %+v", name, got, want)
+			}
+		}
+
+		checkNPS("room", got.NPS, wantNPS)
+		checkCSAT("room", got.CSAT, wantCSAT)
+		today := models.IntervalStart(now, models.IntervalDay)
+		for _, bucket := range got.Series {
+			if bucket.Start.Equal(today) {
+				checkNPS("today", bucket.NPS, wantNPS)
+				checkCSAT("today", bucket.CSAT, wantCSAT)
+			} else {
+				checkNPS(bucket.Start.Format(time.DateOnly), bucket.NPS, emptyNPS)
+				checkCSAT(bucket.Start.Format(time.DateOnly), bucket.CSAT, emptyCSAT)
+			}
+		}
+
+		// Text questions are not scored
+		if len(got.Questions) != 2 || got.Questions[0].QuestionID != nps || got.Questions[1].QuestionID != rating ||
+			got.Questions[0].CSAT != nil || got.Questions[1].NPS != nil {
+			t.Fatalf("question scores = %+v, want the NPS and rating questions", got.Questions)
+		}
+		checkNPS("NPS question", got.Questions[0].NPS, wantNPS)
+		checkCSAT("rating question", got.Questions[1].CSAT, wantCSAT)
+
+		// Without responses in the period the scores are present but null
+		empty, err := store.GetRoomAnalytics(room.ID, models.AnalyticsQuery{
+			Interval: models.IntervalWeek, From: now.AddDate(0, 0, -21), To: now.AddDate(0, 0, -14),
+		})
+		if err != nil {
+			t.Fatalf("GetRoomAnalytics() error: %v", err)
+		}
+		checkNPS("empty period", empty.NPS, emptyNPS)
+		checkCSAT("empty period", empty.CSAT, emptyCSAT)
+		if empty.Total != 0 || len(empty.Questions) != 2 {
+			t.Errorf("empty period = %d entries and %d question scores, want 0 and 2", empty.Total, len(empty.Questions))
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// ErrInvalidCursor is returned for pagination cursors that cannot be decoded or
+// were issued for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// feedbackCursor is the position after the last entry of a page: its sort key
+// and ID. It is sent to clients as opaque base64-encoded JSON.
+type feedbackCursor struct {
+	Sort  string     `json:"s"`
+	Time  *time.Time `json:"t,omitempty"`
+	Score *float64   `json:"v,omitempty"`
+	Votes *int       `json:"n,omitempty"`
+	ID    int        `json:"id"`
+}
+
+// encodeFeedbackCursor returns the cursor positioned after f
+func encodeFeedbackCursor(f *models.Feedback, sort string) string {
+	cursor := feedbackCursor{Sort: sort, ID: f.ID}
+	switch sort {
+	case models.FeedbackSortScore:
+		score := float64(pendingScore)
+		if f.SentimentScore != nil {
+			score = *f.SentimentScore
+		}
+		cursor.Score = &score
+	case models.FeedbackSortVotes:
+		votes := f.Votes
+		cursor.Votes = &votes
+	default:
+		createdAt := f.CreatedAt.UTC()
+		cursor.Time = &createdAt
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeFeedbackCursor parses a cursor for the given sort; an empty string
+// means the first page and returns nil
+func decodeFeedbackCursor(value, sort string) (*feedbackCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := &feedbackCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	switch sort {
+	case models.FeedbackSortScore:
+		if cursor.Score == nil {
+			return nil, ErrInvalidCursor
+		}
+	case models.FeedbackSortVotes:
+		if cursor.Votes == nil {
+			return nil, ErrInvalidCursor
+		}
+	default:
+		if cursor.Time == nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return cursor, nil
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestFeedbackCursorRoundTrip(t *testing.T) {
+	score := 0.25
+	f := &models.Feedback{
+		ID:             42,
+		SentimentScore: &score,
+		Votes:          3,
+		CreatedAt:      time.Date(2026, 3, 1, 12, 30, 0, 500, time.FixedZone("CET", 3600)),
+	}
+	pending := &models.Feedback{ID: 7}
+
+	tests := []struct {
+		name  string
+		f     *models.Feedback
+		sort  string
+		check func(c *feedbackCursor) bool
+	}{
+		{"created at", f, models.FeedbackSortCreatedAt, func(c *feedbackCursor) bool {
+			return c.Time != nil && c.Time.Equal(f.CreatedAt) && c.Time.Location() == time.UTC
+		}},
+		{"score", f, models.FeedbackSortScore, func(c *feedbackCursor) bool { return c.Score != nil && *c.Score == score }},
+		{"pending score", pending, models.FeedbackSortScore, func(c *feedbackCursor) bool {
+			return c.Score != nil && *c.Score == pendingScore
+		}},
+		{"votes", f, models.FeedbackSortVotes, func(c *feedbackCursor) bool { return c.Votes != nil && *c.Votes == 3 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := decodeFeedbackCursor(encodeFeedbackCursor(tt.f, tt.sort), tt.sort)
+			if err != nil {
+				t.Fatalf("decodeFeedbackCursor() error: %v", err)
+			}
+			if cursor.ID != tt.f.ID || cursor.Sort != tt.sort || !tt.check(cursor) {
+				t.Errorf("decodeFeedbackCursor() = %+v", cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeFeedbackCursorRejects(t *testing.T) {
+	f := &models.Feedback{ID: 1, CreatedAt: time.Now()}
+
+	if cursor, err := decodeFeedbackCursor("", models.FeedbackSortScore); cursor != nil || err != nil {
+		t.Errorf("empty cursor = %+v, %v, want nil, nil", cursor, err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		sort  string
+	}{
+		{"not base64", "***", models.FeedbackSortCreatedAt},
+		{"not json", "bm90IGpzb24", models.FeedbackSortCreatedAt},
+		{"other sort", encodeFeedbackCursor(f, models.FeedbackSortCreatedAt), models.FeedbackSortVotes},
+		{"missing key", "eyJzIjoic2NvcmUiLCJpZCI6MX0", models.FeedbackSortScore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeFeedbackCursor(tt.value, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeFeedbackCursor(%q) error = %v, want %v", tt.value, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// trendWindow is the length of each of the two periods a sentiment trend compares
+const trendWindow = 7 * 24 * time.Hour
+
+// RecordRoomVisit marks a room's feedback as read by a user up to now
+func (s *sqlStore) RecordRoomVisit(userID int, roomID string) error {
+	_, err := s.exec(
+		`INSERT INTO room_visits (user_id, room_id) VALUES ($1, $2)
+		 ON CONFLICT (user_id, room_id) DO UPDATE SET visited_at = CURRENT_TIMESTAMP`,
+		userID, roomID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record room visit: %w", err)
+	}
+	return nil
+}
+
+// GetDashboard summarises the activity of every room owned by a user with a
+// single aggregate query over their rooms, visits and feedback
+func (s *sqlStore) GetDashboard(userID int, now time.Time) (*models.Dashboard, error) {
+	rooms := qualifyColumns(roomColumns, "r")
+	current, previous := s.timeArg(now.Add(-trendWindow)), s.timeArg(now.Add(-2*trendWindow))
+
+	rows, err := s.query(
+		`SELECT `+rooms+`, v.visited_at,
+			COUNT(f.id),
+			COUNT(CASE WHEN v.visited_at IS NULL OR f.created_at > v.visited_at THEN f.id END),
+			MAX(f.created_at),
+			COUNT(CASE WHEN f.sentiment = 'positive' THEN 1 END),
+			COUNT(CASE WHEN f.sentiment = 'neutral' THEN 1 END),
+			COUNT(CASE WHEN f.sentiment = 'negative' THEN 1 END),
+			COUNT(CASE WHEN f.sentiment = 'pending' THEN 1 END),
+			COALESCE(SUM(CASE WHEN f.created_at >= $2 THEN f.sentiment_score END), 0),
+			COUNT(CASE WHEN f.created_at >= $2 THEN f.sentiment_score END),
+			COALESCE(SUM(CASE WHEN f.created_at >= $3 AND f.created_at < $2 THEN f.sentiment_score END), 0),
+			COUNT(CASE WHEN f.created_at >= $3 AND f.created_at < $2 THEN f.sentiment_score END)
+		 FROM rooms r
+		 LEFT JOIN room_visits v ON v.room_id = r.id AND v.user_id = $1
+		 LEFT JOIN feedback f ON f.room_id = r.id
+		 WHERE r.creator_id = $1
+		 GROUP BY `+rooms+`, v.visited_at
+		 ORDER BY r.created_at DESC, r.id`,
+		userID, current, previous,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dashboard: %w", err)
+	}
+	defer rows.Close()
+
+	d := newDashboard()
+	for rows.Next() {
+		var activity models.RoomActivity
+		var visited sql.NullTime
+		var last aggregateTime
+		var w trendSums
+		room, err := scanRoom(extendedRow{rows, []interface{}{
+			&visited, &activity.FeedbackCount, &activity.UnreadCount, &last,
+			&activity.Sentiment.Positive, &activity.Sentiment.Neutral,
+			&activity.Sentiment.Negative, &activity.Sentiment.Pending,
+			&w.currentSum, &w.currentCount, &w.previousSum, &w.previousCount,
+		}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dashboard row: %w", err)
+		}
+		activity.Room = *room
+		activity.LastVisitedAt = timePtr(visited)
+		activity.LastFeedbackAt = last.Time
+		d.add(activity, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return d.finish(), nil
+}
+
+// trendSums holds the score sums and counts of the two periods of a trend
+type trendSums struct {
+	currentSum    float64
+	currentCount  int
+	previousSum   float64
+	previousCount int
+}
+
+// addScore adds a feedback score created at created to the period it falls in
+func (w *trendSums) addScore(score *float64, created, now time.Time) {
+	switch {
+	case score == nil || created.Before(now.Add(-2*trendWindow)):
+	case created.Before(now.Add(-trendWindow)):
+		w.previousSum += *score
+		w.previousCount++
+	default:
+		w.currentSum += *score
+		w.currentCount++
+	}
+}
+
+// trend computes the averages of both periods
+func (w trendSums) trend() models.SentimentTrend {
+	return models.NewSentimentTrend(average(w.currentSum, w.currentCount), average(w.previousSum, w.previousCount))
+}
+
+// dashboard accumulates room activity into a Dashboard
+type dashboard struct {
+	result *models.Dashboard
+	totals trendSums
+}
+
+func newDashboard() *dashboard {
+	return &dashboard{result: &models.Dashboard{Rooms: []models.RoomActivity{}}}
+}
+
+// add appends a room, whose scores in the trend periods are summed in w
+func (d *dashboard) add(activity models.RoomActivity, w trendSums) {
+	activity.Room.Password = ""
+	activity.Trend = w.trend()
+	d.result.Rooms = append(d.result.Rooms, activity)
+
+	totals := &d.result.Totals
+	totals.Rooms++
+	if activity.Room.Status == models.RoomStatusOpen {
+		totals.OpenRooms++
+	}
+	totals.Feedback += activity.FeedbackCount
+	totals.Unread += activity.UnreadCount
+	if activity.LastFeedbackAt != nil && (totals.LastFeedbackAt == nil || activity.LastFeedbackAt.After(*totals.LastFeedbackAt)) {
+		totals.LastFeedbackAt = activity.LastFeedbackAt
+	}
+	totals.Sentiment.Positive += activity.Sentiment.Positive
+	totals.Sentiment.Neutral += activity.Sentiment.Neutral
+	totals.Sentiment.Negative += activity.Sentiment.Negative
+	totals.Sentiment.Pending += activity.Sentiment.Pending
+
+	d.totals.currentSum += w.currentSum
+	d.totals.currentCount += w.currentCount
+	d.totals.previousSum += w.previousSum
+	d.totals.previousCount += w.previousCount
+}
+
+// finish computes the account-wide trend
+func (d *dashboard) finish() *models.Dashboard {
+	d.result.Totals.Trend = d.totals.trend()
+	return d.result
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestGetDashboard(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		owner, err := store.CreateUser("owner@example.com", "password")
+		if err != nil {
+			t.Fatalf("CreateUser() error: %v", err)
+		}
+		other := createTestRoom(t, store, &models.Room{ID: "OTHER"})
+		for _, id := range []string{"VISITED", "UNVISITED", "EMPTY"} {
+			if err := store.CreateRoom(&models.Room{ID: id, Name: id, CreatorID: owner.ID}); err != nil {
+				t.Fatalf("CreateRoom() error: %v", err)
+			}
+		}
+
+		// Timestamps are stored to the second, so entries are minutes apart from the visit
+		start := time.Now().UTC().Truncate(time.Second)
+		now := start.Add(2 * time.Minute)
+		score := func(s float64) *float64 { return &s }
+		importTestFeedback(t, store, "VISITED", []models.FeedbackImport{
+			{Content: "read", Sentiment: "positive", SentimentScore: score(0.5), CreatedAt: start.Add(-time.Hour)},
+		})
+		importTestFeedback(t, store, "UNVISITED", []models.FeedbackImport{
+			{Content: "last week", Sentiment: "neutral", SentimentScore: score(0.2), CreatedAt: now.AddDate(0, 0, -10)},
+			{Content: "recent", Sentiment: "positive", SentimentScore: score(0.6), CreatedAt: start.Add(-2 * time.Hour)},
+			{Content: "unanalysed", Sentiment: "pending", CreatedAt: start.Add(-3 * time.Hour)},
+		})
+		importTestFeedback(t, store, other.ID, []models.FeedbackImport{
+			{Content: "elsewhere", Sentiment: "pending", CreatedAt: start},
+		})
+		if err := store.RecordRoomVisit(owner.ID, "VISITED"); err != nil {
+			t.Fatalf("RecordRoomVisit() error: %v", err)
+		}
+		// Visits of other users do not mark the owner's feedback as read
+		if err := store.RecordRoomVisit(other.CreatorID, "UNVISITED"); err != nil {
+			t.Fatalf("RecordRoomVisit() error: %v", err)
+		}
+		newFeedback := start.Add(time.Minute)
+		importTestFeedback(t, store, "VISITED", []models.FeedbackImport{
+			{Content: "new", Sentiment: "negative", SentimentScore: score(-0.5), CreatedAt: newFeedback},
+		})
+
+		dashboard, err := store.GetDashboard(owner.ID, now)
+		if err != nil {
+			t.Fatalf("GetDashboard() error: %v", err)
+		}
+
+		rooms := make(map[string]models.RoomActivity)
+		for _, activity := range dashboard.Rooms {
+			rooms[activity.Room.ID] = activity
+		}
+		if len(dashboard.Rooms) != 3 || len(rooms) != 3 {
+			t.Fatalf("dashboard rooms = %+v, want the owner's 3 rooms", dashboard.Rooms)
+		}
+
+		tests := []struct {
+			roomID    string
+			feedback  int
+			unread    int
+			visited   bool
+			lastAt    *time.Time
+			sentiment models.SentimentCounts
+			trend     string
+		}{
+			{"VISITED", 2, 1, true, &newFeedback, models.SentimentCounts{Positive: 1, Negative: 1}, models.TrendUnknown},
+			{"UNVISITED", 3, 3, false, timeAt(start.Add(-2 * time.Hour)),
+				models.SentimentCounts{Positive: 1, Neutral: 1, Pending: 1}, models.TrendUp},
+			{"EMPTY", 0, 0, false, nil, models.SentimentCounts{}, models.TrendUnknown},
+		}
+		for _, tt := range tests {
+			got := rooms[tt.roomID]
+			if got.FeedbackCount != tt.feedback || got.UnreadCount != tt.unread {
+				t.Errorf("%s: %d entries with %d unread, want %d with %d unread",
+					tt.roomID, got.FeedbackCount, got.UnreadCount, tt.feedback, tt.unread)
+			}
+			if (got.LastVisitedAt != nil) != tt.visited {
+				t.Errorf("%s: last visited at %v, want visited %t", tt.roomID, got.LastVisitedAt, tt.visited)
+			}
+			if !equalTimes(got.LastFeedbackAt, tt.lastAt) {
+				t.Errorf("%s: last feedback at %v, want %v", tt.roomID, got.LastFeedbackAt, tt.lastAt)
+			}
+			if got.Sentiment != tt.sentiment {
+				t.Errorf("%s: sentiment = %+v, want %+v", tt.roomID, got.Sentiment, tt.sentiment)
+			}
+			if got.Trend.Direction != tt.trend {
+				t.Errorf("%s: trend = %+v, want %s", tt.roomID, got.Trend, tt.trend)
+			}
+		}
+
+		totals := dashboard.Totals
+		if totals.Rooms != 3 || totals.OpenRooms != 3 || totals.Feedback != 5 || totals.Unread != 4 {
+			t.Errorf("totals = %+v, want 3 open rooms with 5 entries and 4 unread", totals)
+		}
+		if !equalTimes(totals.LastFeedbackAt, &newFeedback) {
+			t.Errorf("last feedback at %v, want %v", totals.LastFeedbackAt, newFeedback)
+		}
+		// The current week averages 0.5, -0.5 and 0.6; the previous one 0.2
+		if totals.Trend.Direction != models.TrendFlat || totals.Trend.Current == nil || *totals.Trend.Current != 0.2 || *totals.Trend.Previous != 0.2 {
+			t.Errorf("trend = %+v, want flat at 0.2", totals.Trend)
+		}
+	})
+}
+
+func TestGetDashboardWithoutRooms(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		user, err := store.CreateUser("owner@example.com", "password")
+		if err != nil {
+			t.Fatalf("CreateUser() error: %v", err)
+		}
+		dashboard, err := store.GetDashboard(user.ID, time.Now())
+		if err != nil {
+			t.Fatalf("GetDashboard() error: %v", err)
+		}
+		if dashboard.Rooms == nil || len(dashboard.Rooms) != 0 || dashboard.Totals.Rooms != 0 ||
+			dashboard.Totals.LastFeedbackAt != nil || dashboard.Totals.Trend.Direction != models.TrendUnknown {
+			t.Errorf("dashboard = %+v, want no rooms", dashboard)
+		}
+	})
+}
+
+// timeAt returns a pointer to t
+func timeAt(t time.Time) *time.Time {
+	return &t
+}
+
+// equalTimes reports whether two optional times are both unset or equal
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
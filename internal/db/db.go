@@ -1,161 +1,213 @@
-package database
+package db
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
-	"regexp"
-	"sort"
-	"strconv"
-	"strings"
 	"time"
 
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
+	"github.com/panaalexandrucristian/feedback-collector/internal/migrate"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
 )
 
-var DB *gorm.DB
+var (
+	// ErrUserNotFound is returned when a user lookup matches no rows
+	ErrUserNotFound = errors.New("user not found")
+	// ErrRoomNotFound is returned when a room lookup matches no rows
+	ErrRoomNotFound = errors.New("room not found")
+	// ErrRoomIDTaken is returned when creating a room whose ID is already in use
+	ErrRoomIDTaken = errors.New("room id already taken")
+	// ErrFeedbackNotFound is returned when a feedback lookup matches no rows
+	ErrFeedbackNotFound = errors.New("feedback not found")
+	// ErrQuestionNotFound is returned when a question to update no longer exists
+	ErrQuestionNotFound = errors.New("question not found")
+	// ErrColumnNotFound is returned when a retro column lookup matches no rows
+	ErrColumnNotFound = errors.New("column not found")
+	// ErrColumnNotEmpty is returned when deleting a retro column that still holds cards
+	ErrColumnNotEmpty = errors.New("column is not empty")
+	// ErrCardGroupNotFound is returned when a card group lookup matches no rows
+	ErrCardGroupNotFound = errors.New("card group not found")
+	// ErrActionItemNotFound is returned when an action item lookup matches no rows
+	ErrActionItemNotFound = errors.New("action item not found")
+	// ErrVoteLimit is returned when a participant may not add another vote
+	ErrVoteLimit = errors.New("vote limit reached")
+	// ErrVoteNotFound is returned when removing a vote that was never cast
+	ErrVoteNotFound = errors.New("vote not found")
+	// ErrReactionNotFound is returned when removing a reaction that was never left
+	ErrReactionNotFound = errors.New("reaction not found")
+	// ErrRefreshTokenNotFound is returned when no refresh token matches the given hash
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+)
 
-// Migration represents a database migration
-type Migration struct {
-	ID          int
-	Name        string
-	Description string
-	FilePath    string
-}
+// Supported storage drivers, selected from the DATABASE_URL scheme
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
 
-// MigrationRecord keeps track of which migrations have been executed
-type MigrationRecord struct {
-	ID          uint   `gorm:"primaryKey"`
-	MigrationID int    `gorm:"uniqueIndex"`
-	Name        string `gorm:"size:255;not null"`
-	Description string `gorm:"size:255"`
-	ExecutedAt  time.Time
+// UserStore persists registered users
+type UserStore interface {
+	CreateUser(email, password string) (*models.User, error)
+	GetUserByEmail(email string) (*models.User, error)
+	GetUserByID(id int) (*models.User, error)
 }
 
-func Init() {
-	var err error
-	DB, err = gorm.Open(sqlite.Open("feature_collector.db"), &gorm.Config{})
-	if err != nil {
-		panic("failed to connect database")
-	}
-
-	// Create the migrations table if it doesn't exist
-	DB.AutoMigrate(&MigrationRecord{})
-
-	// Run migrations
-	runSQLMigrations()
+// RoomStore persists feedback rooms
+type RoomStore interface {
+	CreateRoom(room *models.Room) error
+	GetRoomByID(id string) (*models.Room, error)
+	GetRoomsByUserID(userID int) ([]models.Room, error)
+	UpdateRoom(room *models.Room) error
+	DeleteRoom(id string) error
+	GetRoomsDueForTransition(now time.Time) ([]models.Room, error)
+	TransitionRoomStatus(id string, from, to models.RoomStatus) (bool, error)
 }
 
-// runSQLMigrations runs SQL migrations from the migrations directory
-func runSQLMigrations() {
-	fmt.Println("Running SQL migrations...")
+// QuestionStore persists the questions of survey rooms
+type QuestionStore interface {
+	GetQuestions(roomID string) ([]models.Question, error)
+	SetQuestions(roomID string, questions []models.Question) ([]models.Question, error)
+	GetQuestionUsage(roomID string) (map[int]models.QuestionUsage, error)
+}
 
-	// Get list of applied migrations
-	var appliedMigrations []MigrationRecord
-	if err := DB.Order("migration_id").Find(&appliedMigrations).Error; err != nil {
-		panic(fmt.Errorf("failed to fetch applied migrations: %w", err))
-	}
+// RetroStore persists the boards of retro rooms: columns, phase, card groups
+// and action items
+type RetroStore interface {
+	GetColumns(roomID string) ([]models.RetroColumn, error)
+	SetColumns(roomID string, columns []models.RetroColumn) ([]models.RetroColumn, error)
+	SetRetroPhase(roomID string, phase models.RetroPhase, votesPerParticipant int) error
+	GetCardGroups(roomID string) ([]models.CardGroup, error)
+	SaveCardGroup(group *models.CardGroup) error
+	DeleteCardGroup(roomID string, id int) error
+	GetActionItems(roomID string) ([]models.ActionItem, error)
+	GetActionItem(roomID string, id int) (*models.ActionItem, error)
+	CreateActionItem(item *models.ActionItem) error
+	UpdateActionItem(item *models.ActionItem) error
+	DeleteActionItem(roomID string, id int) error
+}
 
-	appliedIDs := make(map[int]bool)
-	for _, migration := range appliedMigrations {
-		appliedIDs[migration.MigrationID] = true
-	}
+// VoteStore persists the votes of room participants on feedback entries
+type VoteStore interface {
+	AddVote(feedbackID int, participantID string, limits models.VoteLimits) (int, error)
+	RemoveVote(feedbackID int, participantID string) (int, error)
+	GetParticipantVotes(roomID, participantID string) (map[int]int, error)
+}
 
-	// Scan migration files
-	migrations := scanMigrationFiles()
-
-	// Sort migrations by ID
-	sort.Slice(migrations, func(i, j int) bool {
-		return migrations[i].ID < migrations[j].ID
-	})
-
-	// Run pending migrations
-	for _, migration := range migrations {
-		if !appliedIDs[migration.ID] {
-			fmt.Printf("Applying migration %d: %s\n", migration.ID, migration.Name)
-
-			// Read SQL content from file
-			sql, err := ioutil.ReadFile(migration.FilePath)
-			if err != nil {
-				panic(fmt.Errorf("failed to read migration file %s: %w", migration.FilePath, err))
-			}
-
-			// Begin transaction
-			tx := DB.Begin()
-
-			// Execute migration
-			if err := tx.Exec(string(sql)).Error; err != nil {
-				tx.Rollback()
-				panic(fmt.Errorf("migration %d failed: %w", migration.ID, err))
-			}
-
-			// Record migration
-			if err := tx.Create(&MigrationRecord{
-				MigrationID: migration.ID,
-				Name:        migration.Name,
-				Description: migration.Description,
-				ExecutedAt:  time.Now(),
-			}).Error; err != nil {
-				tx.Rollback()
-				panic(fmt.Errorf("failed to record migration %d: %w", migration.ID, err))
-			}
-
-			// Commit transaction
-			tx.Commit()
-
-			fmt.Printf("Migration %d completed successfully\n", migration.ID)
-		} else {
-			fmt.Printf("Migration %d already applied\n", migration.ID)
-		}
-	}
+// ReactionStore persists the reactions of room participants to feedback
+// entries; their counts are loaded with the entries
+type ReactionStore interface {
+	AddReaction(feedbackID int, participantID, reaction string) (bool, error)
+	RemoveReaction(feedbackID int, participantID, reaction string) error
+	GetParticipantReactions(roomID, participantID string) (map[int][]string, error)
 }
 
-// scanMigrationFiles scans for migration files in the migrations directory
-func scanMigrationFiles() []Migration {
-	migrationsDir := "migrations"
-	files, err := os.ReadDir(migrationsDir)
-	if err != nil {
-		panic(fmt.Errorf("failed to read migrations directory: %w", err))
-	}
+// FeedbackStore persists feedback entries
+type FeedbackStore interface {
+	CreateFeedback(f *models.Feedback) error
+	GetFeedbackByID(id int) (*models.Feedback, error)
+	GetFeedbackByRoomID(roomID string) ([]models.Feedback, error)
+	GetFeedbackAfter(roomID string, afterID, limit int) ([]models.Feedback, error)
+	ListFeedback(roomID string, query models.FeedbackQuery) (*models.FeedbackPage, error)
+	UpdateFeedbackStatus(id int, status models.FeedbackStatus) error
+	UpdateQuestion(id int, state models.QAState, pinned bool) error
+	SetFeedbackTags(id int, tags []string) error
+	SearchFeedback(userID int, query string, limit, offset int) (*models.FeedbackSearchPage, error)
+	ImportFeedback(roomID string, entries []models.FeedbackImport, dryRun bool) ([]models.Feedback, error)
+	UpdateFeedbackSentiment(id int, sentiment string, score float64) error
+	GetPendingFeedback(afterID, limit int) ([]models.Feedback, error)
+}
 
-	// Regular expression to match migration files: 001_description.sql
-	re := regexp.MustCompile(`^(\d+)_([a-zA-Z0-9_]+)\.sql$`)
+// AnalyticsStore aggregates feedback activity for reporting
+type AnalyticsStore interface {
+	GetRoomAnalytics(roomID string, q models.AnalyticsQuery) (*models.RoomAnalytics, error)
+	GetDashboard(userID int, now time.Time) (*models.Dashboard, error)
+	RecordRoomVisit(userID int, roomID string) error
+}
 
-	var migrations []Migration
+// TokenStore persists refresh tokens and the access token denylist
+type TokenStore interface {
+	CreateRefreshToken(userID int, tokenHash, familyID string, expiresAt time.Time) (*models.RefreshToken, error)
+	GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error)
+	// RevokeRefreshToken revokes a single token and reports whether it was still active,
+	// so that concurrent rotations of the same token are detected as reuse
+	RevokeRefreshToken(id int) (bool, error)
+	// RecordAccessToken remembers the ID of an access token issued to a refresh token family
+	RecordAccessToken(familyID, jti string, expiresAt time.Time) error
+	// RevokeRefreshTokenFamily revokes the active refresh tokens of a family and
+	// denylists the access tokens issued to it
+	RevokeRefreshTokenFamily(familyID string) error
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	PurgeExpiredTokens() error
+}
 
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
+// JobStore persists the background job queue. Jobs are leased by attempt number:
+// CompleteJob, RetryJob and BuryJob only apply while the job is still running
+// under that attempt.
+type JobStore interface {
+	EnqueueJob(kind string, payload []byte, runAt time.Time, maxAttempts int) (*models.Job, error)
+	LeaseJobs(now time.Time, lease time.Duration, limit int) ([]models.Job, error)
+	CompleteJob(id int64, attempt int) error
+	RetryJob(id int64, attempt int, runAt time.Time, lastError string) error
+	BuryJob(id int64, attempt int, lastError string) error
+	PurgeFinishedJobs(before time.Time) error
+}
 
-		matches := re.FindStringSubmatch(file.Name())
-		if len(matches) != 3 {
-			// Not a migration file, skip
-			continue
-		}
+// Store is the storage backend used by the API handlers
+type Store interface {
+	UserStore
+	RoomStore
+	QuestionStore
+	RetroStore
+	FeedbackStore
+	VoteStore
+	ReactionStore
+	AnalyticsStore
+	TokenStore
+	JobStore
+	Close() error
+}
 
-		id, err := strconv.Atoi(matches[1])
-		if err != nil {
-			fmt.Printf("Warning: Invalid migration ID in file %s\n", file.Name())
-			continue
+// Open returns the Store implementation for the given driver. SQLite databases
+// are refused by binaries built without FTS5, which could only scan them on search.
+func Open(driver, databaseURL string) (Store, error) {
+	switch driver {
+	case DriverPostgres:
+		return NewPostgres(databaseURL)
+	case DriverSQLite:
+		if !sqliteFTS5 {
+			return nil, errors.New("SQLite full-text search needs FTS5: build with `-tags sqlite_fts5` (see the Makefile)")
 		}
-
-		name := matches[2]
-		description := strings.ReplaceAll(name, "_", " ")
-
-		migrations = append(migrations, Migration{
-			ID:          id,
-			Name:        name,
-			Description: description,
-			FilePath:    filepath.Join(migrationsDir, file.Name()),
-		})
+		return NewSQLite(databaseURL)
+	case DriverMemory:
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
 	}
+}
 
-	return migrations
+// Connect opens a raw connection for the SQL drivers, without running migrations.
+// It is used by the migrate command, which manages the schema itself.
+func Connect(driver, databaseURL string) (*sql.DB, error) {
+	switch driver {
+	case DriverPostgres:
+		return connectPostgres(databaseURL)
+	case DriverSQLite:
+		return connectSQLite(databaseURL)
+	default:
+		return nil, fmt.Errorf("driver %q does not use SQL migrations", driver)
+	}
 }
 
-func GetDB() *gorm.DB {
-	return DB
+// migrateUp applies pending migrations when a SQL store is opened
+func migrateUp(conn *sql.DB, driver string) error {
+	migrator, err := migrate.New(conn, driver)
+	if err != nil {
+		return err
+	}
+	if err := migrator.Up(); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// feedbackColumns is the column list read by scanFeedback
+const feedbackColumns = `id, room_id, content, sentiment, sentiment_score, status, column_id, group_id,
+	votes, qa_state, pinned, participant_id, created_at`
+
+// scanFeedback scans a row selected with feedbackColumns. Tags and answers are
+// loaded separately by attachDetails.
+func scanFeedback(row rowScanner) (*models.Feedback, error) {
+	f := &models.Feedback{Tags: []string{}}
+	var score sql.NullFloat64
+	var columnID, groupID sql.NullInt64
+	var qaState, participantID sql.NullString
+	err := row.Scan(
+		&f.ID, &f.RoomID, &f.Content, &f.Sentiment, &score, &f.Status, &columnID, &groupID,
+		&f.Votes, &qaState, &f.Pinned, &participantID, &f.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if score.Valid {
+		f.SentimentScore = &score.Float64
+	}
+	f.ColumnID = intPtr(columnID)
+	f.GroupID = intPtr(groupID)
+	f.QAState = models.QAState(qaState.String)
+	f.ParticipantID = participantID.String
+	return f, nil
+}
+
+// CreateFeedback inserts a feedback entry from the room, content, answers,
+// column, Q&A state and participant of f, with the answers of a survey submission in the
+// same transaction. The stored fields are filled in on success.
+func (s *sqlStore) CreateFeedback(f *models.Feedback) error {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	f.Tags = []string{}
+	err = tx.QueryRow(s.rebind(
+		`INSERT INTO feedback (room_id, content, column_id, qa_state, participant_id)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, sentiment, status, created_at`),
+		f.RoomID, f.Content, nullInt(f.ColumnID), nullString(string(f.QAState)), nullString(f.ParticipantID),
+	).Scan(&f.ID, &f.Sentiment, &f.Status, &f.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert feedback: %w", err)
+	}
+
+	for _, answer := range f.Answers {
+		_, err := tx.Exec(s.rebind(`INSERT INTO answers (feedback_id, question_id, value) VALUES ($1, $2, $3)`),
+			f.ID, answer.QuestionID, string(answer.Value))
+		if err != nil {
+			return fmt.Errorf("failed to insert answer: %w", err)
+		}
+	}
+	if len(f.Answers) == 0 {
+		f.Answers = nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit feedback: %w", err)
+	}
+	return nil
+}
+
+// GetFeedbackByID retrieves a single feedback entry
+func (s *sqlStore) GetFeedbackByID(id int) (*models.Feedback, error) {
+	f, err := scanFeedback(s.queryRow(`SELECT `+feedbackColumns+` FROM feedback WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeedbackNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feedback: %w", err)
+	}
+
+	entries := []models.Feedback{*f}
+	if err := s.attachDetails(entries); err != nil {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// GetFeedbackByRoomID lists all feedback for a room, newest first, with their
+// tags and reaction counts
+func (s *sqlStore) GetFeedbackByRoomID(roomID string) ([]models.Feedback, error) {
+	feedback, err := s.listFeedback(
+		`SELECT `+feedbackColumns+` FROM feedback WHERE room_id = $1
+		 ORDER BY created_at DESC, id DESC`,
+		roomID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachDetails(feedback); err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+// GetFeedbackAfter lists up to limit entries of a room with an ID greater than
+// afterID, in ID order, with their tags; used to resume live streams and to
+// export a room in batches
+func (s *sqlStore) GetFeedbackAfter(roomID string, afterID, limit int) ([]models.Feedback, error) {
+	feedback, err := s.listFeedback(
+		`SELECT `+feedbackColumns+` FROM feedback
+		 WHERE room_id = $1 AND id > $2
+		 ORDER BY id
+		 LIMIT $3`,
+		roomID, afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachDetails(feedback); err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+// UpdateFeedbackSentiment stores the result of sentiment analysis
+func (s *sqlStore) UpdateFeedbackSentiment(id int, sentiment string, score float64) error {
+	result, err := s.exec(
+		`UPDATE feedback SET sentiment = $1, sentiment_score = $2 WHERE id = $3`,
+		sentiment, score, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update feedback sentiment: %w", err)
+	}
+
+	return expectAffected(result, ErrFeedbackNotFound)
+}
+
+// GetPendingFeedback lists up to limit entries still awaiting sentiment analysis
+// with an ID greater than afterID, in ID order
+func (s *sqlStore) GetPendingFeedback(afterID, limit int) ([]models.Feedback, error) {
+	return s.listFeedback(
+		`SELECT `+feedbackColumns+` FROM feedback
+		 WHERE sentiment = 'pending' AND id > $1
+		 ORDER BY id
+		 LIMIT $2`,
+		afterID, limit,
+	)
+}
+
+// listFeedback runs a query selecting feedbackColumns and collects the rows
+func (s *sqlStore) listFeedback(query string, args ...interface{}) ([]models.Feedback, error) {
+	rows, err := s.query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feedback: %w", err)
+	}
+	defer rows.Close()
+
+	feedback := []models.Feedback{}
+	for rows.Next() {
+		f, err := scanFeedback(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		feedback = append(feedback, *f)
+	}
+
+	return feedback, rows.Err()
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// ImportFeedback inserts entries into a room in a single transaction, keeping
+// their timestamps. On a dry run the transaction is rolled back, so constraint
+// violations are still reported but nothing is stored.
+func (s *sqlStore) ImportFeedback(roomID string, entries []models.FeedbackImport, dryRun bool) ([]models.Feedback, error) {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	insertFeedback := s.rebind(
+		`INSERT INTO feedback (room_id, content, sentiment, sentiment_score, status, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id`,
+	)
+	insertTag := s.rebind(`INSERT INTO feedback_tags (feedback_id, tag) VALUES ($1, $2)`)
+
+	imported := make([]models.Feedback, 0, len(entries))
+	for _, entry := range entries {
+		f := models.Feedback{
+			RoomID:         roomID,
+			Content:        entry.Content,
+			Sentiment:      entry.Sentiment,
+			SentimentScore: entry.SentimentScore,
+			Status:         entry.Status,
+			Tags:           append([]string{}, entry.Tags...),
+			CreatedAt:      entry.CreatedAt.UTC(),
+		}
+		err := tx.QueryRow(insertFeedback,
+			roomID, f.Content, f.Sentiment, f.SentimentScore, string(f.Status), s.timeArg(f.CreatedAt),
+		).Scan(&f.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert feedback: %w", err)
+		}
+		for _, tag := range f.Tags {
+			if _, err := tx.Exec(insertTag, f.ID, tag); err != nil {
+				return nil, fmt.Errorf("failed to insert feedback tag: %w", err)
+			}
+		}
+		imported = append(imported, f)
+	}
+
+	if dryRun {
+		return imported, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return imported, nil
+}
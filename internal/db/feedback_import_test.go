@@ -0,0 +1,55 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestImportFeedback(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		room := createTestRoom(t, store, &models.Room{ID: "IMPORT"})
+		score := -0.25
+		createdAt := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+		entries := []models.FeedbackImport{
+			{Content: "first", Status: models.FeedbackStatusArchived, Tags: []string{"bug"},
+				Sentiment: "negative", SentimentScore: &score, CreatedAt: createdAt},
+			{Content: "second", Status: models.FeedbackStatusNew, Tags: []string{}, Sentiment: "pending", CreatedAt: createdAt},
+		}
+
+		count := func() int {
+			page, err := store.ListFeedback(room.ID, models.FeedbackQuery{Sort: models.FeedbackSortCreatedAt, Limit: 10})
+			if err != nil {
+				t.Fatalf("ListFeedback() error: %v", err)
+			}
+			return page.Total
+		}
+
+		imported, err := store.ImportFeedback(room.ID, entries, true)
+		if err != nil || len(imported) != 2 {
+			t.Fatalf("dry run ImportFeedback() = %d entries, %v", len(imported), err)
+		}
+		if n := count(); n != 0 {
+			t.Fatalf("dry run stored %d entries", n)
+		}
+
+		imported, err = store.ImportFeedback(room.ID, entries, false)
+		if err != nil || len(imported) != 2 {
+			t.Fatalf("ImportFeedback() = %d entries, %v", len(imported), err)
+		}
+		if n := count(); n != 2 {
+			t.Fatalf("stored %d entries, want 2", n)
+		}
+
+		stored, err := store.GetFeedbackByID(imported[0].ID)
+		if err != nil {
+			t.Fatalf("GetFeedbackByID() error: %v", err)
+		}
+		if stored.Content != "first" || stored.Status != models.FeedbackStatusArchived || !stored.CreatedAt.Equal(createdAt) ||
+			stored.SentimentScore == nil || *stored.SentimentScore != score || !reflect.DeepEqual(stored.Tags, []string{"bug"}) {
+			t.Errorf("GetFeedbackByID() = %+v, want the imported entry", stored)
+		}
+	})
+}
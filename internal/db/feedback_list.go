@@ -0,0 +1,218 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// pendingScore is the sort key of entries without a sentiment score; it is
+// below every real score so that they come last when sorting by score descending
+const pendingScore = -2
+
+// ListFeedback returns one page of a room's feedback matching the query, using
+// keyset pagination on the sort key and ID
+func (s *sqlStore) ListFeedback(roomID string, q models.FeedbackQuery) (*models.FeedbackPage, error) {
+	cursor, err := decodeFeedbackCursor(q.Cursor, q.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &queryBuilder{}
+	filters := s.feedbackFilters(b, roomID, q)
+
+	page := &models.FeedbackPage{}
+	err = s.queryRow(`SELECT COUNT(*) FROM feedback WHERE `+filters, b.args...).Scan(&page.Total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count feedback: %w", err)
+	}
+
+	key := "created_at"
+	switch q.Sort {
+	case models.FeedbackSortScore:
+		key = "COALESCE(sentiment_score, " + strconv.Itoa(pendingScore) + ")"
+	case models.FeedbackSortVotes:
+		key = "votes"
+	}
+	order, compare := "DESC", "<"
+	if q.Ascending {
+		order, compare = "ASC", ">"
+	}
+
+	where := filters
+	if cursor != nil {
+		var value interface{}
+		switch q.Sort {
+		case models.FeedbackSortScore:
+			value = *cursor.Score
+		case models.FeedbackSortVotes:
+			value = *cursor.Votes
+		default:
+			value = s.timeArg(*cursor.Time)
+		}
+		where += fmt.Sprintf(" AND (%s, id) %s (%s, %s)", key, compare, b.arg(value), b.arg(cursor.ID))
+	}
+
+	// One extra row tells whether there is a next page
+	items, err := s.listFeedback(
+		`SELECT `+feedbackColumns+` FROM feedback
+		 WHERE `+where+`
+		 ORDER BY `+key+` `+order+`, id `+order+`
+		 LIMIT `+b.arg(q.Limit+1),
+		b.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > q.Limit {
+		items = items[:q.Limit]
+		page.NextCursor = encodeFeedbackCursor(&items[len(items)-1], q.Sort)
+	}
+	if err := s.attachDetails(items); err != nil {
+		return nil, err
+	}
+
+	page.Items = items
+	return page, nil
+}
+
+// feedbackFilters builds the WHERE conditions shared by the count and page queries
+func (s *sqlStore) feedbackFilters(b *queryBuilder, roomID string, q models.FeedbackQuery) string {
+	conditions := []string{"room_id = " + b.arg(roomID)}
+	if q.Sentiment != "" {
+		conditions = append(conditions, "sentiment = "+b.arg(q.Sentiment))
+	}
+	if q.Status != "" {
+		conditions = append(conditions, "status = "+b.arg(string(q.Status)))
+	}
+	if q.QAState != "" {
+		conditions = append(conditions, "qa_state = "+b.arg(string(q.QAState)))
+	}
+	if q.Tag != "" {
+		conditions = append(conditions,
+			"EXISTS (SELECT 1 FROM feedback_tags t WHERE t.feedback_id = feedback.id AND t.tag = "+b.arg(q.Tag)+")")
+	}
+	if q.From != nil {
+		conditions = append(conditions, "created_at >= "+b.arg(s.timeArg(*q.From)))
+	}
+	if q.To != nil {
+		conditions = append(conditions, "created_at < "+b.arg(s.timeArg(*q.To)))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// attachDetails loads the tags, survey answers and reaction counts of the given entries
+func (s *sqlStore) attachDetails(feedback []models.Feedback) error {
+	if err := s.attachTags(feedback); err != nil {
+		return err
+	}
+	if err := s.attachAnswers(feedback); err != nil {
+		return err
+	}
+	return s.attachReactions(feedback)
+}
+
+// attachTags loads the tags of the given entries in a single query
+func (s *sqlStore) attachTags(feedback []models.Feedback) error {
+	if len(feedback) == 0 {
+		return nil
+	}
+
+	b := &queryBuilder{}
+	index := make(map[int]int, len(feedback))
+	placeholders := make([]string, len(feedback))
+	for i := range feedback {
+		index[feedback[i].ID] = i
+		placeholders[i] = b.arg(feedback[i].ID)
+		feedback[i].Tags = []string{}
+	}
+
+	rows, err := s.query(
+		`SELECT feedback_id, tag FROM feedback_tags
+		 WHERE feedback_id IN (`+strings.Join(placeholders, ", ")+`)
+		 ORDER BY feedback_id, tag`,
+		b.args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query feedback tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return fmt.Errorf("failed to scan feedback tag: %w", err)
+		}
+		f := &feedback[index[id]]
+		f.Tags = append(f.Tags, tag)
+	}
+
+	return rows.Err()
+}
+
+// UpdateFeedbackStatus changes the triage status of a feedback entry
+func (s *sqlStore) UpdateFeedbackStatus(id int, status models.FeedbackStatus) error {
+	result, err := s.exec(`UPDATE feedback SET status = $1 WHERE id = $2`, string(status), id)
+	if err != nil {
+		return fmt.Errorf("failed to update feedback status: %w", err)
+	}
+
+	return expectAffected(result, ErrFeedbackNotFound)
+}
+
+// UpdateQuestion sets the Q&A state and pin of a question
+func (s *sqlStore) UpdateQuestion(id int, state models.QAState, pinned bool) error {
+	result, err := s.exec(`UPDATE feedback SET qa_state = $1, pinned = $2 WHERE id = $3`, string(state), pinned, id)
+	if err != nil {
+		return fmt.Errorf("failed to update question: %w", err)
+	}
+
+	return expectAffected(result, ErrFeedbackNotFound)
+}
+
+// SetFeedbackTags replaces the tags of a feedback entry
+func (s *sqlStore) SetFeedbackTags(id int, tags []string) error {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow(s.rebind(`SELECT EXISTS (SELECT 1 FROM feedback WHERE id = $1)`), id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to query feedback: %w", err)
+	}
+	if !exists {
+		return ErrFeedbackNotFound
+	}
+
+	if _, err := tx.Exec(s.rebind(`DELETE FROM feedback_tags WHERE feedback_id = $1`), id); err != nil {
+		return fmt.Errorf("failed to clear feedback tags: %w", err)
+	}
+	for _, tag := range tags {
+		_, err := tx.Exec(s.rebind(`INSERT INTO feedback_tags (feedback_id, tag) VALUES ($1, $2)`), id, tag)
+		if err != nil {
+			return fmt.Errorf("failed to insert feedback tag: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit feedback tags: %w", err)
+	}
+	return nil
+}
+
+// queryBuilder numbers the arguments of a dynamically built query
+type queryBuilder struct {
+	args []interface{}
+}
+
+// arg adds an argument and returns its $N placeholder
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestListFeedbackPagesInOrder(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		room := createTestRoom(t, store, &models.Room{ID: "LIST01"})
+		feedback := createTestFeedback(t, store, room.ID, "a", "b", "c", "d", "e", "f")
+
+		// c and f stay pending and must sort below e, whose score is the lowest possible
+		scores := map[string]float64{"a": 0.5, "b": -0.5, "d": 0.5, "e": -1}
+		byContent := make(map[int]string, len(feedback))
+		for _, f := range feedback {
+			byContent[f.ID] = f.Content
+			if score, ok := scores[f.Content]; ok {
+				if err := store.UpdateFeedbackSentiment(f.ID, "neutral", score); err != nil {
+					t.Fatalf("UpdateFeedbackSentiment() error: %v", err)
+				}
+			}
+		}
+
+		tests := []struct {
+			name string
+			q    models.FeedbackQuery
+			want []string
+		}{
+			{"newest first", models.FeedbackQuery{}, []string{"f", "e", "d", "c", "b", "a"}},
+			{"oldest first", models.FeedbackQuery{Ascending: true}, []string{"a", "b", "c", "d", "e", "f"}},
+			{"score descending", models.FeedbackQuery{Sort: models.FeedbackSortScore},
+				[]string{"d", "a", "b", "e", "f", "c"}},
+			{"score ascending", models.FeedbackQuery{Sort: models.FeedbackSortScore, Ascending: true},
+				[]string{"c", "f", "e", "b", "a", "d"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				q := tt.q
+				if q.Sort == "" {
+					q.Sort = models.FeedbackSortCreatedAt
+				}
+				q.Limit = 4
+
+				got := []string{}
+				for pages := 0; pages < len(tt.want); pages++ {
+					page, err := store.ListFeedback(room.ID, q)
+					if err != nil {
+						t.Fatalf("ListFeedback() error: %v", err)
+					}
+					if page.Total != len(tt.want) {
+						t.Errorf("Total = %d, want %d", page.Total, len(tt.want))
+					}
+					for _, f := range page.Items {
+						got = append(got, byContent[f.ID])
+					}
+					if page.NextCursor == "" {
+						break
+					}
+					q.Cursor = page.NextCursor
+					q.Limit = 1
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("pages = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestListFeedbackFilters(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		room := createTestRoom(t, store, &models.Room{ID: "LIST02"})
+		feedback := createTestFeedback(t, store, room.ID, "tagged", "reviewed", "plain")
+		if err := store.SetFeedbackTags(feedback[0].ID, []string{"bug"}); err != nil {
+			t.Fatalf("SetFeedbackTags() error: %v", err)
+		}
+		if err := store.UpdateFeedbackStatus(feedback[1].ID, models.FeedbackStatusReviewed); err != nil {
+			t.Fatalf("UpdateFeedbackStatus() error: %v", err)
+		}
+
+		tests := []struct {
+			name string
+			q    models.FeedbackQuery
+			want int
+		}{
+			{"no filter", models.FeedbackQuery{}, 3},
+			{"tag", models.FeedbackQuery{Tag: "bug"}, 1},
+			{"status", models.FeedbackQuery{Status: models.FeedbackStatusNew}, 2},
+			{"no match", models.FeedbackQuery{Tag: "bug", Status: models.FeedbackStatusReviewed}, 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				q := tt.q
+				q.Sort, q.Limit = models.FeedbackSortCreatedAt, 10
+				page, err := store.ListFeedback(room.ID, q)
+				if err != nil {
+					t.Fatalf("ListFeedback() error: %v", err)
+				}
+				if page.Total != tt.want || len(page.Items) != tt.want || page.NextCursor != "" {
+					t.Errorf("ListFeedback() = %d of %d, cursor %q, want %d", len(page.Items), page.Total, page.NextCursor, tt.want)
+				}
+			})
+		}
+	})
+}
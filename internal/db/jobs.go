@@ -0,0 +1,128 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// jobColumns is the column list read by scanJob
+const jobColumns = `id, kind, payload, status, attempts, max_attempts, run_at, locked_until, last_error, created_at`
+
+// scanJob scans a row selected with jobColumns
+func scanJob(row rowScanner) (*models.Job, error) {
+	job := &models.Job{}
+	var payload []byte
+	var lockedUntil sql.NullTime
+	var lastError sql.NullString
+	err := row.Scan(&job.ID, &job.Kind, &payload, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&job.RunAt, &lockedUntil, &lastError, &job.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Payload = payload
+	job.LockedUntil = timePtr(lockedUntil)
+	job.LastError = lastError.String
+	return job, nil
+}
+
+// EnqueueJob inserts a queued job that becomes ready at runAt
+func (s *sqlStore) EnqueueJob(kind string, payload []byte, runAt time.Time, maxAttempts int) (*models.Job, error) {
+	job, err := scanJob(s.queryRow(
+		`INSERT INTO jobs (kind, payload, run_at, max_attempts, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $5)
+		 RETURNING `+jobColumns,
+		kind, string(payload), runAt.UTC(), maxAttempts, time.Now().UTC(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert job: %w", err)
+	}
+	return job, nil
+}
+
+// LeaseJobs claims up to limit ready jobs until now+lease and increments their
+// attempt counter. Running jobs whose lease has expired are claimed again, so a
+// job held by a crashed worker is retried. On PostgreSQL concurrent workers skip
+// each other's rows; SQLite serialises the statement on its single writer.
+func (s *sqlStore) LeaseJobs(now time.Time, lease time.Duration, limit int) ([]models.Job, error) {
+	lock := ""
+	if s.driver == DriverPostgres {
+		lock = " FOR UPDATE SKIP LOCKED"
+	}
+
+	now = now.UTC()
+	rows, err := s.query(
+		`UPDATE jobs
+		 SET status = 'running', attempts = attempts + 1, locked_until = $1, updated_at = $2
+		 WHERE id IN (
+			SELECT id FROM jobs
+			WHERE (status = 'queued' AND run_at <= $2)
+			   OR (status = 'running' AND locked_until < $2)
+			ORDER BY run_at, id
+			LIMIT $3`+lock+`
+		 )
+		 RETURNING `+jobColumns,
+		now.Add(lease), now, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// CompleteJob marks a leased job as done. The attempt number identifies the
+// lease, so a worker whose lease expired cannot overwrite a newer attempt.
+func (s *sqlStore) CompleteJob(id int64, attempt int) error {
+	return s.finishJob(
+		`UPDATE jobs SET status = 'done', locked_until = NULL, last_error = NULL, updated_at = $1
+		 WHERE id = $2 AND attempts = $3 AND status = 'running'`,
+		time.Now().UTC(), id, attempt,
+	)
+}
+
+// RetryJob returns a leased job to the queue to run again at runAt
+func (s *sqlStore) RetryJob(id int64, attempt int, runAt time.Time, lastError string) error {
+	return s.finishJob(
+		`UPDATE jobs SET status = 'queued', run_at = $1, locked_until = NULL, last_error = $2, updated_at = $3
+		 WHERE id = $4 AND attempts = $5 AND status = 'running'`,
+		runAt.UTC(), lastError, time.Now().UTC(), id, attempt,
+	)
+}
+
+// BuryJob moves a leased job to the dead-letter state; it is kept for inspection
+func (s *sqlStore) BuryJob(id int64, attempt int, lastError string) error {
+	return s.finishJob(
+		`UPDATE jobs SET status = 'dead', locked_until = NULL, last_error = $1, updated_at = $2
+		 WHERE id = $3 AND attempts = $4 AND status = 'running'`,
+		lastError, time.Now().UTC(), id, attempt,
+	)
+}
+
+// PurgeFinishedJobs deletes completed jobs last updated before the given time
+func (s *sqlStore) PurgeFinishedJobs(before time.Time) error {
+	if _, err := s.exec(`DELETE FROM jobs WHERE status = 'done' AND updated_at < $1`, before.UTC()); err != nil {
+		return fmt.Errorf("failed to purge finished jobs: %w", err)
+	}
+	return nil
+}
+
+// finishJob runs a state change for a leased job; a lost lease is not an error
+func (s *sqlStore) finishJob(query string, args ...interface{}) error {
+	if _, err := s.exec(query, args...); err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	return nil
+}
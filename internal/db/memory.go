@@ -0,0 +1,403 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// memoryStore is an in-process Store used for tests and throwaway instances
+type memoryStore struct {
+	mu             sync.RWMutex
+	users          map[int]models.User
+	rooms          map[string]models.Room
+	feedback       map[int]models.Feedback
+	refreshTokens  map[int]models.RefreshToken
+	revokedTokens  map[string]time.Time
+	issuedTokens   map[string]issuedToken
+	jobs           map[int64]models.Job
+	roomVisits     map[roomVisitKey]time.Time
+	questions      map[string][]models.Question
+	retroColumns   map[string][]models.RetroColumn
+	cardGroups     map[int]models.CardGroup
+	votes          map[voteKey]int
+	reactions      map[reactionKey]time.Time
+	actionItems    map[int]models.ActionItem
+	nextUserID     int
+	nextFeedbackID int
+	nextTokenID    int
+	nextJobID      int64
+	nextQuestionID int
+	nextColumnID   int
+	nextGroupID    int
+	nextActionID   int
+}
+
+// NewMemory returns an empty in-memory Store
+func NewMemory() Store {
+	return &memoryStore{
+		users:          make(map[int]models.User),
+		rooms:          make(map[string]models.Room),
+		feedback:       make(map[int]models.Feedback),
+		refreshTokens:  make(map[int]models.RefreshToken),
+		revokedTokens:  make(map[string]time.Time),
+		issuedTokens:   make(map[string]issuedToken),
+		jobs:           make(map[int64]models.Job),
+		roomVisits:     make(map[roomVisitKey]time.Time),
+		questions:      make(map[string][]models.Question),
+		retroColumns:   make(map[string][]models.RetroColumn),
+		cardGroups:     make(map[int]models.CardGroup),
+		votes:          make(map[voteKey]int),
+		reactions:      make(map[reactionKey]time.Time),
+		actionItems:    make(map[int]models.ActionItem),
+		nextUserID:     1,
+		nextFeedbackID: 1,
+		nextTokenID:    1,
+		nextQuestionID: 1,
+		nextColumnID:   1,
+		nextGroupID:    1,
+		nextActionID:   1,
+		nextJobID:      1,
+	}
+}
+
+// Close is a no-op for the in-memory store
+func (m *memoryStore) Close() error {
+	return nil
+}
+
+// CreateUser hashes the password and stores a new user
+func (m *memoryStore) CreateUser(email, password string) (*models.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	email = strings.ToLower(email)
+	for _, u := range m.users {
+		if u.Email == email {
+			return nil, fmt.Errorf("failed to insert user: email %q already exists", email)
+		}
+	}
+
+	user := models.User{
+		ID:               m.nextUserID,
+		Email:            email,
+		PasswordHash:     string(hash),
+		SubscriptionType: "free",
+		CreatedAt:        time.Now().UTC(),
+	}
+	m.users[user.ID] = user
+	m.nextUserID++
+
+	user.PasswordHash = ""
+	return &user, nil
+}
+
+// GetUserByEmail retrieves a user, including the password hash, by email
+func (m *memoryStore) GetUserByEmail(email string) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	email = strings.ToLower(email)
+	for _, u := range m.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetUserByID retrieves a user by ID
+func (m *memoryStore) GetUserByID(id int) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &u, nil
+}
+
+// CreateRoom stores a new room; room.Password holds the bcrypt hash or is empty for open rooms
+func (m *memoryStore) CreateRoom(room *models.Room) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.rooms[room.ID]; exists {
+		return ErrRoomIDTaken
+	}
+
+	room.IsPasswordProtected = room.Password != ""
+	if room.Status == "" {
+		room.Status = models.RoomStatusOpen
+	}
+	if room.Type == "" {
+		room.Type = models.RoomTypeStandard
+	}
+	room.CreatedAt = time.Now().UTC()
+	stored := *room
+	stored.Reactions = copyReactions(room.Reactions)
+	m.rooms[room.ID] = stored
+
+	return nil
+}
+
+// GetRoomByID retrieves a room, including its password hash, by ID
+func (m *memoryStore) GetRoomByID(id string) (*models.Room, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	room, ok := m.rooms[id]
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+	return &room, nil
+}
+
+// GetRoomsByUserID lists the rooms created by a user, newest first
+func (m *memoryStore) GetRoomsByUserID(userID int) ([]models.Room, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rooms := []models.Room{}
+	for _, room := range m.rooms {
+		if room.CreatorID == userID {
+			room.Password = ""
+			rooms = append(rooms, room)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].CreatedAt.After(rooms[j].CreatedAt)
+	})
+	return rooms, nil
+}
+
+// UpdateRoom writes the mutable fields of a room: name, password, status,
+// reactions and schedule
+func (m *memoryStore) UpdateRoom(room *models.Room) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.rooms[room.ID]
+	if !ok {
+		return ErrRoomNotFound
+	}
+
+	room.IsPasswordProtected = room.Password != ""
+	stored.Name = room.Name
+	stored.Password = room.Password
+	stored.IsPasswordProtected = room.IsPasswordProtected
+	stored.Status = room.Status
+	stored.Reactions = copyReactions(room.Reactions)
+	stored.OpensAt = room.OpensAt
+	stored.ClosesAt = room.ClosesAt
+	m.rooms[room.ID] = stored
+
+	return nil
+}
+
+// GetRoomsDueForTransition lists draft rooms whose opens_at and open rooms whose
+// closes_at has passed
+func (m *memoryStore) GetRoomsDueForTransition(now time.Time) ([]models.Room, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rooms := []models.Room{}
+	for _, room := range m.rooms {
+		due := (room.Status == models.RoomStatusDraft && room.OpensAt != nil && !room.OpensAt.After(now)) ||
+			(room.Status == models.RoomStatusOpen && room.ClosesAt != nil && !room.ClosesAt.After(now))
+		if due {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms, nil
+}
+
+// TransitionRoomStatus moves a room from one status to another if it is still in from
+func (m *memoryStore) TransitionRoomStatus(id string, from, to models.RoomStatus) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	room, ok := m.rooms[id]
+	if !ok || room.Status != from {
+		return false, nil
+	}
+
+	room.Status = to
+	m.rooms[id] = room
+	return true, nil
+}
+
+// DeleteRoom deletes a room together with its feedback
+func (m *memoryStore) DeleteRoom(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.rooms[id]; !ok {
+		return ErrRoomNotFound
+	}
+
+	delete(m.rooms, id)
+	for feedbackID, f := range m.feedback {
+		if f.RoomID == id {
+			delete(m.feedback, feedbackID)
+		}
+	}
+	for key := range m.votes {
+		if _, ok := m.feedback[key.feedbackID]; !ok {
+			delete(m.votes, key)
+		}
+	}
+	for key := range m.reactions {
+		if _, ok := m.feedback[key.feedbackID]; !ok {
+			delete(m.reactions, key)
+		}
+	}
+	for groupID, g := range m.cardGroups {
+		if g.RoomID == id {
+			delete(m.cardGroups, groupID)
+		}
+	}
+	for itemID, item := range m.actionItems {
+		if item.RoomID == id {
+			delete(m.actionItems, itemID)
+		}
+	}
+	delete(m.retroColumns, id)
+	for key := range m.roomVisits {
+		if key.roomID == id {
+			delete(m.roomVisits, key)
+		}
+	}
+	delete(m.questions, id)
+
+	return nil
+}
+
+// CreateFeedback stores a feedback entry for a room with its survey answers
+func (m *memoryStore) CreateFeedback(f *models.Feedback) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.rooms[f.RoomID]; !ok {
+		return ErrRoomNotFound
+	}
+
+	f.ID = m.nextFeedbackID
+	f.Sentiment = "pending"
+	f.Status = models.FeedbackStatusNew
+	f.Tags = []string{}
+	f.CreatedAt = time.Now().UTC()
+	if len(f.Answers) == 0 {
+		f.Answers = nil
+	}
+	stored := *f
+	if f.Answers != nil {
+		stored.Answers = append([]models.Answer{}, f.Answers...)
+	}
+	m.feedback[f.ID] = stored
+	m.nextFeedbackID++
+
+	return nil
+}
+
+// GetFeedbackByID retrieves a single feedback entry
+func (m *memoryStore) GetFeedbackByID(id int) (*models.Feedback, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	f, ok := m.feedback[id]
+	if !ok {
+		return nil, ErrFeedbackNotFound
+	}
+	f.Tags = append([]string{}, f.Tags...)
+	return &f, nil
+}
+
+// GetFeedbackByRoomID lists all feedback for a room, newest first, with their
+// tags and reaction counts
+func (m *memoryStore) GetFeedbackByRoomID(roomID string) ([]models.Feedback, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	feedback := []models.Feedback{}
+	for _, f := range m.feedback {
+		if f.RoomID == roomID {
+			feedback = append(feedback, f)
+		}
+	}
+	sort.Slice(feedback, func(i, j int) bool {
+		return feedback[i].ID > feedback[j].ID
+	})
+	return feedback, nil
+}
+
+// GetFeedbackAfter lists up to limit entries of a room with an ID greater than
+// afterID, in ID order, with their tags
+func (m *memoryStore) GetFeedbackAfter(roomID string, afterID, limit int) ([]models.Feedback, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	feedback := []models.Feedback{}
+	for _, f := range m.feedback {
+		if f.RoomID == roomID && f.ID > afterID {
+			f.Tags = append([]string{}, f.Tags...)
+			feedback = append(feedback, f)
+		}
+	}
+	sort.Slice(feedback, func(i, j int) bool {
+		return feedback[i].ID < feedback[j].ID
+	})
+	if len(feedback) > limit {
+		feedback = feedback[:limit]
+	}
+	return feedback, nil
+}
+
+// UpdateFeedbackSentiment stores the result of sentiment analysis
+func (m *memoryStore) UpdateFeedbackSentiment(id int, sentiment string, score float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.feedback[id]
+	if !ok {
+		return ErrFeedbackNotFound
+	}
+
+	f.Sentiment = sentiment
+	f.SentimentScore = &score
+	m.feedback[id] = f
+	return nil
+}
+
+// GetPendingFeedback lists up to limit entries still awaiting sentiment analysis
+// with an ID greater than afterID, in ID order
+func (m *memoryStore) GetPendingFeedback(afterID, limit int) ([]models.Feedback, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	feedback := []models.Feedback{}
+	for _, f := range m.feedback {
+		if f.Sentiment == "pending" && f.ID > afterID {
+			feedback = append(feedback, f)
+		}
+	}
+	sort.Slice(feedback, func(i, j int) bool {
+		return feedback[i].ID < feedback[j].ID
+	})
+	if len(feedback) > limit {
+		feedback = feedback[:limit]
+	}
+	return feedback, nil
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// GetRoomAnalytics aggregates a room's feedback over a period
+func (m *memoryStore) GetRoomAnalytics(roomID string, q models.AnalyticsQuery) (*models.RoomAnalytics, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	a := newAnalytics(q, m.questions[roomID])
+	windows := make(map[[2]int]int)
+
+	for _, f := range m.feedback {
+		if f.RoomID != roomID || f.CreatedAt.Before(a.result.From) || !f.CreatedAt.Before(a.result.To) {
+			continue
+		}
+		scoreSum, scored := 0.0, 0
+		if f.SentimentScore != nil {
+			scoreSum, scored = *f.SentimentScore, 1
+		}
+		start := models.IntervalStart(f.CreatedAt, q.Interval)
+		a.add(start, f.Sentiment, 1, scoreSum, scored)
+		for _, answer := range f.Answers {
+			var value int
+			if _, ok := a.questions[answer.QuestionID]; ok && json.Unmarshal(answer.Value, &value) == nil {
+				a.addAnswers(start, answer.QuestionID, value, 1)
+			}
+		}
+
+		created := f.CreatedAt.UTC()
+		windows[[2]int{int(created.Weekday()), created.Hour()}]++
+	}
+
+	a.result.PeakWindows = peakWindows(windows)
+	return a.finish(), nil
+}
+
+// roomVisitKey identifies the last visit of a user to a room
+type roomVisitKey struct {
+	userID int
+	roomID string
+}
+
+// RecordRoomVisit marks a room's feedback as read by a user up to now
+func (m *memoryStore) RecordRoomVisit(userID int, roomID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.rooms[roomID]; !ok {
+		return ErrRoomNotFound
+	}
+	m.roomVisits[roomVisitKey{userID, roomID}] = time.Now().UTC()
+	return nil
+}
+
+// GetDashboard summarises the activity of every room owned by a user
+func (m *memoryStore) GetDashboard(userID int, now time.Time) (*models.Dashboard, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rooms := []models.Room{}
+	for _, room := range m.rooms {
+		if room.CreatorID == userID {
+			rooms = append(rooms, room)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		if !rooms[i].CreatedAt.Equal(rooms[j].CreatedAt) {
+			return rooms[i].CreatedAt.After(rooms[j].CreatedAt)
+		}
+		return rooms[i].ID < rooms[j].ID
+	})
+
+	activities := make(map[string]*models.RoomActivity, len(rooms))
+	sums := make(map[string]*trendSums, len(rooms))
+	for i := range rooms {
+		activity := &models.RoomActivity{Room: rooms[i]}
+		if visited, ok := m.roomVisits[roomVisitKey{userID, rooms[i].ID}]; ok {
+			activity.LastVisitedAt = &visited
+		}
+		activities[rooms[i].ID] = activity
+		sums[rooms[i].ID] = &trendSums{}
+	}
+
+	for _, f := range m.feedback {
+		activity, ok := activities[f.RoomID]
+		if !ok {
+			continue
+		}
+		activity.FeedbackCount++
+		if activity.LastVisitedAt == nil || f.CreatedAt.After(*activity.LastVisitedAt) {
+			activity.UnreadCount++
+		}
+		if activity.LastFeedbackAt == nil || f.CreatedAt.After(*activity.LastFeedbackAt) {
+			created := f.CreatedAt
+			activity.LastFeedbackAt = &created
+		}
+		addSentiment(&activity.Sentiment, f.Sentiment, 1)
+		sums[f.RoomID].addScore(f.SentimentScore, f.CreatedAt, now)
+	}
+
+	d := newDashboard()
+	for _, room := range rooms {
+		d.add(*activities[room.ID], *sums[room.ID])
+	}
+	return d.finish(), nil
+}
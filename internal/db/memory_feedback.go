@@ -0,0 +1,209 @@
+package db
+
+import (
+	"sort"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// ListFeedback returns one page of a room's feedback matching the query
+func (m *memoryStore) ListFeedback(roomID string, q models.FeedbackQuery) (*models.FeedbackPage, error) {
+	cursor, err := decodeFeedbackCursor(q.Cursor, q.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matches := []models.Feedback{}
+	for _, f := range m.feedback {
+		if f.RoomID != roomID ||
+			(q.Sentiment != "" && f.Sentiment != q.Sentiment) ||
+			(q.Status != "" && f.Status != q.Status) ||
+			(q.QAState != "" && f.QAState != q.QAState) ||
+			(q.Tag != "" && !containsTag(f.Tags, q.Tag)) ||
+			(q.From != nil && f.CreatedAt.Before(*q.From)) ||
+			(q.To != nil && !f.CreatedAt.Before(*q.To)) {
+			continue
+		}
+		f.Tags = append([]string{}, f.Tags...)
+		matches = append(matches, f)
+	}
+
+	// before reports whether a sorts before b in ascending order
+	before := func(a, b *models.Feedback) bool {
+		switch q.Sort {
+		case models.FeedbackSortScore:
+			if sa, sb := feedbackScore(a), feedbackScore(b); sa != sb {
+				return sa < sb
+			}
+		case models.FeedbackSortVotes:
+			if a.Votes != b.Votes {
+				return a.Votes < b.Votes
+			}
+		default:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if q.Ascending {
+			return before(&matches[i], &matches[j])
+		}
+		return before(&matches[j], &matches[i])
+	})
+
+	page := &models.FeedbackPage{Total: len(matches), Items: []models.Feedback{}}
+	for i := range matches {
+		if cursor != nil {
+			position := &models.Feedback{ID: cursor.ID}
+			switch {
+			case cursor.Time != nil:
+				position.CreatedAt = *cursor.Time
+			case cursor.Votes != nil:
+				position.Votes = *cursor.Votes
+			default:
+				position.SentimentScore = cursor.Score
+			}
+			if q.Ascending && !before(position, &matches[i]) || !q.Ascending && !before(&matches[i], position) {
+				continue
+			}
+		}
+		if len(page.Items) == q.Limit {
+			last := page.Items[len(page.Items)-1]
+			page.NextCursor = encodeFeedbackCursor(&last, q.Sort)
+			break
+		}
+		page.Items = append(page.Items, matches[i])
+	}
+	return page, nil
+}
+
+// feedbackScore is the score sort key of an entry
+func feedbackScore(f *models.Feedback) float64 {
+	if f.SentimentScore == nil {
+		return pendingScore
+	}
+	return *f.SentimentScore
+}
+
+// containsTag reports whether tags includes tag
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateFeedbackStatus changes the triage status of a feedback entry
+func (m *memoryStore) UpdateFeedbackStatus(id int, status models.FeedbackStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.feedback[id]
+	if !ok {
+		return ErrFeedbackNotFound
+	}
+
+	f.Status = status
+	m.feedback[id] = f
+	return nil
+}
+
+// UpdateQuestion sets the Q&A state and pin of a question
+func (m *memoryStore) UpdateQuestion(id int, state models.QAState, pinned bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.feedback[id]
+	if !ok {
+		return ErrFeedbackNotFound
+	}
+
+	f.QAState, f.Pinned = state, pinned
+	m.feedback[id] = f
+	return nil
+}
+
+// SetFeedbackTags replaces the tags of a feedback entry
+func (m *memoryStore) SetFeedbackTags(id int, tags []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.feedback[id]
+	if !ok {
+		return ErrFeedbackNotFound
+	}
+
+	f.Tags = append([]string{}, tags...)
+	sort.Strings(f.Tags)
+	m.feedback[id] = f
+	return nil
+}
+
+// SearchFeedback finds feedback in the rooms owned by a user by scanning content
+func (m *memoryStore) SearchFeedback(userID int, query string, limit, offset int) (*models.FeedbackSearchPage, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return &models.FeedbackSearchPage{Items: []models.FeedbackSearchResult{}}, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matches := []models.FeedbackSearchResult{}
+	for _, f := range m.feedback {
+		room, ok := m.rooms[f.RoomID]
+		if !ok || room.CreatorID != userID {
+			continue
+		}
+		snippet, rank, ok := matchContent(f.Content, terms)
+		if !ok {
+			continue
+		}
+		f.Tags = append([]string{}, f.Tags...)
+		matches = append(matches, models.FeedbackSearchResult{Feedback: f, RoomName: room.Name, Snippet: snippet, Rank: rank})
+	}
+
+	return pageSearchResults(matches, limit, offset), nil
+}
+
+// ImportFeedback stores entries in a room, all at once unless dryRun is set
+func (m *memoryStore) ImportFeedback(roomID string, entries []models.FeedbackImport, dryRun bool) ([]models.Feedback, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.rooms[roomID]; !ok {
+		return nil, ErrRoomNotFound
+	}
+
+	imported := make([]models.Feedback, 0, len(entries))
+	for i, entry := range entries {
+		imported = append(imported, models.Feedback{
+			ID:             m.nextFeedbackID + i,
+			RoomID:         roomID,
+			Content:        entry.Content,
+			Sentiment:      entry.Sentiment,
+			SentimentScore: entry.SentimentScore,
+			Status:         entry.Status,
+			Tags:           append([]string{}, entry.Tags...),
+			CreatedAt:      entry.CreatedAt.UTC(),
+		})
+	}
+	if dryRun {
+		return imported, nil
+	}
+
+	for _, f := range imported {
+		stored := f
+		stored.Tags = append([]string{}, f.Tags...)
+		m.feedback[f.ID] = stored
+	}
+	m.nextFeedbackID += len(imported)
+	return imported, nil
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"sort"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// EnqueueJob stores a queued job that becomes ready at runAt
+func (m *memoryStore) EnqueueJob(kind string, payload []byte, runAt time.Time, maxAttempts int) (*models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job := models.Job{
+		ID:          m.nextJobID,
+		Kind:        kind,
+		Payload:     append([]byte(nil), payload...),
+		Status:      models.JobStatusQueued,
+		MaxAttempts: maxAttempts,
+		RunAt:       runAt.UTC(),
+		CreatedAt:   time.Now().UTC(),
+	}
+	m.jobs[job.ID] = job
+	m.nextJobID++
+
+	return &job, nil
+}
+
+// LeaseJobs claims up to limit ready jobs, including running jobs whose lease expired
+func (m *memoryStore) LeaseJobs(now time.Time, lease time.Duration, limit int) ([]models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ready := []models.Job{}
+	for _, job := range m.jobs {
+		if (job.Status == models.JobStatusQueued && !job.RunAt.After(now)) ||
+			(job.Status == models.JobStatusRunning && job.LockedUntil != nil && job.LockedUntil.Before(now)) {
+			ready = append(ready, job)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		if !ready[i].RunAt.Equal(ready[j].RunAt) {
+			return ready[i].RunAt.Before(ready[j].RunAt)
+		}
+		return ready[i].ID < ready[j].ID
+	})
+	if len(ready) > limit {
+		ready = ready[:limit]
+	}
+
+	lockedUntil := now.Add(lease).UTC()
+	for i := range ready {
+		ready[i].Status = models.JobStatusRunning
+		ready[i].Attempts++
+		ready[i].LockedUntil = &lockedUntil
+		m.jobs[ready[i].ID] = ready[i]
+	}
+	return ready, nil
+}
+
+// CompleteJob marks a leased job as done
+func (m *memoryStore) CompleteJob(id int64, attempt int) error {
+	m.finishJob(id, attempt, func(job *models.Job) {
+		job.Status = models.JobStatusDone
+		job.LastError = ""
+	})
+	return nil
+}
+
+// RetryJob returns a leased job to the queue to run again at runAt
+func (m *memoryStore) RetryJob(id int64, attempt int, runAt time.Time, lastError string) error {
+	m.finishJob(id, attempt, func(job *models.Job) {
+		job.Status = models.JobStatusQueued
+		job.RunAt = runAt.UTC()
+		job.LastError = lastError
+	})
+	return nil
+}
+
+// BuryJob moves a leased job to the dead-letter state
+func (m *memoryStore) BuryJob(id int64, attempt int, lastError string) error {
+	m.finishJob(id, attempt, func(job *models.Job) {
+		job.Status = models.JobStatusDead
+		job.LastError = lastError
+	})
+	return nil
+}
+
+// PurgeFinishedJobs deletes completed jobs. The memory store does not track
+// update times, so every completed job is removed.
+func (m *memoryStore) PurgeFinishedJobs(before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, job := range m.jobs {
+		if job.Status == models.JobStatusDone {
+			delete(m.jobs, id)
+		}
+	}
+	return nil
+}
+
+// finishJob applies a state change to a job still held under the given attempt
+func (m *memoryStore) finishJob(id int64, attempt int, apply func(job *models.Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || job.Status != models.JobStatusRunning || job.Attempts != attempt {
+		return
+	}
+
+	apply(&job)
+	job.LockedUntil = nil
+	m.jobs[id] = job
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// GetQuestions lists the questions of a room in order
+func (m *memoryStore) GetQuestions(roomID string) ([]models.Question, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return copyQuestions(m.questions[roomID]), nil
+}
+
+// GetQuestionUsage counts the stored answers to each question of a room and
+// the options they picked
+func (m *memoryStore) GetQuestionUsage(roomID string) (map[int]models.QuestionUsage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	types := make(map[int]models.QuestionType, len(m.questions[roomID]))
+	for _, q := range m.questions[roomID] {
+		types[q.ID] = q.Type
+	}
+	usage := make(map[int]models.QuestionUsage)
+	for _, f := range m.feedback {
+		if f.RoomID != roomID {
+			continue
+		}
+		for _, a := range f.Answers {
+			u := usage[a.QuestionID]
+			u.Add(types[a.QuestionID], a.Value, 1)
+			usage[a.QuestionID] = u
+		}
+	}
+	return usage, nil
+}
+
+// SetQuestions replaces the questions of a room, deleting the answers to the
+// questions that are left out
+func (m *memoryStore) SetQuestions(roomID string, questions []models.Question) ([]models.Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.rooms[roomID]; !ok {
+		return nil, ErrRoomNotFound
+	}
+
+	current := make(map[int]bool, len(m.questions[roomID]))
+	for _, q := range m.questions[roomID] {
+		current[q.ID] = true
+	}
+	kept := make(map[int]bool, len(questions))
+	for _, q := range questions {
+		if q.ID != 0 {
+			if !current[q.ID] {
+				return nil, ErrQuestionNotFound
+			}
+			kept[q.ID] = true
+		}
+	}
+
+	saved := copyQuestions(questions)
+	for i := range saved {
+		saved[i].RoomID = roomID
+		if saved[i].ID == 0 {
+			saved[i].ID = m.nextQuestionID
+			m.nextQuestionID++
+		}
+	}
+
+	// Drop the answers to deleted questions
+	for id, f := range m.feedback {
+		if f.RoomID != roomID || len(f.Answers) == 0 {
+			continue
+		}
+		answers := []models.Answer{}
+		for _, a := range f.Answers {
+			if kept[a.QuestionID] {
+				answers = append(answers, a)
+			}
+		}
+		if len(answers) == 0 {
+			answers = nil
+		}
+		f.Answers = answers
+		m.feedback[id] = f
+	}
+
+	m.questions[roomID] = saved
+	return copyQuestions(saved), nil
+}
+
+// copyQuestions copies questions so that callers cannot modify stored options
+func copyQuestions(questions []models.Question) []models.Question {
+	copied := make([]models.Question, len(questions))
+	for i, q := range questions {
+		if q.Options != nil {
+			q.Options = append([]string{}, q.Options...)
+		}
+		copied[i] = q
+	}
+	return copied
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"sort"
+	"time"
+)
+
+// reactionKey identifies one reaction of one participant to a feedback entry
+type reactionKey struct {
+	feedbackID    int
+	participantID string
+	reaction      string
+}
+
+// AddReaction records a reaction of a participant to a feedback entry. It
+// reports false when the participant had already left that reaction.
+func (m *memoryStore) AddReaction(feedbackID int, participantID, reaction string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.feedback[feedbackID]
+	if !ok {
+		return false, ErrFeedbackNotFound
+	}
+	key := reactionKey{feedbackID, participantID, reaction}
+	if _, ok := m.reactions[key]; ok {
+		return false, nil
+	}
+	m.reactions[key] = time.Now().UTC()
+
+	f.Reactions = countReaction(f.Reactions, reaction, 1)
+	m.feedback[feedbackID] = f
+	return true, nil
+}
+
+// RemoveReaction takes back a reaction of a participant to a feedback entry.
+// It returns ErrReactionNotFound when the participant never left it.
+func (m *memoryStore) RemoveReaction(feedbackID int, participantID, reaction string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := reactionKey{feedbackID, participantID, reaction}
+	if _, ok := m.reactions[key]; !ok {
+		return ErrReactionNotFound
+	}
+	delete(m.reactions, key)
+
+	f := m.feedback[feedbackID]
+	f.Reactions = countReaction(f.Reactions, reaction, -1)
+	m.feedback[feedbackID] = f
+	return nil
+}
+
+// countReaction returns a copy of counts with delta added to reaction, so that
+// entries handed out earlier keep their counts. Reactions dropping to zero are
+// left out.
+func countReaction(counts map[string]int, reaction string, delta int) map[string]int {
+	updated := make(map[string]int, len(counts)+1)
+	for r, n := range counts {
+		updated[r] = n
+	}
+	if updated[reaction] += delta; updated[reaction] <= 0 {
+		delete(updated, reaction)
+	}
+	if len(updated) == 0 {
+		return nil
+	}
+	return updated
+}
+
+// GetParticipantReactions returns the reactions of a participant in a room per
+// feedback entry, in the order they were left
+func (m *memoryStore) GetParticipantReactions(roomID, participantID string) (map[int][]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []reactionKey{}
+	for k := range m.reactions {
+		if k.participantID == participantID && m.feedback[k.feedbackID].RoomID == roomID {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.feedbackID != b.feedbackID {
+			return a.feedbackID < b.feedbackID
+		}
+		if ta, tb := m.reactions[a], m.reactions[b]; !ta.Equal(tb) {
+			return ta.Before(tb)
+		}
+		return a.reaction < b.reaction
+	})
+
+	reactions := make(map[int][]string)
+	for _, k := range keys {
+		reactions[k.feedbackID] = append(reactions[k.feedbackID], k.reaction)
+	}
+	return reactions, nil
+}
+
+// copyReactions copies the emoji set of a room, keeping nil, for the default
+// set, apart from an empty set
+func copyReactions(reactions []string) []string {
+	if reactions == nil {
+		return nil
+	}
+	return append([]string{}, reactions...)
+}
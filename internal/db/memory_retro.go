@@ -0,0 +1,306 @@
+package db
+
+import (
+	"sort"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// voteKey identifies the votes of one participant on one feedback entry
+type voteKey struct {
+	feedbackID    int
+	participantID string
+}
+
+// GetColumns lists the columns of a retro room from left to right
+func (m *memoryStore) GetColumns(roomID string) ([]models.RetroColumn, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]models.RetroColumn{}, m.retroColumns[roomID]...), nil
+}
+
+// SetColumns replaces the columns of a retro room. Columns left out are
+// deleted unless they hold cards.
+func (m *memoryStore) SetColumns(roomID string, columns []models.RetroColumn) ([]models.RetroColumn, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.rooms[roomID]; !ok {
+		return nil, ErrRoomNotFound
+	}
+
+	current := make(map[int]bool, len(m.retroColumns[roomID]))
+	for _, c := range m.retroColumns[roomID] {
+		current[c.ID] = true
+	}
+	for _, c := range columns {
+		if c.ID != 0 {
+			if !current[c.ID] {
+				return nil, ErrColumnNotFound
+			}
+			delete(current, c.ID)
+		}
+	}
+	for _, f := range m.feedback {
+		if f.RoomID == roomID && f.ColumnID != nil && current[*f.ColumnID] {
+			return nil, ErrColumnNotEmpty
+		}
+	}
+
+	saved := append([]models.RetroColumn{}, columns...)
+	for i := range saved {
+		saved[i].RoomID = roomID
+		if saved[i].ID == 0 {
+			saved[i].ID = m.nextColumnID
+			m.nextColumnID++
+		}
+	}
+	m.retroColumns[roomID] = saved
+	return append([]models.RetroColumn{}, saved...), nil
+}
+
+// SetRetroPhase moves a retro room to another phase and sets its dot budget
+func (m *memoryStore) SetRetroPhase(roomID string, phase models.RetroPhase, votesPerParticipant int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	room, ok := m.rooms[roomID]
+	if !ok {
+		return ErrRoomNotFound
+	}
+	room.Phase = phase
+	room.VotesPerParticipant = votesPerParticipant
+	m.rooms[roomID] = room
+	return nil
+}
+
+// GetCardGroups lists the card groups of a retro room in creation order, with
+// their cards and vote totals
+func (m *memoryStore) GetCardGroups(roomID string) ([]models.CardGroup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	groups := []models.CardGroup{}
+	for _, g := range m.cardGroups {
+		if g.RoomID == roomID {
+			groups = append(groups, m.groupWithCards(g))
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	return groups, nil
+}
+
+// groupWithCards fills in the cards and vote total of a stored group
+func (m *memoryStore) groupWithCards(g models.CardGroup) models.CardGroup {
+	g.FeedbackIDs, g.Votes = []int{}, 0
+	for _, f := range m.feedback {
+		if f.GroupID != nil && *f.GroupID == g.ID {
+			g.FeedbackIDs = append(g.FeedbackIDs, f.ID)
+			g.Votes += f.Votes
+		}
+	}
+	sort.Ints(g.FeedbackIDs)
+	return g
+}
+
+// SaveCardGroup creates a card group when its ID is 0 and otherwise renames it,
+// then makes its cards exactly group.FeedbackIDs. Groups left without cards are
+// deleted.
+func (m *memoryStore) SaveCardGroup(group *models.CardGroup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, id := range group.FeedbackIDs {
+		if f, ok := m.feedback[id]; !ok || f.RoomID != group.RoomID {
+			return ErrFeedbackNotFound
+		}
+	}
+
+	if group.ID == 0 {
+		group.ID = m.nextGroupID
+		group.CreatedAt = time.Now().UTC()
+		m.nextGroupID++
+	} else {
+		stored, ok := m.cardGroups[group.ID]
+		if !ok || stored.RoomID != group.RoomID {
+			return ErrCardGroupNotFound
+		}
+		group.CreatedAt = stored.CreatedAt
+	}
+	m.cardGroups[group.ID] = models.CardGroup{ID: group.ID, RoomID: group.RoomID, Title: group.Title, CreatedAt: group.CreatedAt}
+
+	members := make(map[int]bool, len(group.FeedbackIDs))
+	for _, id := range group.FeedbackIDs {
+		members[id] = true
+	}
+	groupID := group.ID
+	for id, f := range m.feedback {
+		switch {
+		case members[id]:
+			f.GroupID = &groupID
+		case f.GroupID != nil && *f.GroupID == groupID:
+			f.GroupID = nil
+		default:
+			continue
+		}
+		m.feedback[id] = f
+	}
+
+	for id, g := range m.cardGroups {
+		if g.RoomID == group.RoomID && len(m.groupWithCards(g).FeedbackIDs) == 0 {
+			delete(m.cardGroups, id)
+		}
+	}
+	*group = m.groupWithCards(m.cardGroups[group.ID])
+	return nil
+}
+
+// DeleteCardGroup deletes a card group; its cards stay on the board ungrouped
+func (m *memoryStore) DeleteCardGroup(roomID string, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if g, ok := m.cardGroups[id]; !ok || g.RoomID != roomID {
+		return ErrCardGroupNotFound
+	}
+	delete(m.cardGroups, id)
+	for feedbackID, f := range m.feedback {
+		if f.GroupID != nil && *f.GroupID == id {
+			f.GroupID = nil
+			m.feedback[feedbackID] = f
+		}
+	}
+	return nil
+}
+
+// GetActionItems lists the action items of a room in creation order
+func (m *memoryStore) GetActionItems(roomID string) ([]models.ActionItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := []models.ActionItem{}
+	for _, item := range m.actionItems {
+		if item.RoomID == roomID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+// GetActionItem retrieves one action item of a room
+func (m *memoryStore) GetActionItem(roomID string, id int) (*models.ActionItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.actionItems[id]
+	if !ok || item.RoomID != roomID {
+		return nil, ErrActionItemNotFound
+	}
+	return &item, nil
+}
+
+// CreateActionItem stores an action item
+func (m *memoryStore) CreateActionItem(item *models.ActionItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.rooms[item.RoomID]; !ok {
+		return ErrRoomNotFound
+	}
+	item.ID = m.nextActionID
+	item.CreatedAt = time.Now().UTC()
+	m.actionItems[item.ID] = *item
+	m.nextActionID++
+	return nil
+}
+
+// UpdateActionItem writes the title, owner and done flag of an action item
+func (m *memoryStore) UpdateActionItem(item *models.ActionItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.actionItems[item.ID]
+	if !ok || stored.RoomID != item.RoomID {
+		return ErrActionItemNotFound
+	}
+	stored.Title, stored.Owner, stored.Done = item.Title, item.Owner, item.Done
+	m.actionItems[item.ID] = stored
+	return nil
+}
+
+// DeleteActionItem deletes an action item of a room
+func (m *memoryStore) DeleteActionItem(roomID string, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if item, ok := m.actionItems[id]; !ok || item.RoomID != roomID {
+		return ErrActionItemNotFound
+	}
+	delete(m.actionItems, id)
+	return nil
+}
+
+// AddVote adds one vote of a participant to a feedback entry within limits and
+// returns the entry's new total
+func (m *memoryStore) AddVote(feedbackID int, participantID string, limits models.VoteLimits) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.feedback[feedbackID]
+	if !ok {
+		return 0, ErrFeedbackNotFound
+	}
+
+	key := voteKey{feedbackID, participantID}
+	inRoom := 0
+	for k, count := range m.votes {
+		if k.participantID == participantID && m.feedback[k.feedbackID].RoomID == f.RoomID {
+			inRoom += count
+		}
+	}
+	if (limits.PerEntry > 0 && m.votes[key] >= limits.PerEntry) || (limits.PerRoom > 0 && inRoom >= limits.PerRoom) {
+		return 0, ErrVoteLimit
+	}
+
+	m.votes[key]++
+	f.Votes++
+	m.feedback[feedbackID] = f
+	return f.Votes, nil
+}
+
+// RemoveVote takes back one vote of a participant from a feedback entry and
+// returns the entry's new total
+func (m *memoryStore) RemoveVote(feedbackID int, participantID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := voteKey{feedbackID, participantID}
+	if m.votes[key] == 0 {
+		return 0, ErrVoteNotFound
+	}
+	if m.votes[key]--; m.votes[key] == 0 {
+		delete(m.votes, key)
+	}
+
+	f := m.feedback[feedbackID]
+	f.Votes--
+	m.feedback[feedbackID] = f
+	return f.Votes, nil
+}
+
+// GetParticipantVotes returns the votes of a participant in a room per feedback entry
+func (m *memoryStore) GetParticipantVotes(roomID, participantID string) (map[int]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	votes := make(map[int]int)
+	for k, count := range m.votes {
+		if k.participantID == participantID && m.feedback[k.feedbackID].RoomID == roomID {
+			votes[k.feedbackID] = count
+		}
+	}
+	return votes, nil
+}
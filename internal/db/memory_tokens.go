@@ -0,0 +1,133 @@
+package db
+
+import (
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// issuedToken is an access token issued to a refresh token family
+type issuedToken struct {
+	familyID  string
+	expiresAt time.Time
+}
+
+// CreateRefreshToken stores the hash of a newly issued refresh token
+func (m *memoryStore) CreateRefreshToken(userID int, tokenHash, familyID string, expiresAt time.Time) (*models.RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token := models.RefreshToken{
+		ID:        m.nextTokenID,
+		UserID:    userID,
+		TokenHash: tokenHash,
+		FamilyID:  familyID,
+		ExpiresAt: expiresAt.UTC(),
+		CreatedAt: time.Now().UTC(),
+	}
+	m.refreshTokens[token.ID] = token
+	m.nextTokenID++
+
+	return &token, nil
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by the hash of its value
+func (m *memoryStore) GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, token := range m.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, ErrRefreshTokenNotFound
+}
+
+// RevokeRefreshToken revokes a token and reports whether it was still active
+func (m *memoryStore) RevokeRefreshToken(id int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.refreshTokens[id]
+	if !ok || token.RevokedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	token.RevokedAt = &now
+	m.refreshTokens[id] = token
+	return true, nil
+}
+
+// RecordAccessToken remembers the ID of an access token issued to a refresh token family
+func (m *memoryStore) RecordAccessToken(familyID, jti string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.issuedTokens[jti] = issuedToken{familyID: familyID, expiresAt: expiresAt.UTC()}
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every active token descended from the same login
+// and denylists the access tokens issued to the family that have not expired yet
+func (m *memoryStore) RevokeRefreshTokenFamily(familyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	for id, token := range m.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			m.refreshTokens[id] = token
+		}
+	}
+	for jti, token := range m.issuedTokens {
+		if token.familyID == familyID && token.expiresAt.After(now) {
+			m.revokedTokens[jti] = token.expiresAt
+		}
+	}
+	return nil
+}
+
+// RevokeAccessToken adds an access token ID to the denylist until it expires
+func (m *memoryStore) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.revokedTokens[jti] = expiresAt.UTC()
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token ID is on the denylist
+func (m *memoryStore) IsAccessTokenRevoked(jti string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, revoked := m.revokedTokens[jti]
+	return revoked, nil
+}
+
+// PurgeExpiredTokens deletes refresh tokens and denylist entries that can no longer be used
+func (m *memoryStore) PurgeExpiredTokens() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range m.refreshTokens {
+		if token.ExpiresAt.Before(now) {
+			delete(m.refreshTokens, id)
+		}
+	}
+	for jti, expiresAt := range m.revokedTokens {
+		if expiresAt.Before(now) {
+			delete(m.revokedTokens, jti)
+		}
+	}
+	for jti, token := range m.issuedTokens {
+		if token.expiresAt.Before(now) {
+			delete(m.issuedTokens, jti)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq" // PostgreSQL driver
+)
+
+// NewPostgres opens a connection to PostgreSQL and applies pending migrations
+func NewPostgres(databaseURL string) (Store, error) {
+	conn, err := connectPostgres(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateUp(conn, DriverPostgres); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &sqlStore{conn: conn, driver: DriverPostgres}, nil
+}
+
+func connectPostgres(databaseURL string) (*sql.DB, error) {
+	conn, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return conn, nil
+}
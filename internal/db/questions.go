@@ -0,0 +1,187 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// questionColumns is the column list read by scanQuestion
+const questionColumns = `id, room_id, position, type, prompt, required, options`
+
+// scanQuestion scans a row selected with questionColumns
+func scanQuestion(row rowScanner) (*models.Question, error) {
+	q := &models.Question{}
+	var options []byte
+	if err := row.Scan(&q.ID, &q.RoomID, &q.Position, &q.Type, &q.Prompt, &q.Required, &options); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(options, &q.Options); err != nil {
+		return nil, fmt.Errorf("invalid options of question %d: %w", q.ID, err)
+	}
+	if len(q.Options) == 0 {
+		q.Options = nil
+	}
+	return q, nil
+}
+
+// GetQuestions lists the questions of a room in order; empty unless it is a survey
+func (s *sqlStore) GetQuestions(roomID string) ([]models.Question, error) {
+	rows, err := s.query(
+		`SELECT `+questionColumns+` FROM questions WHERE room_id = $1 ORDER BY position, id`,
+		roomID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query questions: %w", err)
+	}
+	defer rows.Close()
+
+	questions := []models.Question{}
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan question: %w", err)
+		}
+		questions = append(questions, *q)
+	}
+	return questions, rows.Err()
+}
+
+// GetQuestionUsage counts the stored answers to each question of a room and
+// the options they picked. Identical answers are grouped in the query.
+func (s *sqlStore) GetQuestionUsage(roomID string) (map[int]models.QuestionUsage, error) {
+	rows, err := s.query(
+		`SELECT a.question_id, q.type, a.value, COUNT(*) FROM answers a
+		 JOIN questions q ON q.id = a.question_id
+		 WHERE q.room_id = $1
+		 GROUP BY a.question_id, q.type, a.value`,
+		roomID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query answers: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make(map[int]models.QuestionUsage)
+	for rows.Next() {
+		var id, n int
+		var t models.QuestionType
+		var value []byte
+		if err := rows.Scan(&id, &t, &value, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan answer count: %w", err)
+		}
+		u := usage[id]
+		u.Add(t, value, n)
+		usage[id] = u
+	}
+	return usage, rows.Err()
+}
+
+// SetQuestions replaces the questions of a room in a single transaction.
+// Questions with an ID are updated in place and keep their answers; the room's
+// other questions are deleted and those without an ID are added.
+func (s *sqlStore) SetQuestions(roomID string, questions []models.Question) ([]models.Question, error) {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	b := &queryBuilder{}
+	conditions := []string{"room_id = " + b.arg(roomID)}
+	for _, q := range questions {
+		if q.ID != 0 {
+			conditions = append(conditions, "id <> "+b.arg(q.ID))
+		}
+	}
+	if _, err := tx.Exec(s.rebind(`DELETE FROM questions WHERE `+strings.Join(conditions, " AND ")), b.args...); err != nil {
+		return nil, fmt.Errorf("failed to delete questions: %w", err)
+	}
+
+	saved := make([]models.Question, 0, len(questions))
+	for _, q := range questions {
+		q.RoomID = roomID
+		options, err := json.Marshal(q.Options)
+		if err != nil {
+			return nil, err
+		}
+		if q.Options == nil {
+			options = []byte("[]")
+		}
+
+		if q.ID != 0 {
+			result, err := tx.Exec(s.rebind(
+				`UPDATE questions SET position = $1, prompt = $2, required = $3, options = $4
+				 WHERE id = $5 AND room_id = $6`),
+				q.Position, q.Prompt, q.Required, string(options), q.ID, roomID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update question: %w", err)
+			}
+			if err := expectAffected(result, ErrQuestionNotFound); err != nil {
+				return nil, err
+			}
+		} else {
+			err := tx.QueryRow(s.rebind(
+				`INSERT INTO questions (room_id, position, type, prompt, required, options)
+				 VALUES ($1, $2, $3, $4, $5, $6)
+				 RETURNING id`),
+				roomID, q.Position, string(q.Type), q.Prompt, q.Required, string(options),
+			).Scan(&q.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert question: %w", err)
+			}
+		}
+		saved = append(saved, q)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit questions: %w", err)
+	}
+	return saved, nil
+}
+
+// attachAnswers loads the survey answers of the given entries in a single
+// query, in question order
+func (s *sqlStore) attachAnswers(feedback []models.Feedback) error {
+	if len(feedback) == 0 {
+		return nil
+	}
+
+	b := &queryBuilder{}
+	index := make(map[int]int, len(feedback))
+	placeholders := make([]string, len(feedback))
+	for i := range feedback {
+		index[feedback[i].ID] = i
+		placeholders[i] = b.arg(feedback[i].ID)
+		feedback[i].Answers = nil
+	}
+
+	rows, err := s.query(
+		`SELECT a.feedback_id, a.question_id, a.value FROM answers a
+		 JOIN questions q ON q.id = a.question_id
+		 WHERE a.feedback_id IN (`+strings.Join(placeholders, ", ")+`)
+		 ORDER BY a.feedback_id, q.position, q.id`,
+		b.args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query answers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var answer models.Answer
+		var value []byte
+		if err := rows.Scan(&id, &answer.QuestionID, &value); err != nil {
+			return fmt.Errorf("failed to scan answer: %w", err)
+		}
+		answer.Value = value
+		f := &feedback[index[id]]
+		f.Answers = append(f.Answers, answer)
+	}
+
+	return rows.Err()
+}
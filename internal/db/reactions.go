@@ -0,0 +1,113 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// AddReaction records a reaction of a participant to a feedback entry. It
+// reports false when the participant had already left that reaction.
+func (s *sqlStore) AddReaction(feedbackID int, participantID, reaction string) (bool, error) {
+	result, err := s.exec(
+		`INSERT INTO reactions (feedback_id, participant_id, reaction) VALUES ($1, $2, $3)
+		 ON CONFLICT (feedback_id, participant_id, reaction) DO NOTHING`,
+		feedbackID, participantID, reaction,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert reaction: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// RemoveReaction takes back a reaction of a participant to a feedback entry.
+// It returns ErrReactionNotFound when the participant never left it.
+func (s *sqlStore) RemoveReaction(feedbackID int, participantID, reaction string) error {
+	result, err := s.exec(
+		`DELETE FROM reactions WHERE feedback_id = $1 AND participant_id = $2 AND reaction = $3`,
+		feedbackID, participantID, reaction,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete reaction: %w", err)
+	}
+	return expectAffected(result, ErrReactionNotFound)
+}
+
+// GetParticipantReactions returns the reactions of a participant in a room per
+// feedback entry, in the order they were left
+func (s *sqlStore) GetParticipantReactions(roomID, participantID string) (map[int][]string, error) {
+	// SQLite stores whole seconds; its rowid keeps reactions of the same second in order
+	order := "r.reaction"
+	if s.driver == DriverSQLite {
+		order = "r.rowid"
+	}
+	rows, err := s.query(
+		`SELECT r.feedback_id, r.reaction FROM reactions r JOIN feedback f ON f.id = r.feedback_id
+		 WHERE f.room_id = $1 AND r.participant_id = $2
+		 ORDER BY r.feedback_id, r.created_at, `+order,
+		roomID, participantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reactions: %w", err)
+	}
+	defer rows.Close()
+
+	reactions := make(map[int][]string)
+	for rows.Next() {
+		var feedbackID int
+		var reaction string
+		if err := rows.Scan(&feedbackID, &reaction); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction: %w", err)
+		}
+		reactions[feedbackID] = append(reactions[feedbackID], reaction)
+	}
+	return reactions, rows.Err()
+}
+
+// attachReactions loads the reaction counts of the given entries in a single query
+func (s *sqlStore) attachReactions(feedback []models.Feedback) error {
+	if len(feedback) == 0 {
+		return nil
+	}
+
+	b := &queryBuilder{}
+	index := make(map[int]int, len(feedback))
+	placeholders := make([]string, len(feedback))
+	for i := range feedback {
+		index[feedback[i].ID] = i
+		placeholders[i] = b.arg(feedback[i].ID)
+		feedback[i].Reactions = nil
+	}
+
+	rows, err := s.query(
+		`SELECT feedback_id, reaction, COUNT(*) FROM reactions
+		 WHERE feedback_id IN (`+strings.Join(placeholders, ", ")+`)
+		 GROUP BY feedback_id, reaction`,
+		b.args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, count int
+		var reaction string
+		if err := rows.Scan(&id, &reaction, &count); err != nil {
+			return fmt.Errorf("failed to scan reaction count: %w", err)
+		}
+		f := &feedback[index[id]]
+		if f.Reactions == nil {
+			f.Reactions = make(map[string]int)
+		}
+		f.Reactions[reaction] = count
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestReactions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		room := createTestRoom(t, store, &models.Room{ID: "REACT1"})
+		feedback := createTestFeedback(t, store, room.ID, "a", "b")
+		a, b := feedback[0].ID, feedback[1].ID
+
+		steps := []struct {
+			feedbackID  int
+			participant string
+			reaction    string
+			want        bool
+		}{
+			{a, "p1", "\U0001F389", true},
+			{a, "p1", models.ReactionUpvote, true},
+			{a, "p1", "\U0001F389", false},
+			{a, "p2", "\U0001F389", true},
+			{b, "p1", "\U0001F389", true},
+		}
+		for _, step := range steps {
+			added, err := store.AddReaction(step.feedbackID, step.participant, step.reaction)
+			if err != nil || added != step.want {
+				t.Errorf("AddReaction(%d, %s, %s) = %v, %v, want %v", step.feedbackID, step.participant, step.reaction, added, err, step.want)
+			}
+		}
+
+		if err := store.RemoveReaction(b, "p1", "\U0001F389"); err != nil {
+			t.Fatalf("RemoveReaction() error: %v", err)
+		}
+		if err := store.RemoveReaction(b, "p1", "\U0001F389"); !errors.Is(err, ErrReactionNotFound) {
+			t.Errorf("second RemoveReaction() error = %v, want %v", err, ErrReactionNotFound)
+		}
+
+		page, err := store.ListFeedback(room.ID, models.FeedbackQuery{Sort: models.FeedbackSortCreatedAt, Ascending: true, Limit: 10})
+		if err != nil {
+			t.Fatalf("ListFeedback() error: %v", err)
+		}
+		want := []map[string]int{{"\U0001F389": 2, models.ReactionUpvote: 1}, nil}
+		for i, f := range page.Items {
+			if !reflect.DeepEqual(f.Reactions, want[i]) {
+				t.Errorf("entry %d reactions = %v, want %v", f.ID, f.Reactions, want[i])
+			}
+		}
+
+		mine, err := store.GetParticipantReactions(room.ID, "p1")
+		if err != nil {
+			t.Fatalf("GetParticipantReactions() error: %v", err)
+		}
+		if want := map[int][]string{a: {"\U0001F389", models.ReactionUpvote}}; !reflect.DeepEqual(mine, want) {
+			t.Errorf("GetParticipantReactions() = %v, want %v", mine, want)
+		}
+	})
+}
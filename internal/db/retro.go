@@ -0,0 +1,339 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// GetColumns lists the columns of a retro room from left to right
+func (s *sqlStore) GetColumns(roomID string) ([]models.RetroColumn, error) {
+	rows, err := s.query(
+		`SELECT id, room_id, position, title FROM retro_columns WHERE room_id = $1 ORDER BY position, id`,
+		roomID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := []models.RetroColumn{}
+	for rows.Next() {
+		var c models.RetroColumn
+		if err := rows.Scan(&c.ID, &c.RoomID, &c.Position, &c.Title); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+// SetColumns replaces the columns of a retro room in a single transaction.
+// Columns with an ID are updated in place; the room's other columns are
+// deleted, unless they hold cards, and those without an ID are added.
+func (s *sqlStore) SetColumns(roomID string, columns []models.RetroColumn) ([]models.RetroColumn, error) {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	b := &queryBuilder{}
+	conditions := []string{"room_id = " + b.arg(roomID)}
+	for _, c := range columns {
+		if c.ID != 0 {
+			conditions = append(conditions, "id <> "+b.arg(c.ID))
+		}
+	}
+	removed := `SELECT id FROM retro_columns WHERE ` + strings.Join(conditions, " AND ")
+
+	var cards int
+	if err := tx.QueryRow(s.rebind(`SELECT COUNT(*) FROM feedback WHERE column_id IN (`+removed+`)`), b.args...).Scan(&cards); err != nil {
+		return nil, fmt.Errorf("failed to count cards: %w", err)
+	}
+	if cards > 0 {
+		return nil, ErrColumnNotEmpty
+	}
+	if _, err := tx.Exec(s.rebind(`DELETE FROM retro_columns WHERE id IN (`+removed+`)`), b.args...); err != nil {
+		return nil, fmt.Errorf("failed to delete columns: %w", err)
+	}
+
+	saved := make([]models.RetroColumn, 0, len(columns))
+	for _, c := range columns {
+		c.RoomID = roomID
+		if c.ID != 0 {
+			result, err := tx.Exec(s.rebind(
+				`UPDATE retro_columns SET position = $1, title = $2 WHERE id = $3 AND room_id = $4`),
+				c.Position, c.Title, c.ID, roomID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update column: %w", err)
+			}
+			if err := expectAffected(result, ErrColumnNotFound); err != nil {
+				return nil, err
+			}
+		} else {
+			err := tx.QueryRow(s.rebind(
+				`INSERT INTO retro_columns (room_id, position, title) VALUES ($1, $2, $3) RETURNING id`),
+				roomID, c.Position, c.Title,
+			).Scan(&c.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert column: %w", err)
+			}
+		}
+		saved = append(saved, c)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit columns: %w", err)
+	}
+	return saved, nil
+}
+
+// SetRetroPhase moves a retro room to another phase and sets its dot budget
+func (s *sqlStore) SetRetroPhase(roomID string, phase models.RetroPhase, votesPerParticipant int) error {
+	result, err := s.exec(
+		`UPDATE rooms SET phase = $1, votes_per_participant = $2 WHERE id = $3`,
+		string(phase), votesPerParticipant, roomID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update retro phase: %w", err)
+	}
+	return expectAffected(result, ErrRoomNotFound)
+}
+
+// GetCardGroups lists the card groups of a retro room in creation order, with
+// their cards and vote totals
+func (s *sqlStore) GetCardGroups(roomID string) ([]models.CardGroup, error) {
+	rows, err := s.query(
+		`SELECT id, room_id, title, created_at FROM card_groups WHERE room_id = $1 ORDER BY id`,
+		roomID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query card groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []models.CardGroup{}
+	index := make(map[int]int)
+	for rows.Next() {
+		g := models.CardGroup{FeedbackIDs: []int{}}
+		if err := rows.Scan(&g.ID, &g.RoomID, &g.Title, &g.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan card group: %w", err)
+		}
+		index[g.ID] = len(groups)
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	rows, err = s.query(
+		`SELECT id, group_id, votes FROM feedback WHERE room_id = $1 AND group_id IS NOT NULL ORDER BY id`,
+		roomID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query grouped cards: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, groupID, votes int
+		if err := rows.Scan(&id, &groupID, &votes); err != nil {
+			return nil, fmt.Errorf("failed to scan grouped card: %w", err)
+		}
+		if i, ok := index[groupID]; ok {
+			groups[i].FeedbackIDs = append(groups[i].FeedbackIDs, id)
+			groups[i].Votes += votes
+		}
+	}
+	return groups, rows.Err()
+}
+
+// SaveCardGroup creates a card group when its ID is 0 and otherwise renames it,
+// then makes its cards exactly group.FeedbackIDs. Cards taken from other groups
+// leave them, and groups left without cards are deleted. It returns
+// ErrFeedbackNotFound when a card is not in the group's room.
+func (s *sqlStore) SaveCardGroup(group *models.CardGroup) error {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	b := &queryBuilder{}
+	placeholders := make([]string, len(group.FeedbackIDs))
+	for i, id := range group.FeedbackIDs {
+		placeholders[i] = b.arg(id)
+	}
+	cards := `room_id = ` + b.arg(group.RoomID) + ` AND id IN (` + strings.Join(placeholders, ", ") + `)`
+
+	var found int
+	if err := tx.QueryRow(s.rebind(`SELECT COUNT(*) FROM feedback WHERE `+cards), b.args...).Scan(&found); err != nil {
+		return fmt.Errorf("failed to check cards: %w", err)
+	}
+	if found != len(group.FeedbackIDs) {
+		return ErrFeedbackNotFound
+	}
+
+	if group.ID == 0 {
+		err = tx.QueryRow(s.rebind(
+			`INSERT INTO card_groups (room_id, title) VALUES ($1, $2) RETURNING id, created_at`),
+			group.RoomID, group.Title,
+		).Scan(&group.ID, &group.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert card group: %w", err)
+		}
+	} else {
+		err = tx.QueryRow(s.rebind(
+			`UPDATE card_groups SET title = $1 WHERE id = $2 AND room_id = $3 RETURNING created_at`),
+			group.Title, group.ID, group.RoomID,
+		).Scan(&group.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCardGroupNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update card group: %w", err)
+		}
+		if _, err := tx.Exec(s.rebind(`UPDATE feedback SET group_id = NULL WHERE group_id = $1`), group.ID); err != nil {
+			return fmt.Errorf("failed to ungroup cards: %w", err)
+		}
+	}
+
+	groupID := b.arg(group.ID)
+	if _, err := tx.Exec(s.rebind(`UPDATE feedback SET group_id = `+groupID+` WHERE `+cards), b.args...); err != nil {
+		return fmt.Errorf("failed to group cards: %w", err)
+	}
+
+	if err := s.deleteEmptyGroups(tx, group.RoomID); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(s.rebind(
+		`SELECT COALESCE(SUM(votes), 0) FROM feedback WHERE group_id = $1`), group.ID,
+	).Scan(&group.Votes); err != nil {
+		return fmt.Errorf("failed to count group votes: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// deleteEmptyGroups deletes the card groups of a room that have no cards left
+func (s *sqlStore) deleteEmptyGroups(tx *sql.Tx, roomID string) error {
+	_, err := tx.Exec(s.rebind(
+		`DELETE FROM card_groups WHERE room_id = $1
+		 AND NOT EXISTS (SELECT 1 FROM feedback f WHERE f.group_id = card_groups.id)`),
+		roomID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete empty card groups: %w", err)
+	}
+	return nil
+}
+
+// DeleteCardGroup deletes a card group; its cards stay on the board ungrouped
+func (s *sqlStore) DeleteCardGroup(roomID string, id int) error {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(s.rebind(`UPDATE feedback SET group_id = NULL WHERE group_id = $1 AND room_id = $2`), id, roomID); err != nil {
+		return fmt.Errorf("failed to ungroup cards: %w", err)
+	}
+	result, err := tx.Exec(s.rebind(`DELETE FROM card_groups WHERE id = $1 AND room_id = $2`), id, roomID)
+	if err != nil {
+		return fmt.Errorf("failed to delete card group: %w", err)
+	}
+	if err := expectAffected(result, ErrCardGroupNotFound); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// actionItemColumns is the column list read by scanActionItem
+const actionItemColumns = `id, room_id, feedback_id, title, owner, done, created_at`
+
+// scanActionItem scans a row selected with actionItemColumns
+func scanActionItem(row rowScanner) (*models.ActionItem, error) {
+	item := &models.ActionItem{}
+	var feedbackID sql.NullInt64
+	err := row.Scan(&item.ID, &item.RoomID, &feedbackID, &item.Title, &item.Owner, &item.Done, &item.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	item.FeedbackID = intPtr(feedbackID)
+	return item, nil
+}
+
+// GetActionItems lists the action items of a room in creation order
+func (s *sqlStore) GetActionItems(roomID string) ([]models.ActionItem, error) {
+	rows, err := s.query(`SELECT `+actionItemColumns+` FROM action_items WHERE room_id = $1 ORDER BY id`, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query action items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ActionItem{}
+	for rows.Next() {
+		item, err := scanActionItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan action item: %w", err)
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// GetActionItem retrieves one action item of a room
+func (s *sqlStore) GetActionItem(roomID string, id int) (*models.ActionItem, error) {
+	item, err := scanActionItem(s.queryRow(
+		`SELECT `+actionItemColumns+` FROM action_items WHERE id = $1 AND room_id = $2`, id, roomID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrActionItemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query action item: %w", err)
+	}
+	return item, nil
+}
+
+// CreateActionItem inserts an action item
+func (s *sqlStore) CreateActionItem(item *models.ActionItem) error {
+	err := s.queryRow(
+		`INSERT INTO action_items (room_id, feedback_id, title, owner, done)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at`,
+		item.RoomID, nullInt(item.FeedbackID), item.Title, item.Owner, item.Done,
+	).Scan(&item.ID, &item.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert action item: %w", err)
+	}
+	return nil
+}
+
+// UpdateActionItem writes the title, owner and done flag of an action item
+func (s *sqlStore) UpdateActionItem(item *models.ActionItem) error {
+	result, err := s.exec(
+		`UPDATE action_items SET title = $1, owner = $2, done = $3 WHERE id = $4 AND room_id = $5`,
+		item.Title, item.Owner, item.Done, item.ID, item.RoomID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update action item: %w", err)
+	}
+	return expectAffected(result, ErrActionItemNotFound)
+}
+
+// DeleteActionItem deletes an action item of a room
+func (s *sqlStore) DeleteActionItem(roomID string, id int) error {
+	result, err := s.exec(`DELETE FROM action_items WHERE id = $1 AND room_id = $2`, id, roomID)
+	if err != nil {
+		return fmt.Errorf("failed to delete action item: %w", err)
+	}
+	return expectAffected(result, ErrActionItemNotFound)
+}
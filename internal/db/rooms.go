@@ -0,0 +1,213 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// roomColumns is the column list read by scanRoom
+const roomColumns = `id, name, password, creator_id, is_password_protected, status, type, phase,
+	votes_per_participant, moderated, reactions, opens_at, closes_at, created_at`
+
+// scanRoom scans a row selected with roomColumns
+func scanRoom(row rowScanner) (*models.Room, error) {
+	room := &models.Room{}
+	var password, phase, reactions sql.NullString
+	var votes sql.NullInt64
+	var opensAt, closesAt sql.NullTime
+	err := row.Scan(
+		&room.ID, &room.Name, &password, &room.CreatorID, &room.IsPasswordProtected,
+		&room.Status, &room.Type, &phase, &votes, &room.Moderated, &reactions, &opensAt, &closesAt, &room.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	room.Password = password.String
+	room.Phase = models.RetroPhase(phase.String)
+	room.VotesPerParticipant = int(votes.Int64)
+	room.Reactions = splitReactions(reactions)
+	room.OpensAt = timePtr(opensAt)
+	room.ClosesAt = timePtr(closesAt)
+	return room, nil
+}
+
+// CreateRoom inserts a new room; room.Password holds the bcrypt hash or is empty for open rooms.
+// It returns ErrRoomIDTaken when the ID is already in use. The columns of retro
+// rooms are added separately with SetColumns.
+func (s *sqlStore) CreateRoom(room *models.Room) error {
+	room.IsPasswordProtected = room.Password != ""
+	if room.Status == "" {
+		room.Status = models.RoomStatusOpen
+	}
+	if room.Type == "" {
+		room.Type = models.RoomTypeStandard
+	}
+
+	var votes sql.NullInt64
+	if room.VotesPerParticipant != 0 {
+		votes = sql.NullInt64{Int64: int64(room.VotesPerParticipant), Valid: true}
+	}
+	err := s.queryRow(
+		`INSERT INTO rooms (id, name, password, creator_id, is_password_protected, status, type, phase,
+			votes_per_participant, moderated, reactions, opens_at, closes_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		 RETURNING created_at`,
+		room.ID, room.Name, nullString(room.Password), room.CreatorID, room.IsPasswordProtected,
+		room.Status, room.Type, nullString(string(room.Phase)), votes, room.Moderated,
+		joinReactions(room.Reactions), nullTime(room.OpensAt), nullTime(room.ClosesAt),
+	).Scan(&room.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrRoomIDTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert room: %w", err)
+	}
+
+	return nil
+}
+
+// GetRoomByID retrieves a room, including its password hash, by ID
+func (s *sqlStore) GetRoomByID(id string) (*models.Room, error) {
+	room, err := scanRoom(s.queryRow(`SELECT `+roomColumns+` FROM rooms WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query room: %w", err)
+	}
+
+	return room, nil
+}
+
+// GetRoomsByUserID lists the rooms created by a user, newest first
+func (s *sqlStore) GetRoomsByUserID(userID int) ([]models.Room, error) {
+	rows, err := s.query(
+		`SELECT `+roomColumns+` FROM rooms WHERE creator_id = $1
+		 ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rooms: %w", err)
+	}
+	defer rows.Close()
+
+	rooms := []models.Room{}
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan room: %w", err)
+		}
+		room.Password = ""
+		rooms = append(rooms, *room)
+	}
+
+	return rooms, rows.Err()
+}
+
+// UpdateRoom writes the mutable fields of a room: name, password, status,
+// reactions and schedule
+func (s *sqlStore) UpdateRoom(room *models.Room) error {
+	room.IsPasswordProtected = room.Password != ""
+
+	result, err := s.exec(
+		`UPDATE rooms SET name = $1, password = $2, is_password_protected = $3, status = $4,
+		 reactions = $5, opens_at = $6, closes_at = $7
+		 WHERE id = $8`,
+		room.Name, nullString(room.Password), room.IsPasswordProtected, room.Status,
+		joinReactions(room.Reactions), nullTime(room.OpensAt), nullTime(room.ClosesAt), room.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update room: %w", err)
+	}
+
+	return expectAffected(result, ErrRoomNotFound)
+}
+
+// GetRoomsDueForTransition lists draft rooms whose opens_at and open rooms whose
+// closes_at has passed
+func (s *sqlStore) GetRoomsDueForTransition(now time.Time) ([]models.Room, error) {
+	rows, err := s.query(
+		`SELECT `+roomColumns+` FROM rooms
+		 WHERE (status = 'draft' AND opens_at IS NOT NULL AND opens_at <= $1)
+		    OR (status = 'open' AND closes_at IS NOT NULL AND closes_at <= $1)`,
+		now.UTC(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled rooms: %w", err)
+	}
+	defer rows.Close()
+
+	rooms := []models.Room{}
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan room: %w", err)
+		}
+		rooms = append(rooms, *room)
+	}
+
+	return rooms, rows.Err()
+}
+
+// TransitionRoomStatus moves a room from one status to another. It reports false when
+// the room was no longer in the expected status, e.g. because its owner changed it.
+func (s *sqlStore) TransitionRoomStatus(id string, from, to models.RoomStatus) (bool, error) {
+	result, err := s.exec(`UPDATE rooms SET status = $1 WHERE id = $2 AND status = $3`, to, id, from)
+	if err != nil {
+		return false, fmt.Errorf("failed to update room status: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	return affected == 1, nil
+}
+
+// DeleteRoom deletes a room; its feedback is removed by the ON DELETE CASCADE constraint
+func (s *sqlStore) DeleteRoom(id string) error {
+	result, err := s.exec(`DELETE FROM rooms WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete room: %w", err)
+	}
+
+	return expectAffected(result, ErrRoomNotFound)
+}
+
+// joinReactions stores the emoji set of a room as a comma-separated list;
+// nil, for the default set, is stored as NULL and an empty set as ”
+func joinReactions(reactions []string) sql.NullString {
+	if reactions == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: strings.Join(reactions, ","), Valid: true}
+}
+
+// splitReactions reverses joinReactions
+func splitReactions(stored sql.NullString) []string {
+	switch {
+	case !stored.Valid:
+		return nil
+	case stored.String == "":
+		return []string{}
+	}
+	return strings.Split(stored.String, ",")
+}
+
+// expectAffected returns notFound when a statement matched no rows
+func expectAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
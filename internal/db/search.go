@@ -0,0 +1,305 @@
+package db
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// Full-text search backends:
+//   - PostgreSQL ranks a GIN-indexed tsvector column (migration 008).
+//   - SQLite uses an FTS5 index (search_fts5.go), which needs the binary to be
+//     built with `-tags sqlite_fts5`; the Makefile sets it and Open refuses
+//     SQLite databases without it.
+//   - The memory store, and SQLite stores opened by tests built without the
+//     tag, scan content for every term instead.
+
+// Snippet markers wrap matching terms. Private-use characters are used so that
+// the snippet can be HTML-escaped before the markers become <mark> tags.
+const (
+	markStart = "\uE000"
+	markEnd   = "\uE001"
+)
+
+// snippetRadius is how many characters of context the scanning search keeps
+// around the first match
+const snippetRadius = 60
+
+// searchFeedbackColumns is feedbackColumns qualified for queries joining rooms
+var searchFeedbackColumns = qualifyColumns(feedbackColumns, "f")
+
+// SearchFeedback finds feedback in the rooms owned by a user, best match first
+func (s *sqlStore) SearchFeedback(userID int, query string, limit, offset int) (*models.FeedbackSearchPage, error) {
+	switch {
+	case s.driver == DriverPostgres:
+		return s.searchPostgres(userID, query, limit, offset)
+	case sqliteFTS5:
+		return s.searchFTS5(userID, query, limit, offset)
+	default:
+		return s.searchScan(userID, query, limit, offset)
+	}
+}
+
+// searchPostgres ranks matches with ts_rank and builds snippets with ts_headline
+func (s *sqlStore) searchPostgres(userID int, query string, limit, offset int) (*models.FeedbackSearchPage, error) {
+	page := &models.FeedbackSearchPage{Items: []models.FeedbackSearchResult{}}
+	err := s.queryRow(
+		`SELECT COUNT(*) FROM feedback f JOIN rooms r ON r.id = f.room_id
+		 WHERE r.creator_id = $1 AND f.search_vector @@ websearch_to_tsquery('english', $2)`,
+		userID, query,
+	).Scan(&page.Total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	options := `StartSel="` + markStart + `", StopSel="` + markEnd +
+		`", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "`
+	rows, err := s.query(
+		`SELECT `+searchFeedbackColumns+`, r.name,
+			ts_headline('english', f.content, q.query, $5),
+			ts_rank(f.search_vector, q.query) AS rank
+		 FROM feedback f
+		 JOIN rooms r ON r.id = f.room_id
+		 CROSS JOIN websearch_to_tsquery('english', $2) AS q(query)
+		 WHERE r.creator_id = $1 AND f.search_vector @@ q.query
+		 ORDER BY rank DESC, f.id DESC
+		 LIMIT $3 OFFSET $4`,
+		userID, query, limit, offset, options,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search feedback: %w", err)
+	}
+
+	if err := s.collectSearchResults(rows, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// searchScan matches every term of the query as a case-insensitive substring
+// and ranks and pages the matches in Go
+func (s *sqlStore) searchScan(userID int, query string, limit, offset int) (*models.FeedbackSearchPage, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return &models.FeedbackSearchPage{Items: []models.FeedbackSearchResult{}}, nil
+	}
+
+	b := &queryBuilder{}
+	conditions := []string{"r.creator_id = " + b.arg(userID)}
+	for _, term := range terms {
+		conditions = append(conditions, `f.content LIKE `+b.arg("%"+escapeLike(term)+"%")+` ESCAPE '\'`)
+	}
+
+	rows, err := s.query(
+		`SELECT `+searchFeedbackColumns+`, r.name
+		 FROM feedback f JOIN rooms r ON r.id = f.room_id
+		 WHERE `+strings.Join(conditions, " AND "),
+		b.args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search feedback: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []models.FeedbackSearchResult{}
+	for rows.Next() {
+		var result models.FeedbackSearchResult
+		f, err := scanFeedback(extendedRow{rows, []interface{}{&result.RoomName}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		snippet, rank, ok := matchContent(f.Content, terms)
+		if !ok {
+			continue
+		}
+		result.Feedback, result.Snippet, result.Rank = *f, snippet, rank
+		matches = append(matches, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := pageSearchResults(matches, limit, offset)
+	if err := s.attachSearchTags(page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// collectSearchResults scans rows of feedback columns, room name, raw snippet
+// and rank into page and loads the tags of the results
+func (s *sqlStore) collectSearchResults(rows rowsScanner, page *models.FeedbackSearchPage) error {
+	defer rows.Close()
+
+	for rows.Next() {
+		var result models.FeedbackSearchResult
+		var snippet string
+		f, err := scanFeedback(extendedRow{rows, []interface{}{&result.RoomName, &snippet, &result.Rank}})
+		if err != nil {
+			return fmt.Errorf("failed to scan search result: %w", err)
+		}
+		result.Feedback = *f
+		result.Snippet = highlight(snippet)
+		page.Items = append(page.Items, result)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return s.attachSearchTags(page)
+}
+
+// attachSearchTags loads the tags and answers of the feedback in a page of results
+func (s *sqlStore) attachSearchTags(page *models.FeedbackSearchPage) error {
+	feedback := make([]models.Feedback, len(page.Items))
+	for i := range page.Items {
+		feedback[i] = page.Items[i].Feedback
+	}
+	if err := s.attachDetails(feedback); err != nil {
+		return err
+	}
+	for i := range page.Items {
+		page.Items[i].Feedback.Tags = feedback[i].Tags
+		page.Items[i].Feedback.Answers = feedback[i].Answers
+	}
+	return nil
+}
+
+// rowsScanner is implemented by *sql.Rows
+type rowsScanner interface {
+	rowScanner
+	Next() bool
+	Err() error
+	Close() error
+}
+
+// searchTerms splits a query into lowercase words, ignoring punctuation
+func searchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(words))
+	terms := []string{}
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// matchContent reports whether content contains every term, ranking it by how
+// often the terms occur and building a snippet around the first match
+func matchContent(content string, terms []string) (string, float64, bool) {
+	lower := strings.ToLower(content)
+	if len(lower) != len(content) {
+		// Lowercasing changed byte offsets; match on the content as is
+		lower = content
+	}
+
+	type span struct{ start, end int }
+	spans := []span{}
+	for _, term := range terms {
+		found := false
+		for at := 0; ; {
+			i := strings.Index(lower[at:], term)
+			if i < 0 {
+				break
+			}
+			found = true
+			spans = append(spans, span{at + i, at + i + len(term)})
+			at += i + len(term)
+		}
+		if !found {
+			return "", 0, false
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	// Keep a window of context around the first match, on rune boundaries
+	from, to := spans[0].start-snippetRadius, spans[0].end+snippetRadius
+	if from < 0 {
+		from = 0
+	}
+	if to > len(content) {
+		to = len(content)
+	}
+	for from > 0 && !isRuneStart(content[from]) {
+		from--
+	}
+	for to < len(content) && !isRuneStart(content[to]) {
+		to++
+	}
+
+	var snippet strings.Builder
+	if from > 0 {
+		snippet.WriteString("…")
+	}
+	at := from
+	for _, sp := range spans {
+		if sp.start < at || sp.end > to {
+			continue
+		}
+		snippet.WriteString(content[at:sp.start])
+		snippet.WriteString(markStart + content[sp.start:sp.end] + markEnd)
+		at = sp.end
+	}
+	snippet.WriteString(content[at:to])
+	if to < len(content) {
+		snippet.WriteString("…")
+	}
+
+	rank := float64(len(spans)) / float64(1+len(strings.Fields(content)))
+	return highlight(snippet.String()), rank, true
+}
+
+// isRuneStart reports whether b begins a UTF-8 encoded rune
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// pageSearchResults sorts scanned matches by rank and cuts out one page
+func pageSearchResults(matches []models.FeedbackSearchResult, limit, offset int) *models.FeedbackSearchPage {
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Rank != matches[j].Rank {
+			return matches[i].Rank > matches[j].Rank
+		}
+		return matches[i].Feedback.ID > matches[j].Feedback.ID
+	})
+
+	page := &models.FeedbackSearchPage{Items: []models.FeedbackSearchResult{}, Total: len(matches)}
+	if offset < len(matches) {
+		end := offset + limit
+		if end > len(matches) {
+			end = len(matches)
+		}
+		page.Items = append(page.Items, matches[offset:end]...)
+	}
+	return page
+}
+
+// highlight HTML-escapes a snippet and turns the markers into <mark> tags
+func highlight(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	return strings.NewReplacer(markStart, "<mark>", markEnd, "</mark>").Replace(escaped)
+}
+
+// escapeLike escapes the LIKE wildcards in s, using \ as the escape character
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// qualifyColumns prefixes every column of a comma-separated list with alias
+func qualifyColumns(columns, alias string) string {
+	parts := strings.Split(columns, ",")
+	for i, column := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(column)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,94 @@
+//go:build sqlite_fts5
+
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// sqliteFTS5 reports whether this binary was built with FTS5 support
+const sqliteFTS5 = true
+
+// prepareSearch creates the FTS5 index of feedback content and the triggers that
+// keep it in sync. The index is built from the existing feedback the first time
+// a database is opened.
+func (s *sqlStore) prepareSearch() error {
+	var triggers int
+	err := s.queryRow(
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'feedback_fts_%'`,
+	).Scan(&triggers)
+	if err != nil {
+		return fmt.Errorf("failed to inspect search index: %w", err)
+	}
+	if triggers == 3 {
+		return nil
+	}
+
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS feedback_fts USING fts5(
+			content, content='feedback', content_rowid='id', tokenize='porter unicode61')`,
+		`CREATE TRIGGER IF NOT EXISTS feedback_fts_insert AFTER INSERT ON feedback BEGIN
+			INSERT INTO feedback_fts(rowid, content) VALUES (new.id, new.content);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS feedback_fts_delete AFTER DELETE ON feedback BEGIN
+			INSERT INTO feedback_fts(feedback_fts, rowid, content) VALUES ('delete', old.id, old.content);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS feedback_fts_update AFTER UPDATE OF content ON feedback BEGIN
+			INSERT INTO feedback_fts(feedback_fts, rowid, content) VALUES ('delete', old.id, old.content);
+			INSERT INTO feedback_fts(rowid, content) VALUES (new.id, new.content);
+		END`,
+		`INSERT INTO feedback_fts(feedback_fts) VALUES ('rebuild')`,
+	}
+	for _, statement := range statements {
+		if _, err := s.exec(statement); err != nil {
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// searchFTS5 ranks matches with bm25 and builds snippets with snippet(). Every
+// word of the query is quoted, so FTS5 operators in user input are matched literally.
+func (s *sqlStore) searchFTS5(userID int, query string, limit, offset int) (*models.FeedbackSearchPage, error) {
+	page := &models.FeedbackSearchPage{Items: []models.FeedbackSearchResult{}}
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return page, nil
+	}
+	match := `"` + strings.Join(terms, `" "`) + `"`
+
+	err := s.queryRow(
+		`SELECT COUNT(*) FROM feedback_fts
+		 JOIN feedback f ON f.id = feedback_fts.rowid
+		 JOIN rooms r ON r.id = f.room_id
+		 WHERE feedback_fts MATCH $1 AND r.creator_id = $2`,
+		match, userID,
+	).Scan(&page.Total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	rows, err := s.query(
+		`SELECT `+searchFeedbackColumns+`, r.name,
+			snippet(feedback_fts, 0, $5, $6, '…', 24),
+			-bm25(feedback_fts) AS rank
+		 FROM feedback_fts
+		 JOIN feedback f ON f.id = feedback_fts.rowid
+		 JOIN rooms r ON r.id = f.room_id
+		 WHERE feedback_fts MATCH $1 AND r.creator_id = $2
+		 ORDER BY rank DESC, f.id DESC
+		 LIMIT $3 OFFSET $4`,
+		match, userID, limit, offset, markStart, markEnd,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search feedback: %w", err)
+	}
+
+	if err := s.collectSearchResults(rows, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
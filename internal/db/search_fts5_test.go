@@ -0,0 +1,45 @@
+//go:build sqlite_fts5
+
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestSearchFTS5IndexesExistingFeedback(t *testing.T) {
+	url := "sqlite://" + filepath.Join(t.TempDir(), "test.db")
+	store, err := Open(DriverSQLite, url)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	room := createTestRoom(t, store, &models.Room{ID: "INDEXED"})
+	createTestFeedback(t, store, room.ID, "Login fails on every device")
+
+	// A database written before the index existed
+	for _, statement := range []string{
+		`DROP TRIGGER feedback_fts_insert`, `DROP TRIGGER feedback_fts_delete`,
+		`DROP TRIGGER feedback_fts_update`, `DROP TABLE feedback_fts`,
+	} {
+		if _, err := store.(*sqlStore).exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	store.Close()
+
+	if store, err = Open(DriverSQLite, url); err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer store.Close()
+
+	// Terms are stemmed: failing matches fails
+	page, err := store.SearchFeedback(room.CreatorID, "failing logins", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchFeedback() error: %v", err)
+	}
+	if page.Total != 1 || len(page.Items) != 1 || page.Items[0].Snippet != "<mark>Login</mark> <mark>fails</mark> on every device" {
+		t.Errorf("SearchFeedback() = %+v, want the existing entry with both terms highlighted", page)
+	}
+}
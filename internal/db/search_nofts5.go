@@ -0,0 +1,38 @@
+//go:build !sqlite_fts5
+
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// sqliteFTS5 reports whether this binary was built with FTS5 support
+const sqliteFTS5 = false
+
+// errFTS5Index is returned when opening a database indexed by an FTS5 build
+var errFTS5Index = errors.New("database has an FTS5 search index: open it with a binary built with `-tags sqlite_fts5`")
+
+// prepareSearch refuses databases whose feedback is indexed by a build with FTS5
+// support: this build cannot write to the index, so every feedback write would
+// fail on the sync triggers, and dropping them would leave the index stale.
+func (s *sqlStore) prepareSearch() error {
+	var triggers int
+	err := s.queryRow(
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'feedback_fts_%'`,
+	).Scan(&triggers)
+	if err != nil {
+		return fmt.Errorf("failed to inspect search index: %w", err)
+	}
+	if triggers > 0 {
+		return errFTS5Index
+	}
+	return nil
+}
+
+// searchFTS5 is unavailable without FTS5 support; SearchFeedback scans instead
+func (s *sqlStore) searchFTS5(userID int, query string, limit, offset int) (*models.FeedbackSearchPage, error) {
+	return nil, errors.New("built without sqlite_fts5")
+}
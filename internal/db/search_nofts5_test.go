@@ -0,0 +1,39 @@
+//go:build !sqlite_fts5
+
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenRefusesSQLiteWithoutFTS5(t *testing.T) {
+	if store, err := Open(DriverSQLite, "sqlite://"+filepath.Join(t.TempDir(), "test.db")); err == nil {
+		store.Close()
+		t.Fatal("Open() opened a SQLite database without FTS5 support")
+	}
+}
+
+func TestNewSQLiteRefusesFTS5Index(t *testing.T) {
+	url := "sqlite://" + filepath.Join(t.TempDir(), "test.db")
+	store, err := NewSQLite(url)
+	if err != nil {
+		t.Fatalf("NewSQLite() error: %v", err)
+	}
+	// A trigger of a build with FTS5 support, which this build cannot run
+	_, err = store.(*sqlStore).exec(`CREATE TRIGGER feedback_fts_insert AFTER INSERT ON feedback BEGIN
+		INSERT INTO feedback_fts(rowid, content) VALUES (new.id, new.content);
+	END`)
+	if err != nil {
+		t.Fatalf("CREATE TRIGGER error: %v", err)
+	}
+	store.Close()
+
+	if store, err := NewSQLite(url); !errors.Is(err, errFTS5Index) {
+		if err == nil {
+			store.Close()
+		}
+		t.Errorf("NewSQLite() error = %v, want %v", err, errFTS5Index)
+	}
+}
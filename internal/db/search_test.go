@@ -0,0 +1,136 @@
+package db
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// testSearchFeedback checks search results, ranking, snippets, scoping and
+// paging on store. Room IDs are unique so that it can run on a shared database.
+func testSearchFeedback(t *testing.T, store Store) {
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	owned := createTestRoom(t, store, &models.Room{ID: "search-" + suffix, Name: "Sprint <12>"})
+	other := createTestRoom(t, store, &models.Room{ID: "other-" + suffix})
+	t.Cleanup(func() {
+		store.DeleteRoom(owned.ID)
+		store.DeleteRoom(other.ID)
+	})
+
+	entries := createTestFeedback(t, store, owned.ID,
+		"Login fails, login times out, login is broken",
+		"The login page is slow and the layout breaks on small phones",
+		"Checkout <b>works</b> but the login & signup buttons overlap",
+		"Nothing to report",
+	)
+	createTestFeedback(t, store, other.ID, "Login fails for another owner")
+	often, slow, markup := entries[0].ID, entries[1].ID, entries[2].ID
+
+	search := func(query string, limit, offset int) *models.FeedbackSearchPage {
+		t.Helper()
+		page, err := store.SearchFeedback(owned.CreatorID, query, limit, offset)
+		if err != nil {
+			t.Fatalf("SearchFeedback(%q) error: %v", query, err)
+		}
+		return page
+	}
+	ids := func(page *models.FeedbackSearchPage) []int {
+		ids := []int{}
+		for _, result := range page.Items {
+			ids = append(ids, result.Feedback.ID)
+		}
+		return ids
+	}
+
+	// The entry mentioning the term most often ranks first; other owners'
+	// feedback is not searched
+	page := search("login", 10, 0)
+	if got := ids(page); page.Total != 3 || len(got) != 3 || got[0] != often {
+		t.Fatalf("search(login) = %v of %d, want 3 results with %d first", got, page.Total, often)
+	}
+	for i := 1; i < len(page.Items); i++ {
+		if page.Items[i].Rank > page.Items[i-1].Rank {
+			t.Errorf("results are not ordered by rank: %+v", page.Items)
+		}
+	}
+	first := page.Items[0]
+	if first.RoomName != owned.Name || !strings.Contains(strings.ToLower(first.Snippet), "<mark>login</mark>") {
+		t.Errorf("first result = %+v, want the room name and the term highlighted", first)
+	}
+
+	// Snippets are HTML-escaped apart from the highlighting
+	for _, result := range page.Items {
+		if result.Feedback.ID != markup {
+			continue
+		}
+		if strings.Contains(result.Snippet, "<b>") || !strings.Contains(result.Snippet, "&amp;") {
+			t.Errorf("snippet %q, want the content escaped", result.Snippet)
+		}
+	}
+
+	// Every term must match
+	if got := ids(search("LOGIN slow", 10, 0)); len(got) != 1 || got[0] != slow {
+		t.Errorf("search(LOGIN slow) = %v, want [%d]", got, slow)
+	}
+
+	// Pages are cut from the ranked results, with the total of all matches
+	all := ids(page)
+	second := search("login", 1, 1)
+	if got := ids(second); second.Total != 3 || len(got) != 1 || got[0] != all[1] {
+		t.Errorf("search(login) page 2 = %v of %d, want [%d] of 3", got, second.Total, all[1])
+	}
+	if past := search("login", 10, 5); past.Total != 3 || len(past.Items) != 0 {
+		t.Errorf("search(login) past the end = %d items of %d, want none of 3", len(past.Items), past.Total)
+	}
+
+	if none := search("refund", 10, 0); none.Total != 0 || none.Items == nil || len(none.Items) != 0 {
+		t.Errorf("search(refund) = %+v, want an empty page", none)
+	}
+
+	// Deleted feedback is no longer found
+	if err := store.DeleteRoom(owned.ID); err != nil {
+		t.Fatalf("DeleteRoom() error: %v", err)
+	}
+	if gone := search("login", 10, 0); gone.Total != 0 {
+		t.Errorf("search(login) after deleting the room = %d results, want none", gone.Total)
+	}
+}
+
+func TestSearchFeedback(t *testing.T) {
+	forEachStore(t, testSearchFeedback)
+}
+
+func TestSearchFeedbackPostgres(t *testing.T) {
+	databaseURL := os.Getenv("TEST_POSTGRES_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+	store, err := NewPostgres(databaseURL)
+	if err != nil {
+		t.Fatalf("NewPostgres() error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	testSearchFeedback(t, store)
+}
+
+func TestSearchFeedbackQuerySyntax(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		room := createTestRoom(t, store, &models.Room{ID: "SYNTAX"})
+		createTestFeedback(t, store, room.ID, "Login fails near the end")
+
+		// FTS5 operators and punctuation in user input are matched literally
+		for _, query := range []string{`login" OR "x`, `login*`, `NEAR(login fails)`, `-login`, `!!!`} {
+			if _, err := store.SearchFeedback(room.CreatorID, query, 10, 0); err != nil {
+				t.Errorf("SearchFeedback(%q) error: %v", query, err)
+			}
+		}
+		page, err := store.SearchFeedback(room.CreatorID, `"login" AND (fails`, 10, 0)
+		if err != nil || page.Total != 0 {
+			t.Errorf("SearchFeedback(operators) = %+v, %v, want no match for the word and", page, err)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+)
+
+// NewSQLite opens (or creates) a SQLite database file and applies pending migrations.
+// The URL may be given as sqlite://path/to/file.db, sqlite:path/to/file.db or file:path/to/file.db.
+//
+// Full-text search uses FTS5 in binaries built with `-tags sqlite_fts5`, as the
+// Makefile does. Other builds scan feedback content instead, which only tests
+// rely on: Open refuses SQLite databases in such builds.
+func NewSQLite(databaseURL string) (Store, error) {
+	conn, err := connectSQLite(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateUp(conn, DriverSQLite); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	store := &sqlStore{conn: conn, driver: DriverSQLite}
+	if err := store.prepareSearch(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func connectSQLite(databaseURL string) (*sql.DB, error) {
+	conn, err := sql.Open("sqlite3", sqliteDSN(databaseURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite allows a single writer; serialising connections avoids SQLITE_BUSY
+	conn.SetMaxOpenConns(1)
+
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return conn, nil
+}
+
+// sqliteDSN converts a sqlite:// URL into a go-sqlite3 data source name
+func sqliteDSN(databaseURL string) string {
+	path := databaseURL
+	for _, prefix := range []string{"sqlite://", "sqlite3://", "sqlite:", "file:"} {
+		if strings.HasPrefix(path, prefix) {
+			path = strings.TrimPrefix(path, prefix)
+			break
+		}
+	}
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return "file:" + path + separator + "_foreign_keys=on&_busy_timeout=5000"
+}
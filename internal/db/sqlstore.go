@@ -0,0 +1,229 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// sqlStore implements Store on top of database/sql. Queries are written with
+// PostgreSQL-style $N placeholders and rewritten for SQLite by rebind.
+type sqlStore struct {
+	conn   *sql.DB
+	driver string
+}
+
+// Close closes the underlying connection pool
+func (s *sqlStore) Close() error {
+	return s.conn.Close()
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+var placeholderRe = regexp.MustCompile(`\$(\d+)`)
+
+// rebind rewrites $N placeholders into the syntax expected by the driver
+func (s *sqlStore) rebind(query string) string {
+	if s.driver == DriverSQLite {
+		return placeholderRe.ReplaceAllString(query, "?$1")
+	}
+	return query
+}
+
+func (s *sqlStore) exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.conn.Exec(s.rebind(query), args...)
+}
+
+func (s *sqlStore) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.conn.Query(s.rebind(query), args...)
+}
+
+func (s *sqlStore) queryRow(query string, args ...interface{}) *sql.Row {
+	return s.conn.QueryRow(s.rebind(query), args...)
+}
+
+// CreateUser hashes the password and inserts a new user
+func (s *sqlStore) CreateUser(email, password string) (*models.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &models.User{Email: strings.ToLower(email)}
+	err = s.queryRow(
+		`INSERT INTO users (email, password_hash)
+		 VALUES ($1, $2)
+		 RETURNING id, subscription_type, created_at`,
+		user.Email, string(hash),
+	).Scan(&user.ID, &user.SubscriptionType, &user.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserByEmail retrieves a user, including the password hash, by email
+func (s *sqlStore) GetUserByEmail(email string) (*models.User, error) {
+	user := &models.User{}
+	err := s.queryRow(
+		`SELECT id, email, password_hash, subscription_type, created_at
+		 FROM users WHERE email = $1`,
+		strings.ToLower(email),
+	).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.SubscriptionType, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserByID retrieves a user by ID
+func (s *sqlStore) GetUserByID(id int) (*models.User, error) {
+	user := &models.User{}
+	err := s.queryRow(
+		`SELECT id, email, password_hash, subscription_type, created_at
+		 FROM users WHERE id = $1`,
+		id,
+	).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.SubscriptionType, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	return user, nil
+}
+
+// isUniqueViolation reports whether err is a unique or primary key constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+
+	return false
+}
+
+// nullTime maps a nil time to SQL NULL and normalises others to UTC
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// sqliteTimeFormat is the layout of CURRENT_TIMESTAMP in SQLite. SQLite compares
+// timestamps as text, so times compared with columns filled by that default
+// must be bound in the same layout.
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// timeArg prepares a time for comparison with a CURRENT_TIMESTAMP column
+func (s *sqlStore) timeArg(t time.Time) interface{} {
+	if s.driver == DriverSQLite {
+		return t.UTC().Format(sqliteTimeFormat)
+	}
+	return t.UTC()
+}
+
+// timePtr converts a nullable column into an optional time
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// extendedRow scans the columns of a scan function such as scanFeedback
+// followed by extra result columns
+type extendedRow struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+// Scan appends the extra destinations to those of the scan function
+func (r extendedRow) Scan(dest ...interface{}) error {
+	return r.row.Scan(append(dest, r.extra...)...)
+}
+
+// aggregateTime scans an optional timestamp computed by a query, such as the
+// MAX of a column. SQLite returns those as text rather than as time.Time.
+type aggregateTime struct {
+	Time *time.Time
+}
+
+// aggregateTimeLayouts are the text forms SQLite may return
+var aggregateTimeLayouts = []string{sqliteTimeFormat, "2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano}
+
+// Scan implements sql.Scanner
+func (t *aggregateTime) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		t.Time = nil
+		return nil
+	case time.Time:
+		utc := v.UTC()
+		t.Time = &utc
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a timestamp", src)
+	}
+
+	for _, layout := range aggregateTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			utc := parsed.UTC()
+			t.Time = &utc
+			return nil
+		}
+	}
+	return fmt.Errorf("unrecognised timestamp %q", text)
+}
+
+// nullString maps an empty string to SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullInt maps a nil integer to SQL NULL
+func nullInt(n *int) sql.NullInt64 {
+	if n == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*n), Valid: true}
+}
+
+// intPtr converts a nullable column into an optional integer
+func intPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
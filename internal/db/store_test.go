@@ -0,0 +1,156 @@
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// forEachStore runs test against a fresh SQLite store and a fresh in-memory store
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	t.Helper()
+
+	t.Run(DriverSQLite, func(t *testing.T) {
+		store, err := NewSQLite("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("NewSQLite() error: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		test(t, store)
+	})
+	t.Run(DriverMemory, func(t *testing.T) {
+		store := NewMemory()
+		t.Cleanup(func() { store.Close() })
+		test(t, store)
+	})
+}
+
+// createTestRoom creates a user owning room, filling in the room ID and creator
+func createTestRoom(t *testing.T, store Store, room *models.Room) *models.Room {
+	t.Helper()
+
+	user, err := store.CreateUser(room.ID+"@example.com", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	room.CreatorID = user.ID
+	if room.Name == "" {
+		room.Name = room.ID
+	}
+	if err := store.CreateRoom(room); err != nil {
+		t.Fatalf("CreateRoom() error: %v", err)
+	}
+	return room
+}
+
+// createTestFeedback adds feedback with the given contents to a room
+func createTestFeedback(t *testing.T, store Store, roomID string, contents ...string) []models.Feedback {
+	t.Helper()
+
+	feedback := make([]models.Feedback, len(contents))
+	for i, content := range contents {
+		feedback[i] = models.Feedback{RoomID: roomID, Content: content}
+		if err := store.CreateFeedback(&feedback[i]); err != nil {
+			t.Fatalf("CreateFeedback() error: %v", err)
+		}
+	}
+	return feedback
+}
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		driver string
+		query  string
+		want   string
+	}{
+		{DriverPostgres, `SELECT * FROM rooms WHERE id = $1`, `SELECT * FROM rooms WHERE id = $1`},
+		{DriverSQLite, `SELECT * FROM rooms WHERE id = $1`, `SELECT * FROM rooms WHERE id = ?1`},
+		{DriverSQLite, `UPDATE feedback SET status = $2 WHERE id = $1 AND room_id = $12`,
+			`UPDATE feedback SET status = ?2 WHERE id = ?1 AND room_id = ?12`},
+		{DriverSQLite, `SELECT 1`, `SELECT 1`},
+	}
+
+	for _, tt := range tests {
+		s := &sqlStore{driver: tt.driver}
+		if got := s.rebind(tt.query); got != tt.want {
+			t.Errorf("%s rebind(%q) = %q, want %q", tt.driver, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestGetRoomByID(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		createTestRoom(t, store, &models.Room{ID: "ROOM1", Password: "secret"})
+
+		room, err := store.GetRoomByID("ROOM1")
+		if err != nil {
+			t.Fatalf("GetRoomByID() error: %v", err)
+		}
+		if room.Status != models.RoomStatusOpen || room.Type != models.RoomTypeStandard || !room.IsPasswordProtected {
+			t.Errorf("GetRoomByID() = %+v, want an open, standard, protected room", room)
+		}
+		if _, err := store.GetRoomByID("MISSING"); !errors.Is(err, ErrRoomNotFound) {
+			t.Errorf("GetRoomByID(missing) error = %v, want %v", err, ErrRoomNotFound)
+		}
+		if err := store.CreateRoom(&models.Room{ID: "ROOM1", Name: "Again", CreatorID: room.CreatorID}); !errors.Is(err, ErrRoomIDTaken) {
+			t.Errorf("CreateRoom(duplicate) error = %v, want %v", err, ErrRoomIDTaken)
+		}
+	})
+}
+
+func TestUsers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		created, err := store.CreateUser("Owner@Example.com", "password")
+		if err != nil {
+			t.Fatalf("CreateUser() error: %v", err)
+		}
+		if created.Email != "owner@example.com" {
+			t.Errorf("email = %q, want it in lower case", created.Email)
+		}
+
+		byEmail, err := store.GetUserByEmail("OWNER@example.com")
+		if err != nil {
+			t.Fatalf("GetUserByEmail() error: %v", err)
+		}
+		if byEmail.ID != created.ID || bcrypt.CompareHashAndPassword([]byte(byEmail.PasswordHash), []byte("password")) != nil {
+			t.Errorf("GetUserByEmail() = %+v, want user %d with a hash of the password", byEmail, created.ID)
+		}
+		if byID, err := store.GetUserByID(created.ID); err != nil || byID.Email != created.Email {
+			t.Errorf("GetUserByID() = %+v, %v, want user %d", byID, err, created.ID)
+		}
+
+		if _, err := store.GetUserByEmail("missing@example.com"); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("GetUserByEmail(missing) error = %v, want %v", err, ErrUserNotFound)
+		}
+		if _, err := store.GetUserByID(created.ID + 1); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("GetUserByID(missing) error = %v, want %v", err, ErrUserNotFound)
+		}
+	})
+}
+
+func TestGetRoomsByUserID(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		owned := createTestRoom(t, store, &models.Room{ID: "OWNED"})
+		createTestRoom(t, store, &models.Room{ID: "OTHER"})
+
+		rooms, err := store.GetRoomsByUserID(owned.CreatorID)
+		if err != nil {
+			t.Fatalf("GetRoomsByUserID() error: %v", err)
+		}
+		if len(rooms) != 1 || rooms[0].ID != owned.ID {
+			t.Errorf("GetRoomsByUserID() = %+v, want only room %s", rooms, owned.ID)
+		}
+
+		user, err := store.CreateUser("new@example.com", "password")
+		if err != nil {
+			t.Fatalf("CreateUser() error: %v", err)
+		}
+		if rooms, err := store.GetRoomsByUserID(user.ID); err != nil || len(rooms) != 0 {
+			t.Errorf("GetRoomsByUserID(no rooms) = %+v, %v, want none", rooms, err)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// CreateRefreshToken stores the hash of a newly issued refresh token
+func (s *sqlStore) CreateRefreshToken(userID int, tokenHash, familyID string, expiresAt time.Time) (*models.RefreshToken, error) {
+	token := &models.RefreshToken{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		FamilyID:  familyID,
+		ExpiresAt: expiresAt.UTC(),
+	}
+	err := s.queryRow(
+		`INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, created_at`,
+		token.UserID, token.TokenHash, token.FamilyID, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by the hash of its value
+func (s *sqlStore) GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	token := &models.RefreshToken{}
+	var revokedAt sql.NullTime
+	err := s.queryRow(
+		`SELECT id, user_id, token_hash, family_id, expires_at, revoked_at, created_at
+		 FROM refresh_tokens WHERE token_hash = $1`,
+		tokenHash,
+	).Scan(&token.ID, &token.UserID, &token.TokenHash, &token.FamilyID, &token.ExpiresAt, &revokedAt, &token.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refresh token: %w", err)
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return token, nil
+}
+
+// RevokeRefreshToken revokes a token and reports whether it was still active
+func (s *sqlStore) RevokeRefreshToken(id int) (bool, error) {
+	result, err := s.exec(
+		`UPDATE refresh_tokens SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`,
+		time.Now().UTC(), id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return affected == 1, nil
+}
+
+// RecordAccessToken remembers the ID of an access token issued to a refresh token family
+func (s *sqlStore) RecordAccessToken(familyID, jti string, expiresAt time.Time) error {
+	_, err := s.exec(
+		`INSERT INTO issued_access_tokens (jti, family_id, expires_at) VALUES ($1, $2, $3)`,
+		jti, familyID, expiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record access token: %w", err)
+	}
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every active token descended from the same login
+// and denylists the access tokens issued to the family that have not expired yet
+func (s *sqlStore) RevokeRefreshTokenFamily(familyID string) error {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(
+		s.rebind(`UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`),
+		now, familyID,
+	); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	if _, err := tx.Exec(
+		s.rebind(`INSERT INTO revoked_tokens (jti, expires_at)
+		 SELECT jti, expires_at FROM issued_access_tokens WHERE family_id = $1 AND expires_at > $2
+		 ON CONFLICT (jti) DO NOTHING`),
+		familyID, now,
+	); err != nil {
+		return fmt.Errorf("failed to revoke access tokens of family: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit family revocation: %w", err)
+	}
+	return nil
+}
+
+// RevokeAccessToken adds an access token ID to the denylist until it expires
+func (s *sqlStore) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	_, err := s.exec(
+		`INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2)
+		 ON CONFLICT (jti) DO NOTHING`,
+		jti, expiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token ID is on the denylist
+func (s *sqlStore) IsAccessTokenRevoked(jti string) (bool, error) {
+	var exists bool
+	err := s.queryRow(
+		`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`,
+		jti,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to query revoked tokens: %w", err)
+	}
+	return exists, nil
+}
+
+// PurgeExpiredTokens deletes refresh tokens and denylist entries that can no longer be used
+func (s *sqlStore) PurgeExpiredTokens() error {
+	now := time.Now().UTC()
+	if _, err := s.exec(`DELETE FROM refresh_tokens WHERE expires_at < $1`, now); err != nil {
+		return fmt.Errorf("failed to purge refresh tokens: %w", err)
+	}
+	if _, err := s.exec(`DELETE FROM revoked_tokens WHERE expires_at < $1`, now); err != nil {
+		return fmt.Errorf("failed to purge revoked tokens: %w", err)
+	}
+	if _, err := s.exec(`DELETE FROM issued_access_tokens WHERE expires_at < $1`, now); err != nil {
+		return fmt.Errorf("failed to purge issued access tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevokeRefreshToken(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		user, err := store.CreateUser("owner@example.com", "password")
+		if err != nil {
+			t.Fatalf("CreateUser() error: %v", err)
+		}
+		expiresAt := time.Now().Add(time.Hour)
+		first, err := store.CreateRefreshToken(user.ID, "hash-1", "family", expiresAt)
+		if err != nil {
+			t.Fatalf("CreateRefreshToken() error: %v", err)
+		}
+		if _, err := store.CreateRefreshToken(user.ID, "hash-2", "family", expiresAt); err != nil {
+			t.Fatalf("CreateRefreshToken() error: %v", err)
+		}
+
+		for jti, family := range map[string]string{"jti-1": "family", "jti-2": "other"} {
+			if err := store.RecordAccessToken(family, jti, expiresAt); err != nil {
+				t.Fatalf("RecordAccessToken() error: %v", err)
+			}
+		}
+		if err := store.RecordAccessToken("family", "jti-expired", time.Now().Add(-time.Minute)); err != nil {
+			t.Fatalf("RecordAccessToken() error: %v", err)
+		}
+
+		if revoked, err := store.RevokeRefreshToken(first.ID); err != nil || !revoked {
+			t.Fatalf("RevokeRefreshToken() = %v, %v, want true", revoked, err)
+		}
+		if revoked, err := store.RevokeRefreshToken(first.ID); err != nil || revoked {
+			t.Errorf("second RevokeRefreshToken() = %v, %v, want false", revoked, err)
+		}
+
+		if err := store.RevokeRefreshTokenFamily("family"); err != nil {
+			t.Fatalf("RevokeRefreshTokenFamily() error: %v", err)
+		}
+		second, err := store.GetRefreshTokenByHash("hash-2")
+		if err != nil {
+			t.Fatalf("GetRefreshTokenByHash() error: %v", err)
+		}
+		if second.RevokedAt == nil {
+			t.Error("family revocation should revoke the remaining token")
+		}
+		for jti, want := range map[string]bool{"jti-1": true, "jti-2": false, "jti-expired": false} {
+			if revoked, err := store.IsAccessTokenRevoked(jti); err != nil || revoked != want {
+				t.Errorf("IsAccessTokenRevoked(%q) = %v, %v, want %v", jti, revoked, err, want)
+			}
+		}
+	})
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.RevokeAccessToken("jti", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("RevokeAccessToken() error: %v", err)
+		}
+		for jti, want := range map[string]bool{"jti": true, "other": false} {
+			if revoked, err := store.IsAccessTokenRevoked(jti); err != nil || revoked != want {
+				t.Errorf("IsAccessTokenRevoked(%q) = %v, %v, want %v", jti, revoked, err, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// AddVote adds one vote of a participant to a feedback entry within limits and
+// returns the entry's new total. Votes are counted per room under a row lock on
+// the room in PostgreSQL; SQLite serialises writers already.
+func (s *sqlStore) AddVote(feedbackID int, participantID string, limits models.VoteLimits) (int, error) {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var roomID string
+	err = tx.QueryRow(s.rebind(`SELECT room_id FROM feedback WHERE id = $1`), feedbackID).Scan(&roomID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrFeedbackNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query feedback: %w", err)
+	}
+	if s.driver == DriverPostgres {
+		if _, err := tx.Exec(`SELECT id FROM rooms WHERE id = $1 FOR UPDATE`, roomID); err != nil {
+			return 0, fmt.Errorf("failed to lock room: %w", err)
+		}
+	}
+
+	var onEntry, inRoom int
+	err = tx.QueryRow(s.rebind(
+		`SELECT COALESCE(SUM(CASE WHEN v.feedback_id = $1 THEN v.count END), 0), COALESCE(SUM(v.count), 0)
+		 FROM votes v JOIN feedback f ON f.id = v.feedback_id
+		 WHERE f.room_id = $2 AND v.participant_id = $3`),
+		feedbackID, roomID, participantID,
+	).Scan(&onEntry, &inRoom)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count votes: %w", err)
+	}
+	if (limits.PerEntry > 0 && onEntry >= limits.PerEntry) || (limits.PerRoom > 0 && inRoom >= limits.PerRoom) {
+		return 0, ErrVoteLimit
+	}
+
+	_, err = tx.Exec(s.rebind(
+		`INSERT INTO votes (feedback_id, participant_id, count) VALUES ($1, $2, 1)
+		 ON CONFLICT (feedback_id, participant_id) DO UPDATE SET count = votes.count + 1`),
+		feedbackID, participantID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert vote: %w", err)
+	}
+
+	total, err := s.addToVoteTotal(tx, feedbackID, 1)
+	if err != nil {
+		return 0, err
+	}
+	return total, tx.Commit()
+}
+
+// RemoveVote takes back one vote of a participant from a feedback entry and
+// returns the entry's new total. It returns ErrVoteNotFound when the
+// participant has no vote on the entry.
+func (s *sqlStore) RemoveVote(feedbackID int, participantID string) (int, error) {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(s.rebind(
+		`UPDATE votes SET count = count - 1 WHERE feedback_id = $1 AND participant_id = $2 AND count > 1`),
+		feedbackID, participantID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update vote: %w", err)
+	}
+	if err := expectAffected(result, ErrVoteNotFound); errors.Is(err, ErrVoteNotFound) {
+		result, err = tx.Exec(s.rebind(`DELETE FROM votes WHERE feedback_id = $1 AND participant_id = $2`),
+			feedbackID, participantID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete vote: %w", err)
+		}
+		if err := expectAffected(result, ErrVoteNotFound); err != nil {
+			return 0, err
+		}
+	} else if err != nil {
+		return 0, err
+	}
+
+	total, err := s.addToVoteTotal(tx, feedbackID, -1)
+	if err != nil {
+		return 0, err
+	}
+	return total, tx.Commit()
+}
+
+// addToVoteTotal adjusts the cached vote total of a feedback entry
+func (s *sqlStore) addToVoteTotal(tx *sql.Tx, feedbackID, delta int) (int, error) {
+	var total int
+	err := tx.QueryRow(s.rebind(`UPDATE feedback SET votes = votes + $1 WHERE id = $2 RETURNING votes`),
+		delta, feedbackID).Scan(&total)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrFeedbackNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to update vote total: %w", err)
+	}
+	return total, nil
+}
+
+// GetParticipantVotes returns the votes of a participant in a room per feedback entry
+func (s *sqlStore) GetParticipantVotes(roomID, participantID string) (map[int]int, error) {
+	rows, err := s.query(
+		`SELECT v.feedback_id, v.count FROM votes v JOIN feedback f ON f.id = v.feedback_id
+		 WHERE f.room_id = $1 AND v.participant_id = $2`,
+		roomID, participantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query votes: %w", err)
+	}
+	defer rows.Close()
+
+	votes := make(map[int]int)
+	for rows.Next() {
+		var feedbackID, count int
+		if err := rows.Scan(&feedbackID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan vote: %w", err)
+		}
+		votes[feedbackID] = count
+	}
+	return votes, rows.Err()
+}
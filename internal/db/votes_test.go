@@ -0,0 +1,60 @@
+package db
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestAddVoteLimits(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		room := createTestRoom(t, store, &models.Room{ID: "VOTES1", Type: models.RoomTypeRetro})
+		feedback := createTestFeedback(t, store, room.ID, "a", "b")
+		a, b := feedback[0].ID, feedback[1].ID
+		perRoom := models.VoteLimits{PerRoom: 2}
+
+		steps := []struct {
+			name        string
+			feedbackID  int
+			participant string
+			limits      models.VoteLimits
+			wantTotal   int
+			wantErr     error
+		}{
+			{"first dot", a, "p1", perRoom, 1, nil},
+			{"second dot on the same card", a, "p1", perRoom, 2, nil},
+			{"room budget spent", b, "p1", perRoom, 0, ErrVoteLimit},
+			{"other participant", a, "p2", perRoom, 3, nil},
+			{"one per entry", b, "p2", models.VoteLimits{PerEntry: 1}, 1, nil},
+			{"second on the entry", b, "p2", models.VoteLimits{PerEntry: 1}, 0, ErrVoteLimit},
+			{"unlimited", b, "p2", models.VoteLimits{}, 2, nil},
+			{"unknown entry", 9999, "p1", models.VoteLimits{}, 0, ErrFeedbackNotFound},
+		}
+		for _, step := range steps {
+			total, err := store.AddVote(step.feedbackID, step.participant, step.limits)
+			if !errors.Is(err, step.wantErr) || total != step.wantTotal {
+				t.Errorf("%s: AddVote() = %d, %v, want %d, %v", step.name, total, err, step.wantTotal, step.wantErr)
+			}
+		}
+
+		votes, err := store.GetParticipantVotes(room.ID, "p1")
+		if err != nil {
+			t.Fatalf("GetParticipantVotes() error: %v", err)
+		}
+		if want := map[int]int{a: 2}; !reflect.DeepEqual(votes, want) {
+			t.Errorf("GetParticipantVotes() = %v, want %v", votes, want)
+		}
+
+		if total, err := store.RemoveVote(a, "p1"); err != nil || total != 2 {
+			t.Errorf("RemoveVote() = %d, %v, want 2", total, err)
+		}
+		if total, err := store.AddVote(b, "p1", perRoom); err != nil || total != 3 {
+			t.Errorf("AddVote() after RemoveVote() = %d, %v, want 3", total, err)
+		}
+		if _, err := store.RemoveVote(a, "p3"); !errors.Is(err, ErrVoteNotFound) {
+			t.Errorf("RemoveVote() without a vote error = %v, want %v", err, ErrVoteNotFound)
+		}
+	})
+}
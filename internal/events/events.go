@@ -0,0 +1,78 @@
+// Package events provides a small in-process event bus used to announce domain
+// changes (rooms opening or closing, new feedback) to interested components.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	RoomOpened        = "room.opened"
+	RoomClosed        = "room.closed"
+	FeedbackCreated   = "feedback.created"
+	FeedbackSentiment = "feedback.sentiment"
+	FeedbackUpdated   = "feedback.updated"
+
+	// Live session events, sent to the room's WebSocket channel
+	Presence            = "presence"
+	SessionReveal       = "session.reveal"
+	SessionNextQuestion = "session.next_question"
+
+	// RetroUpdated tells clients of a retro room to reload the board. Its data
+	// holds the board's phase and what changed: phase, columns, groups or
+	// action_items.
+	RetroUpdated = "retro.updated"
+)
+
+// Event is a notification about a change to a room or its content
+type Event struct {
+	Type   string      `json:"type"`
+	RoomID string      `json:"room_id"`
+	Data   interface{} `json:"data,omitempty"`
+	Time   time.Time   `json:"time"`
+}
+
+// Publisher publishes events
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Handler receives published events. Handlers run synchronously on the
+// publishing goroutine and must not block.
+type Handler func(event Event)
+
+// Bus fans events out to every subscribed handler
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler for all future events
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish delivers an event to every handler, stamping it with the current time if unset
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
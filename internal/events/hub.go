@@ -0,0 +1,119 @@
+package events
+
+import "sync"
+
+// Hub fans published events out to subscribers of the event's room. Delivery
+// never blocks the publisher: a subscriber whose buffer is full is dropped and
+// must resubscribe, catching up from the database.
+type Hub struct {
+	mu     sync.Mutex
+	rooms  map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+// Subscription receives the events of one room until it is closed
+type Subscription struct {
+	hub    *Hub
+	roomID string
+	events chan Event
+	once   sync.Once
+}
+
+// NewHub creates a hub without subscribers
+func NewHub() *Hub {
+	return &Hub{rooms: make(map[string]map[*Subscription]struct{})}
+}
+
+// Subscribe registers a subscription for roomID buffering up to buffer events.
+// After Close the returned subscription is already closed.
+func (h *Hub) Subscribe(roomID string, buffer int) *Subscription {
+	sub := &Subscription{hub: h, roomID: roomID, events: make(chan Event, buffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		sub.once.Do(func() { close(sub.events) })
+		return sub
+	}
+	if h.rooms[roomID] == nil {
+		h.rooms[roomID] = make(map[*Subscription]struct{})
+	}
+	h.rooms[roomID][sub] = struct{}{}
+	return sub
+}
+
+// Publish delivers an event to the subscribers of its room. It has the
+// signature of a Handler so that the hub can be subscribed to a Bus.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.rooms[event.RoomID] {
+		select {
+		case sub.events <- event:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribers returns the number of open subscriptions to a room
+func (h *Hub) Subscribers(roomID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.rooms[roomID])
+}
+
+// Close closes every subscription and rejects new ones; used on shutdown so
+// that long-lived streams end
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.rooms {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// CloseSubscriptions closes every current subscription but keeps accepting new
+// ones; used when events may have been missed so that clients resume from the
+// database
+func (h *Hub) CloseSubscriptions() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.rooms {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove unregisters and closes a subscription; h.mu must be held
+func (h *Hub) remove(sub *Subscription) {
+	subs := h.rooms[sub.roomID]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.rooms, sub.roomID)
+	}
+	sub.once.Do(func() { close(sub.events) })
+}
+
+// Events returns the channel of delivered events. It is closed when the
+// subscription is closed or dropped for falling behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unsubscribes from the hub
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.remove(s)
+}
@@ -0,0 +1,59 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+)
+
+// utf8BOM makes Excel read the file as UTF-8 rather than the system code page
+const utf8BOM = "\ufeff"
+
+// csvSheet writes RFC 4180 CSV with a header row
+type csvSheet struct {
+	w *csv.Writer
+}
+
+func newCSVSheet(w io.Writer, header []string) (sheet, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	cs := &csvSheet{w: csv.NewWriter(w)}
+	if err := cs.w.Write(header); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// writeRow encodes one CSV record. Dates are written in RFC 3339.
+func (cs *csvSheet) writeRow(cells []cell) error {
+	record := make([]string, len(cells))
+	for i, c := range cells {
+		switch {
+		case c.date != nil:
+			record[i] = c.date.UTC().Format(time.RFC3339)
+		case c.number:
+			record[i] = c.value
+		default:
+			record[i] = escapeFormula(c.value)
+		}
+	}
+	return cs.w.Write(record)
+}
+
+// Close flushes buffered records
+func (cs *csvSheet) Close() error {
+	cs.w.Flush()
+	return cs.w.Error()
+}
+
+// escapeFormula prefixes text that a spreadsheet would evaluate as a formula
+// with an apostrophe, so that submitted content such as =HYPERLINK(...) is shown
+// as text when the file is opened
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
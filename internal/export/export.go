@@ -0,0 +1,207 @@
+// Package export encodes feedback as CSV, JSON Lines or XLSX. Writers encode
+// one entry at a time so that a room can be exported without holding all of
+// its feedback in memory.
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// Export formats
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+)
+
+// ErrUnknownFormat is returned by NewWriter for formats other than the above
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Writer encodes feedback entries to an underlying io.Writer
+type Writer interface {
+	// Write encodes one entry
+	Write(f *models.Feedback) error
+	// Close flushes buffered output and completes the file. It does not close
+	// the underlying writer.
+	Close() error
+}
+
+// Layout describes the columns that spreadsheet exports add for the room's
+// type: answers for surveys, the column, group and votes of retro cards, and
+// the state, pin and votes of Q&A questions
+type Layout struct {
+	Questions []models.Question
+	Columns   []models.RetroColumn // Retro rooms only
+	Groups    []models.CardGroup
+	QA        bool
+}
+
+// format describes how to encode one export format. JSON Lines has no sheet.
+type format struct {
+	contentType string
+	sheet       func(w io.Writer, header []string) (sheet, error)
+}
+
+var formats = map[string]format{
+	FormatCSV:   {"text/csv; charset=utf-8", newCSVSheet},
+	FormatJSONL: {"application/x-ndjson", nil},
+	FormatXLSX:  {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", newXLSXSheet},
+}
+
+// ContentType returns the MIME type of a format, reporting whether it is known
+func ContentType(name string) (string, bool) {
+	f, ok := formats[name]
+	return f.contentType, ok
+}
+
+// NewWriter returns a Writer encoding to w in the named format. Formats with a
+// header write it immediately. Spreadsheet formats add the columns of the
+// layout; JSON Lines carries entries as returned by the API.
+func NewWriter(name string, w io.Writer, layout Layout) (Writer, error) {
+	f, ok := formats[name]
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+	if f.sheet == nil {
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	}
+
+	survey, retro, qa := surveyColumns(layout.Questions), newRetroColumns(layout), qaColumns(layout.QA)
+	header := append([]string{}, columns...)
+	header = append(header, survey.header()...)
+	header = append(header, retro.header()...)
+	header = append(header, qa.header()...)
+	s, err := f.sheet(w, header)
+	if err != nil {
+		return nil, err
+	}
+	return &sheetWriter{sheet: s, survey: survey, retro: retro, qa: qa}, nil
+}
+
+// columns are the fields exported to spreadsheet formats, in order
+var columns = []string{"id", "created_at", "status", "sentiment", "sentiment_score", "tags", "content"}
+
+// sheetWriter writes feedback entries as spreadsheet rows
+type sheetWriter struct {
+	sheet  sheet
+	survey surveyColumns
+	retro  *retroColumns
+	qa     qaColumns
+}
+
+// Write encodes one entry as a row
+func (sw *sheetWriter) Write(f *models.Feedback) error {
+	row := []cell{
+		numberCell(strconv.Itoa(f.ID)),
+		dateCell(f.CreatedAt),
+		textCell(string(f.Status)),
+		textCell(f.Sentiment),
+		numberCell(formatScore(f)),
+		textCell(formatTags(f)),
+		textCell(f.Content),
+	}
+	row = append(row, sw.survey.cells(f)...)
+	row = append(row, sw.retro.cells(f)...)
+	row = append(row, sw.qa.cells(f)...)
+	return sw.sheet.writeRow(row)
+}
+
+// Close completes the file
+func (sw *sheetWriter) Close() error {
+	return sw.sheet.Close()
+}
+
+// WriteActionItems encodes the action items of a retro room in the named format
+func WriteActionItems(name string, w io.Writer, items []models.ActionItem) error {
+	f, ok := formats[name]
+	if !ok {
+		return ErrUnknownFormat
+	}
+	if f.sheet == nil {
+		enc := json.NewEncoder(w)
+		for i := range items {
+			if err := enc.Encode(&items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	s, err := f.sheet(w, []string{"id", "created_at", "title", "owner", "done", "feedback_id"})
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		feedbackID := ""
+		if item.FeedbackID != nil {
+			feedbackID = strconv.Itoa(*item.FeedbackID)
+		}
+		err := s.writeRow([]cell{
+			numberCell(strconv.Itoa(item.ID)),
+			dateCell(item.CreatedAt),
+			textCell(item.Title),
+			textCell(item.Owner),
+			textCell(formatYesNo(item.Done)),
+			numberCell(feedbackID),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.Close()
+}
+
+// sheet writes rows of cells in a spreadsheet format
+type sheet interface {
+	writeRow(cells []cell) error
+	// Close flushes buffered rows and completes the file
+	Close() error
+}
+
+// cell is one value of a spreadsheet row
+type cell struct {
+	value  string // Text, or a formatted number
+	number bool
+	date   *time.Time
+}
+
+func textCell(value string) cell {
+	return cell{value: value}
+}
+
+// numberCell holds a formatted number; an empty value leaves the cell empty
+func numberCell(value string) cell {
+	return cell{value: value, number: true}
+}
+
+func dateCell(t time.Time) cell {
+	return cell{date: &t}
+}
+
+// formatScore renders a sentiment score, or an empty string before analysis
+func formatScore(f *models.Feedback) string {
+	if f.SentimentScore == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f.SentimentScore, 'f', -1, 64)
+}
+
+// formatTags joins tags into a single cell
+func formatTags(f *models.Feedback) string {
+	return strings.Join(f.Tags, ", ")
+}
+
+// formatYesNo renders a boolean for spreadsheets
+func formatYesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
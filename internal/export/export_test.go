@@ -0,0 +1,129 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"plain text", "plain text"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tindented", "'\tindented"},
+		{"\rreturn", "'\rreturn"},
+		{"a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// testFeedback returns two entries, one scored and tagged and one pending
+func testFeedback() []models.Feedback {
+	score := 0.5
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []models.Feedback{
+		{ID: 1, Content: "=cmd", Sentiment: "positive", SentimentScore: &score, Status: models.FeedbackStatusNew,
+			Tags: []string{"bug", "ui"}, CreatedAt: createdAt},
+		{ID: 2, Content: "line one\nline \"two\"", Sentiment: "pending", Status: models.FeedbackStatusReviewed,
+			CreatedAt: createdAt.Add(time.Hour)},
+	}
+}
+
+func writeAll(t *testing.T, format string, layout Layout) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, layout)
+	if err != nil {
+		t.Fatalf("NewWriter(%q) error: %v", format, err)
+	}
+	for _, f := range testFeedback() {
+		f := f
+		if err := w.Write(&f); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	got := string(writeAll(t, FormatCSV, Layout{}))
+	want := utf8BOM +
+		"id,created_at,status,sentiment,sentiment_score,tags,content\n" +
+		"1,2026-03-01T12:00:00Z,new,positive,0.5,\"bug, ui\",'=cmd\n" +
+		"2,2026-03-01T13:00:00Z,reviewed,pending,,,\"line one\nline \"\"two\"\"\"\n"
+	if got != want {
+		t.Errorf("CSV output:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestJSONLWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(string(writeAll(t, FormatJSONL, Layout{})), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	for i, line := range lines {
+		var f models.Feedback
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			t.Fatalf("line %d: %v", i+1, err)
+		}
+		if want := testFeedback()[i]; f.ID != want.ID || f.Content != want.Content {
+			t.Errorf("line %d = %+v, want entry %d", i+1, f, want.ID)
+		}
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	data := writeAll(t, FormatXLSX, Layout{})
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+
+	var sheet string
+	for _, file := range archive.File {
+		if strings.HasPrefix(file.Name, "xl/worksheets/") {
+			r, err := file.Open()
+			if err != nil {
+				t.Fatalf("failed to open %s: %v", file.Name, err)
+			}
+			content, _ := io.ReadAll(r)
+			r.Close()
+			sheet = string(content)
+		}
+	}
+	for _, want := range []string{"sentiment_score", "=cmd", "line &#34;two&#34;"} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("worksheet does not contain %q", want)
+		}
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	if _, err := NewWriter("pdf", io.Discard, Layout{}); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("NewWriter(pdf) error = %v, want %v", err, ErrUnknownFormat)
+	}
+	if _, ok := ContentType("pdf"); ok {
+		t.Error("ContentType(pdf) should not be known")
+	}
+}
@@ -0,0 +1,22 @@
+package export
+
+import (
+	"encoding/json"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// jsonlWriter writes one JSON object per line, as returned by the API
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+// Write encodes one entry as a line of JSON
+func (jw *jsonlWriter) Write(f *models.Feedback) error {
+	return jw.enc.Encode(f)
+}
+
+// Close does nothing; every line is written as soon as it is encoded
+func (jw *jsonlWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,25 @@
+package export
+
+import (
+	"strconv"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// qaColumns adds the state, pin and votes of questions to spreadsheet exports
+// of Q&A rooms when true
+type qaColumns bool
+
+func (qc qaColumns) header() []string {
+	if !qc {
+		return nil
+	}
+	return []string{"qa_state", "pinned", "votes"}
+}
+
+func (qc qaColumns) cells(f *models.Feedback) []cell {
+	if !qc {
+		return nil
+	}
+	return []cell{textCell(string(f.QAState)), textCell(formatYesNo(f.Pinned)), numberCell(strconv.Itoa(f.Votes))}
+}
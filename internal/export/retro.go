@@ -0,0 +1,51 @@
+package export
+
+import (
+	"strconv"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// retroColumns are the column, group and votes of cards that follow the other
+// columns in spreadsheet exports of retro rooms. A nil *retroColumns adds none.
+type retroColumns struct {
+	columns map[int]string // Titles by ID
+	groups  map[int]string
+}
+
+// newRetroColumns returns the retro columns of a layout, or nil for other rooms
+func newRetroColumns(layout Layout) *retroColumns {
+	if len(layout.Columns) == 0 {
+		return nil
+	}
+	rc := &retroColumns{columns: make(map[int]string), groups: make(map[int]string)}
+	for _, c := range layout.Columns {
+		rc.columns[c.ID] = c.Title
+	}
+	for _, g := range layout.Groups {
+		rc.groups[g.ID] = g.Title
+	}
+	return rc
+}
+
+func (rc *retroColumns) header() []string {
+	if rc == nil {
+		return nil
+	}
+	return []string{"column", "group", "votes"}
+}
+
+// cells renders the column and group titles and the vote total of a card
+func (rc *retroColumns) cells(f *models.Feedback) []cell {
+	if rc == nil {
+		return nil
+	}
+	column, group := "", ""
+	if f.ColumnID != nil {
+		column = rc.columns[*f.ColumnID]
+	}
+	if f.GroupID != nil {
+		group = rc.groups[*f.GroupID]
+	}
+	return []cell{textCell(column), textCell(group), numberCell(strconv.Itoa(f.Votes))}
+}
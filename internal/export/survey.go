@@ -0,0 +1,82 @@
+package export
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// surveyColumns are the answer columns that follow the fixed columns in
+// spreadsheet exports of survey rooms: one per question, with the NPS group of
+// NPS answers and whether rating answers count as satisfied for CSAT alongside
+type surveyColumns []models.Question
+
+// header names the answer columns after the question prompts
+func (sc surveyColumns) header() []string {
+	names := []string{}
+	for _, q := range sc {
+		names = append(names, q.Prompt)
+		switch q.Type {
+		case models.QuestionNPS:
+			names = append(names, q.Prompt+" (NPS group)")
+		case models.QuestionRating:
+			names = append(names, q.Prompt+" (CSAT)")
+		}
+	}
+	return names
+}
+
+// cells renders the answers of an entry, leaving unanswered questions empty
+func (sc surveyColumns) cells(f *models.Feedback) []cell {
+	answers := make(map[int]json.RawMessage, len(f.Answers))
+	for _, a := range f.Answers {
+		answers[a.QuestionID] = a.Value
+	}
+
+	cells := []cell{}
+	for _, q := range sc {
+		raw, answered := answers[q.ID]
+		switch q.Type {
+		case models.QuestionNPS, models.QuestionRating:
+			var value int
+			if !answered || json.Unmarshal(raw, &value) != nil {
+				cells = append(cells, textCell(""), textCell(""))
+				continue
+			}
+			derived := models.NPSGroup(value)
+			if q.Type == models.QuestionRating {
+				derived = "not satisfied"
+				if models.CSATSatisfied(value) {
+					derived = "satisfied"
+				}
+			}
+			cells = append(cells, numberCell(strconv.Itoa(value)), textCell(derived))
+		default:
+			cells = append(cells, textCell(formatAnswer(q.Type, raw)))
+		}
+	}
+	return cells
+}
+
+// formatAnswer renders a text, choice or yes/no answer as a single string
+func formatAnswer(t models.QuestionType, raw json.RawMessage) string {
+	if raw == nil {
+		return ""
+	}
+	switch t {
+	case models.QuestionMultipleChoice:
+		var choices []string
+		json.Unmarshal(raw, &choices)
+		return strings.Join(choices, ", ")
+	case models.QuestionYesNo:
+		var yes bool
+		json.Unmarshal(raw, &yes)
+		return formatYesNo(yes)
+	default:
+		var text string
+		json.Unmarshal(raw, &text)
+		return text
+	}
+}
@@ -0,0 +1,146 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// An XLSX file is a zip archive of SpreadsheetML parts. The parts other than the
+// worksheet are fixed, and the worksheet uses inline strings rather than a
+// shared string table, so rows can be written as they arrive.
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Feedback" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	// Cell styles: 0 is the default, 1 a date and time, 2 a bold header
+	{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+const (
+	xlsxStyleDate   = 1
+	xlsxStyleHeader = 2
+
+	// xlsxMaxCellLength is the most characters a spreadsheet cell may hold
+	xlsxMaxCellLength = 32767
+)
+
+// excelEpoch is day zero of spreadsheet date serial numbers
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxSheet writes a single-sheet workbook
+type xlsxSheet struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+}
+
+func newXLSXSheet(w io.Writer, header []string) (sheet, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		fw, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// The worksheet must be the last part: zip entries cannot be interleaved
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxSheet{zip: zw, sheet: bufio.NewWriter(fw)}
+	xw.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData><row>`)
+	for _, column := range header {
+		xw.stringCell(column, xlsxStyleHeader)
+	}
+	xw.sheet.WriteString(`</row>`)
+	return xw, nil
+}
+
+// writeRow encodes one row. Dates become date serial numbers in UTC.
+func (xw *xlsxSheet) writeRow(cells []cell) error {
+	xw.sheet.WriteString(`<row>`)
+	for _, c := range cells {
+		switch {
+		case c.date != nil:
+			xw.numberCell(strconv.FormatFloat(excelDate(*c.date), 'f', -1, 64), xlsxStyleDate)
+		case c.value == "":
+			xw.sheet.WriteString(`<c/>`)
+		case c.number:
+			xw.numberCell(c.value, 0)
+		default:
+			xw.stringCell(c.value, 0)
+		}
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+// Close ends the worksheet and writes the zip directory
+func (xw *xlsxSheet) Close() error {
+	xw.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zip.Close()
+}
+
+// numberCell writes a numeric cell. Errors are reported by the next writeRow.
+func (xw *xlsxSheet) numberCell(value string, style int) {
+	xw.sheet.WriteString(`<c`)
+	if style != 0 {
+		xw.sheet.WriteString(` s="` + strconv.Itoa(style) + `"`)
+	}
+	xw.sheet.WriteString(`><v>` + value + `</v></c>`)
+}
+
+// stringCell writes an inline string cell. Inline strings are never evaluated
+// as formulas, so content needs no escaping beyond XML.
+func (xw *xlsxSheet) stringCell(value string, style int) {
+	if utf8.RuneCountInString(value) > xlsxMaxCellLength {
+		value = string([]rune(value)[:xlsxMaxCellLength])
+	}
+	xw.sheet.WriteString(`<c t="inlineStr"`)
+	if style != 0 {
+		xw.sheet.WriteString(` s="` + strconv.Itoa(style) + `"`)
+	}
+	xw.sheet.WriteString(`><is><t xml:space="preserve">`)
+	xml.EscapeText(xw.sheet, []byte(value))
+	xw.sheet.WriteString(`</t></is></c>`)
+}
+
+// excelDate converts t to a date serial number in UTC
+func excelDate(t time.Time) float64 {
+	return t.UTC().Sub(excelEpoch).Hours() / 24
+}
@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// readCSV reads a CSV file with a header row naming its columns. Only content
+// is required; tags are separated by commas.
+func readCSV(r *bufio.Reader, emit func(*record) error) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["content"]; !ok {
+		return errors.New("CSV header has no content column")
+	}
+
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		rec := &record{row: line}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(fields) {
+				return fields[i]
+			}
+			return ""
+		}
+		if len(fields) != len(header) {
+			rec.malformed = true
+			rec.errs = append(rec.errs, models.ImportRowError{
+				Row:   line,
+				Error: fmt.Sprintf("expected %d fields, found %d", len(header), len(fields)),
+			})
+		}
+
+		rec.content = unescapeFormula(field("content"))
+		rec.createdAt = field("created_at")
+		rec.status = field("status")
+		rec.sentiment = field("sentiment")
+		if value := strings.TrimSpace(field("sentiment_score")); value != "" {
+			score, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				rec.errs = append(rec.errs, models.ImportRowError{Row: line, Field: "sentiment_score", Error: "sentiment_score must be a number"})
+			} else {
+				rec.score = &score
+			}
+		}
+		for _, tag := range strings.Split(unescapeFormula(field("tags")), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				rec.tags = append(rec.tags, tag)
+			}
+		}
+
+		if err := emit(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// unescapeFormula removes the apostrophe the CSV export puts before text that
+// a spreadsheet would evaluate as a formula
+func unescapeFormula(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
@@ -0,0 +1,188 @@
+// Package importer reads feedback from CSV and JSON files for bulk import. The
+// CSV columns and JSON fields match those written by the export package, so an
+// export can be imported again; unknown columns such as id are ignored.
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+	"github.com/panaalexandrucristian/feedback-collector/internal/sentiment"
+)
+
+// Import formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json" // An array of objects, or one object per line
+)
+
+var (
+	// ErrUnknownFormat is returned by Import for formats other than the above
+	ErrUnknownFormat = errors.New("unknown import format")
+	// ErrTooManyRows is returned by Import for files over models.MaxImportRows rows
+	ErrTooManyRows = fmt.Errorf("import is limited to %d rows", models.MaxImportRows)
+	// ErrInvalidFile wraps the errors of files that cannot be read as the format
+	ErrInvalidFile = errors.New("invalid import file")
+)
+
+// timeLayouts are the accepted created_at formats; those without a zone are UTC
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// maxImportTags matches the tag limit of UpdateFeedbackRequest
+const maxImportTags = 20
+
+// record is a row as read from a file, before validation
+type record struct {
+	row       int
+	content   string
+	createdAt string
+	status    string
+	sentiment string
+	score     *float64
+	tags      []string
+	errs      []models.ImportRowError // Problems found while reading the row
+	malformed bool                    // The fields could not be read; skip validation
+}
+
+// Store inserts validated entries
+type Store interface {
+	ImportFeedback(roomID string, entries []models.FeedbackImport, dryRun bool) ([]models.Feedback, error)
+}
+
+// Import reads r and, if every row is valid, imports it into a room in a single
+// transaction, returning the stored entries. Otherwise the problems are listed
+// in the result and nothing is stored. A dry run validates and inserts without
+// committing. Entries without a timestamp are given now. Files that cannot be
+// read as the format yield an error wrapping ErrInvalidFile.
+func Import(store Store, roomID, format string, r io.Reader, dryRun bool, now time.Time) (*models.ImportResult, []models.Feedback, error) {
+	var read func(r *bufio.Reader, emit func(*record) error) error
+	switch format {
+	case FormatCSV:
+		read = readCSV
+	case FormatJSON:
+		read = readJSON
+	default:
+		return nil, nil, ErrUnknownFormat
+	}
+
+	result := &models.ImportResult{DryRun: dryRun, Errors: []models.ImportRowError{}}
+	entries := []models.FeedbackImport{}
+	err := read(bufio.NewReader(r), func(rec *record) error {
+		if result.Rows++; result.Rows > models.MaxImportRows {
+			return ErrTooManyRows
+		}
+		entry, errs := validate(rec, now)
+		if len(errs) > 0 {
+			result.Errors = append(result.Errors, errs...)
+			return nil
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if errors.Is(err, ErrTooManyRows) {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	if len(result.Errors) > 0 {
+		return result, nil, nil
+	}
+
+	imported, err := store.ImportFeedback(roomID, entries, dryRun)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.Imported = len(imported)
+	return result, imported, nil
+}
+
+// validate checks a record and converts it to an entry
+func validate(rec *record, now time.Time) (models.FeedbackImport, []models.ImportRowError) {
+	errs := rec.errs
+	if rec.malformed {
+		return models.FeedbackImport{}, errs
+	}
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, models.ImportRowError{Row: rec.row, Field: field, Error: fmt.Sprintf(format, args...)})
+	}
+
+	entry := models.FeedbackImport{
+		Content:        strings.TrimSpace(rec.content),
+		Status:         models.FeedbackStatus(strings.TrimSpace(rec.status)),
+		Sentiment:      strings.TrimSpace(rec.sentiment),
+		SentimentScore: rec.score,
+		CreatedAt:      now,
+	}
+	if entry.Content == "" {
+		fail("content", "content is required")
+	}
+
+	if value := strings.TrimSpace(rec.createdAt); value != "" {
+		createdAt, err := parseTime(value)
+		switch {
+		case err != nil:
+			fail("created_at", "unrecognised timestamp %q; use RFC 3339", value)
+		case createdAt.After(now):
+			fail("created_at", "timestamp is in the future")
+		default:
+			entry.CreatedAt = createdAt
+		}
+	}
+
+	if entry.Status == "" {
+		entry.Status = models.FeedbackStatusNew
+	} else if !entry.Status.Valid() {
+		fail("status", "status must be new, reviewed or archived")
+	}
+
+	tags, err := models.NormalizeTags(rec.tags)
+	switch {
+	case err != nil:
+		fail("tags", "%v", err)
+	case len(tags) > maxImportTags:
+		fail("tags", "at most %d tags are allowed", maxImportTags)
+	default:
+		entry.Tags = tags
+	}
+
+	switch entry.Sentiment {
+	case "", sentiment.Pending:
+		entry.Sentiment = sentiment.Pending
+		if entry.SentimentScore != nil {
+			fail("sentiment_score", "sentiment_score requires a sentiment")
+		}
+	case sentiment.Positive, sentiment.Neutral, sentiment.Negative:
+		if entry.SentimentScore == nil {
+			fail("sentiment_score", "sentiment_score is required with a sentiment")
+		} else if *entry.SentimentScore < -1 || *entry.SentimentScore > 1 {
+			fail("sentiment_score", "sentiment_score must be between -1 and 1")
+		}
+	default:
+		fail("sentiment", "sentiment must be positive, neutral, negative or pending")
+	}
+
+	return entry, errs
+}
+
+// parseTime parses a timestamp in any of timeLayouts
+func parseTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
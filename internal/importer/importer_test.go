@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// fakeStore records the entries passed to ImportFeedback
+type fakeStore struct {
+	calls   int
+	entries []models.FeedbackImport
+	dryRun  bool
+}
+
+func (s *fakeStore) ImportFeedback(roomID string, entries []models.FeedbackImport, dryRun bool) ([]models.Feedback, error) {
+	s.calls++
+	s.entries, s.dryRun = entries, dryRun
+	imported := make([]models.Feedback, len(entries))
+	for i, entry := range entries {
+		imported[i] = models.Feedback{ID: i + 1, RoomID: roomID, Content: entry.Content}
+	}
+	return imported, nil
+}
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestImportValidFiles(t *testing.T) {
+	score := 0.5
+	want := []models.FeedbackImport{
+		{Content: "=SUM(A1)", Status: models.FeedbackStatusReviewed, Tags: []string{"bug", "ui"},
+			Sentiment: "positive", SentimentScore: &score, CreatedAt: time.Date(2026, 2, 1, 9, 30, 0, 0, time.UTC)},
+		{Content: "No extras", Status: models.FeedbackStatusNew, Tags: []string{}, Sentiment: "pending", CreatedAt: testNow},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		file   string
+	}{
+		{"csv export", FormatCSV, "\ufeffid,created_at,status,sentiment,sentiment_score,tags,content\n" +
+			"1,2026-02-01T09:30:00Z,reviewed,positive,0.5,\"bug, UI\",'=SUM(A1)\n" +
+			"2,,,,,,No extras\n"},
+		{"json array", FormatJSON, `[
+			{"content": "=SUM(A1)", "created_at": "2026-02-01 09:30:00", "status": "reviewed",
+			 "sentiment": "positive", "sentiment_score": 0.5, "tags": ["bug", "ui"]},
+			{"content": "No extras"}
+		]`},
+		{"json lines", FormatJSON, `{"content": "=SUM(A1)", "created_at": "2026-02-01T10:30:00+01:00", "status": "reviewed", "sentiment": "positive", "sentiment_score": 0.5, "tags": ["ui", "bug"]}
+{"content": "  No extras  "}
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{}
+			result, imported, err := Import(store, "ROOM01", tt.format, strings.NewReader(tt.file), true, testNow)
+			if err != nil {
+				t.Fatalf("Import() error: %v", err)
+			}
+			if result.Rows != 2 || result.Imported != 2 || len(result.Errors) != 0 || !result.DryRun || len(imported) != 2 {
+				t.Fatalf("Import() = %+v", result)
+			}
+			if !store.dryRun {
+				t.Error("dry run should be passed to the store")
+			}
+			for i := range want {
+				got := store.entries[i]
+				got.CreatedAt = got.CreatedAt.UTC()
+				if !reflect.DeepEqual(got, want[i]) {
+					t.Errorf("entry %d = %+v, want %+v", i+1, got, want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestImportRejectsInvalidRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		file   string
+		want   []models.ImportRowError
+	}{
+		{"missing content", FormatCSV, "content,status\nok,new\n,new\n", []models.ImportRowError{
+			{Row: 3, Field: "content", Error: "content is required"},
+		}},
+		{"bad fields", FormatCSV, "content,status,created_at\nok,done,2030-01-01\n", []models.ImportRowError{
+			{Row: 2, Field: "created_at", Error: "timestamp is in the future"},
+			{Row: 2, Field: "status", Error: "status must be new, reviewed or archived"},
+		}},
+		{"wrong field count", FormatCSV, "content,status\nok,new,extra\n", []models.ImportRowError{
+			{Row: 2, Error: "expected 2 fields, found 3"},
+		}},
+		{"score without sentiment", FormatJSON, `[{"content": "ok", "sentiment_score": 0.2}]`, []models.ImportRowError{
+			{Row: 1, Field: "sentiment_score", Error: "sentiment_score requires a sentiment"},
+		}},
+		{"wrong type", FormatJSON, `{"content": "ok"}
+{"content": 3}`, []models.ImportRowError{
+			{Row: 2, Error: "row must be an object with content, created_at, status, sentiment, sentiment_score and tags"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{}
+			result, imported, err := Import(store, "ROOM01", tt.format, strings.NewReader(tt.file), false, testNow)
+			if err != nil {
+				t.Fatalf("Import() error: %v", err)
+			}
+			if store.calls != 0 || imported != nil || result.Imported != 0 {
+				t.Error("nothing should be imported when a row is invalid")
+			}
+			if !reflect.DeepEqual(result.Errors, tt.want) {
+				t.Errorf("Errors = %+v, want %+v", result.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportRejectsFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		file   string
+		want   error
+	}{
+		{"unknown format", "xml", "<feedback/>", ErrUnknownFormat},
+		{"no content column", FormatCSV, "id,status\n1,new\n", ErrInvalidFile},
+		{"broken json", FormatJSON, `[{"content": "ok"`, ErrInvalidFile},
+		{"too many rows", FormatCSV, "content\n" + strings.Repeat("row\n", models.MaxImportRows+1), ErrTooManyRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{}
+			_, _, err := Import(store, "ROOM01", tt.format, strings.NewReader(tt.file), false, testNow)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Import() error = %v, want %v", err, tt.want)
+			}
+			if store.calls != 0 {
+				t.Error("nothing should be imported from a rejected file")
+			}
+		})
+	}
+}
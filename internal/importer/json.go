@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// jsonRow holds the fields read from an imported JSON object
+type jsonRow struct {
+	Content        string   `json:"content"`
+	CreatedAt      string   `json:"created_at"`
+	Status         string   `json:"status"`
+	Sentiment      string   `json:"sentiment"`
+	SentimentScore *float64 `json:"sentiment_score"`
+	Tags           []string `json:"tags"`
+}
+
+// readJSON reads either an array of objects or a sequence of objects such as
+// JSON Lines. Objects with fields of the wrong type are reported as row errors.
+func readJSON(r *bufio.Reader, emit func(*record) error) error {
+	dec := json.NewDecoder(r)
+	array, err := startsWith(r, '[')
+	if err != nil {
+		return err
+	}
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("failed to read JSON: %w", err)
+		}
+	}
+
+	for row := 1; ; row++ {
+		if array && !dec.More() {
+			break
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if !array && errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read JSON: %w", err)
+		}
+
+		rec := &record{row: row}
+		var fields jsonRow
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			rec.malformed = true
+			rec.errs = append(rec.errs, models.ImportRowError{Row: row, Error: "row must be an object with content, created_at, status, sentiment, sentiment_score and tags"})
+		}
+		rec.content = fields.Content
+		rec.createdAt = fields.CreatedAt
+		rec.status = fields.Status
+		rec.sentiment = fields.Sentiment
+		rec.score = fields.SentimentScore
+		rec.tags = fields.Tags
+
+		if err := emit(rec); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("failed to read JSON: %w", err)
+	}
+	return nil
+}
+
+// startsWith reports whether the first non-space byte of r is b, without consuming it
+func startsWith(r *bufio.Reader, b byte) (bool, error) {
+	for {
+		c, err := r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return c == b, r.UnreadByte()
+	}
+}
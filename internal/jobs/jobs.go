@@ -0,0 +1,237 @@
+// Package jobs runs background work from a durable, database-backed queue.
+//
+// Jobs are leased rather than popped: a worker claims a job for LeaseDuration
+// and must complete, retry or bury it before the lease expires. A job whose
+// worker crashed is claimed again once its lease runs out, so handlers must be
+// safe to run more than once.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+const (
+	// DefaultMaxAttempts is how many times a job runs before it is dead-lettered
+	DefaultMaxAttempts = 5
+	// LeaseDuration is how long a worker holds a job before others may claim it
+	LeaseDuration = 5 * time.Minute
+
+	// Retry delays grow exponentially from baseBackoff up to maxBackoff
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// Store persists jobs; it is implemented by db.Store
+type Store interface {
+	EnqueueJob(kind string, payload []byte, runAt time.Time, maxAttempts int) (*models.Job, error)
+	LeaseJobs(now time.Time, lease time.Duration, limit int) ([]models.Job, error)
+	CompleteJob(id int64, attempt int) error
+	RetryJob(id int64, attempt int, runAt time.Time, lastError string) error
+	BuryJob(id int64, attempt int, lastError string) error
+}
+
+// Handler processes one job. Returning an error schedules a retry, or moves the
+// job to the dead-letter state once it has used all of its attempts.
+type Handler func(ctx context.Context, job *models.Job) error
+
+// Queue enqueues jobs and runs them on a pool of workers
+type Queue struct {
+	store        Store
+	workers      int
+	pollInterval time.Duration
+	handlers     map[string]Handler
+
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// New creates a queue with the given number of workers. Idle workers poll the
+// store every pollInterval; jobs enqueued by this process wake them immediately.
+func New(store Store, workers int, pollInterval time.Duration) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		store:        store,
+		workers:      workers,
+		pollInterval: pollInterval,
+		handlers:     make(map[string]Handler),
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Register sets the handler for a job kind. It must be called before Start.
+func (q *Queue) Register(kind string, handler Handler) {
+	q.handlers[kind] = handler
+}
+
+// Enqueue stores a job of the given kind with a JSON-encoded payload, ready to run now
+func (q *Queue) Enqueue(kind string, payload interface{}) (*models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", kind, err)
+	}
+
+	job, err := q.store.EnqueueJob(kind, data, time.Now(), DefaultMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Start launches the workers
+func (q *Queue) Start() {
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+}
+
+// Stop stops leasing new jobs and waits for running ones to finish. If ctx
+// expires first the running handlers are cancelled and ctx's error is returned;
+// their jobs are retried by the next process once the lease runs out.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.stopOnce.Do(func() { close(q.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work leases and runs one job at a time until the queue is stopped
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-timer.C:
+		}
+
+		// Drain the ready jobs before going back to sleep
+		for q.runNext() {
+			select {
+			case <-q.stop:
+				return
+			default:
+			}
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(q.pollInterval)
+	}
+}
+
+// runNext leases and runs a single job, reporting whether one was found
+func (q *Queue) runNext() bool {
+	leased, err := q.store.LeaseJobs(time.Now(), LeaseDuration, 1)
+	if err != nil {
+		log.Printf("Failed to lease jobs: %v\n", err)
+		return false
+	}
+	if len(leased) == 0 {
+		return false
+	}
+
+	job := &leased[0]
+	q.finish(job, q.run(job))
+	return true
+}
+
+// run calls the job's handler, converting panics into errors
+func (q *Queue) run(job *models.Job) (err error) {
+	// A lease that expired repeatedly means the job keeps killing its worker
+	if job.Attempts > job.MaxAttempts {
+		return fmt.Errorf("lease expired after %d attempts", job.MaxAttempts)
+	}
+
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler registered for job kind %q", job.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(q.ctx, LeaseDuration)
+	defer cancel()
+	return handler(ctx, job)
+}
+
+// finish records the outcome of a job run
+func (q *Queue) finish(job *models.Job, runErr error) {
+	var err error
+	switch {
+	case runErr == nil:
+		err = q.store.CompleteJob(job.ID, job.Attempts)
+	case job.Attempts >= job.MaxAttempts:
+		log.Printf("Job %d (%s) failed permanently after %d attempts: %v\n", job.ID, job.Kind, job.Attempts, runErr)
+		err = q.store.BuryJob(job.ID, job.Attempts, runErr.Error())
+	default:
+		delay := backoff(job.Attempts)
+		log.Printf("Job %d (%s) failed on attempt %d, retrying in %s: %v\n", job.ID, job.Kind, job.Attempts, delay, runErr)
+		err = q.store.RetryJob(job.ID, job.Attempts, time.Now().Add(delay), runErr.Error())
+	}
+
+	if err != nil {
+		log.Printf("Failed to record outcome of job %d: %v\n", job.ID, err)
+	}
+}
+
+// backoff returns the delay before retrying after the given attempt, doubling
+// each time with up to 20% jitter so that failing jobs do not retry in lockstep
+func backoff(attempt int) time.Duration {
+	delay := maxBackoff
+	if attempt < 20 {
+		if d := baseBackoff << (attempt - 1); d < maxBackoff {
+			delay = d
+		}
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
@@ -0,0 +1,247 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// forEachStore runs test against a fresh SQLite store and a fresh in-memory store
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	t.Helper()
+
+	t.Run(db.DriverSQLite, func(t *testing.T) {
+		store, err := db.NewSQLite("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("NewSQLite() error: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		test(t, store)
+	})
+	t.Run(db.DriverMemory, func(t *testing.T) {
+		store := db.NewMemory()
+		t.Cleanup(func() { store.Close() })
+		test(t, store)
+	})
+}
+
+// lease leases every job that is ready at now
+func lease(t *testing.T, store Store, now time.Time) []models.Job {
+	t.Helper()
+	leased, err := store.LeaseJobs(now, LeaseDuration, 10)
+	if err != nil {
+		t.Fatalf("LeaseJobs() error: %v", err)
+	}
+	return leased
+}
+
+// runQueue runs a queue with handler until it has been called calls times
+func runQueue(t *testing.T, store Store, calls int, handler Handler) {
+	t.Helper()
+
+	called := make(chan struct{}, calls)
+	q := New(store, 1, 10*time.Millisecond)
+	q.Register("test", func(ctx context.Context, job *models.Job) error {
+		defer func() { called <- struct{}{} }()
+		return handler(ctx, job)
+	})
+	q.Start()
+	for i := 0; i < calls; i++ {
+		select {
+		case <-called:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("handler was called %d times, want %d", i, calls)
+		}
+	}
+	if err := q.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+}
+
+func TestLeaseJobs(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		now := time.Now()
+		job, err := store.EnqueueJob("test", []byte(`{}`), now, DefaultMaxAttempts)
+		if err != nil {
+			t.Fatalf("EnqueueJob() error: %v", err)
+		}
+
+		first := lease(t, store, now)
+		if len(first) != 1 || first[0].ID != job.ID || first[0].Attempts != 1 {
+			t.Fatalf("first lease = %+v, want job %d on attempt 1", first, job.ID)
+		}
+		if second := lease(t, store, now.Add(time.Minute)); len(second) != 0 {
+			t.Errorf("second lease = %+v, want the leased job to be skipped", second)
+		}
+
+		// The first worker crashed: once its lease expires the job runs again
+		expired := lease(t, store, now.Add(LeaseDuration+time.Second))
+		if len(expired) != 1 || expired[0].Attempts != 2 {
+			t.Fatalf("lease after expiry = %+v, want the job on attempt 2", expired)
+		}
+
+		// The stale worker cannot complete the job for the current attempt
+		if err := store.CompleteJob(job.ID, 1); err != nil {
+			t.Fatalf("CompleteJob() error: %v", err)
+		}
+		if again := lease(t, store, now.Add(2*LeaseDuration+2*time.Second)); len(again) != 1 || again[0].Attempts != 3 {
+			t.Fatalf("lease after a stale completion = %+v, want the job on attempt 3", again)
+		}
+		if err := store.CompleteJob(job.ID, 3); err != nil {
+			t.Fatalf("CompleteJob() error: %v", err)
+		}
+		if done := lease(t, store, now.Add(24*time.Hour)); len(done) != 0 {
+			t.Errorf("lease after completion = %+v, want none", done)
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, baseBackoff},
+		{2, 2 * baseBackoff},
+		{5, 16 * baseBackoff},
+		{10, maxBackoff},
+		{100, maxBackoff},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if got := backoff(tt.attempt); got < tt.want || got > tt.want+tt.want/5 {
+				t.Errorf("backoff(%d) = %s, want %s plus up to 20%%", tt.attempt, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestQueueRetriesWithBackoff(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if _, err := store.EnqueueJob("test", []byte(`{}`), time.Now(), DefaultMaxAttempts); err != nil {
+			t.Fatalf("EnqueueJob() error: %v", err)
+		}
+		runQueue(t, store, 1, func(ctx context.Context, job *models.Job) error {
+			return errors.New("temporary failure")
+		})
+
+		now := time.Now()
+		if early := lease(t, store, now.Add(baseBackoff-time.Second)); len(early) != 0 {
+			t.Errorf("lease before the backoff = %+v, want none", early)
+		}
+		retried := lease(t, store, now.Add(baseBackoff+baseBackoff/5+time.Second))
+		if len(retried) != 1 || retried[0].Attempts != 2 || retried[0].LastError != "temporary failure" {
+			t.Fatalf("lease after the backoff = %+v, want the failed job on attempt 2", retried)
+		}
+	})
+}
+
+func TestQueueBuriesAfterMaxAttempts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if _, err := store.EnqueueJob("test", []byte(`{}`), time.Now(), 2); err != nil {
+			t.Fatalf("EnqueueJob() error: %v", err)
+		}
+		// The first failure is retried; move the retry forward to run it now
+		first := lease(t, store, time.Now())
+		if err := store.RetryJob(first[0].ID, first[0].Attempts, time.Now(), "first failure"); err != nil {
+			t.Fatalf("RetryJob() error: %v", err)
+		}
+
+		runQueue(t, store, 1, func(ctx context.Context, job *models.Job) error {
+			if job.Attempts != 2 {
+				t.Errorf("attempt = %d, want 2", job.Attempts)
+			}
+			return errors.New("permanent failure")
+		})
+
+		if buried := lease(t, store, time.Now().Add(2*maxBackoff)); len(buried) != 0 {
+			t.Errorf("lease after the last attempt = %+v, want the job to be dead", buried)
+		}
+	})
+}
+
+func TestQueueRunsJobsWithExpiredLease(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		job, err := store.EnqueueJob("test", []byte(`{}`), time.Now().Add(-time.Hour), DefaultMaxAttempts)
+		if err != nil {
+			t.Fatalf("EnqueueJob() error: %v", err)
+		}
+		// A worker leased the job long ago and never finished it
+		if crashed := lease(t, store, time.Now().Add(-LeaseDuration-time.Minute)); len(crashed) != 1 {
+			t.Fatalf("lease = %+v, want job %d", crashed, job.ID)
+		}
+
+		runQueue(t, store, 1, func(ctx context.Context, leased *models.Job) error {
+			if leased.ID != job.ID || leased.Attempts != 2 {
+				t.Errorf("ran job %d on attempt %d, want job %d on attempt 2", leased.ID, leased.Attempts, job.ID)
+			}
+			return nil
+		})
+
+		if done := lease(t, store, time.Now().Add(2*LeaseDuration)); len(done) != 0 {
+			t.Errorf("lease after completion = %+v, want none", done)
+		}
+	})
+}
+
+func TestQueueStopDrainsRunningJobs(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		q := New(store, 1, 10*time.Millisecond)
+		q.Register("test", func(ctx context.Context, job *models.Job) error {
+			close(started)
+			<-release
+			return ctx.Err()
+		})
+		q.Start()
+		if _, err := q.Enqueue("test", nil); err != nil {
+			t.Fatalf("Enqueue() error: %v", err)
+		}
+		<-started
+
+		stopped := make(chan error, 1)
+		go func() { stopped <- q.Stop(context.Background()) }()
+		select {
+		case err := <-stopped:
+			t.Fatalf("Stop() = %v before the running job finished", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		if err := <-stopped; err != nil {
+			t.Fatalf("Stop() error: %v", err)
+		}
+		if done := lease(t, store, time.Now().Add(2*LeaseDuration)); len(done) != 0 {
+			t.Errorf("lease after Stop = %+v, want the drained job to be done", done)
+		}
+	})
+}
+
+func TestQueueStopCancelsAfterDeadline(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		started := make(chan struct{})
+		q := New(store, 1, 10*time.Millisecond)
+		q.Register("test", func(ctx context.Context, job *models.Job) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		q.Start()
+		if _, err := q.Enqueue("test", nil); err != nil {
+			t.Fatalf("Enqueue() error: %v", err)
+		}
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := q.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Stop() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
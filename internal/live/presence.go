@@ -0,0 +1,100 @@
+// Package live tracks who is connected to a room's live channel.
+package live
+
+import "sync"
+
+// Role is the part a connection plays in a live session
+type Role string
+
+const (
+	RoleFacilitator Role = "facilitator" // The room owner; may send session commands
+	RoleParticipant Role = "participant" // Anyone else allowed into the room
+)
+
+// Member identifies a connected user or participant. One member may hold
+// several connections, for example in multiple browser tabs.
+type Member struct {
+	ID   string `json:"id"`
+	Role Role   `json:"role"`
+}
+
+// Counts summarises the distinct members connected to a room
+type Counts struct {
+	Participants int `json:"participants"`
+	Facilitators int `json:"facilitators"`
+}
+
+// presence is a member's role and number of open connections
+type presence struct {
+	role        Role
+	connections int
+}
+
+// Presence counts the members connected to each room on this instance
+type Presence struct {
+	mu    sync.Mutex
+	rooms map[string]map[string]*presence
+}
+
+// NewPresence creates an empty presence tracker
+func NewPresence() *Presence {
+	return &Presence{rooms: make(map[string]map[string]*presence)}
+}
+
+// Join records a new connection of member and returns the room's counts
+func (p *Presence) Join(roomID string, member Member) Counts {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	members := p.rooms[roomID]
+	if members == nil {
+		members = make(map[string]*presence)
+		p.rooms[roomID] = members
+	}
+	if members[member.ID] == nil {
+		members[member.ID] = &presence{role: member.Role}
+	}
+	members[member.ID].connections++
+
+	return p.counts(roomID)
+}
+
+// Leave records a closed connection of member and returns the room's counts
+func (p *Presence) Leave(roomID string, member Member) Counts {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	members := p.rooms[roomID]
+	if m := members[member.ID]; m != nil {
+		m.connections--
+		if m.connections <= 0 {
+			delete(members, member.ID)
+		}
+	}
+	if len(members) == 0 {
+		delete(p.rooms, roomID)
+	}
+
+	return p.counts(roomID)
+}
+
+// Counts returns the members currently connected to a room
+func (p *Presence) Counts(roomID string) Counts {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.counts(roomID)
+}
+
+// counts tallies a room's members; p.mu must be held
+func (p *Presence) counts(roomID string) Counts {
+	var counts Counts
+	for _, m := range p.rooms[roomID] {
+		if m.role == RoleFacilitator {
+			counts.Facilitators++
+		} else {
+			counts.Participants++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,486 @@
+// Package migrate applies and reverts the versioned SQL migrations embedded in
+// the migrations package, tracking applied versions and their checksums in the
+// schema_migrations table.
+package migrate
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/migrations"
+)
+
+var (
+	// ErrDirty is returned when a previous migration failed halfway and must be resolved with Force
+	ErrDirty = errors.New("database is in a dirty migration state; fix the schema and run `migrate force`")
+	// ErrChecksumMismatch is returned when an applied migration file was edited afterwards
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+)
+
+// Migration is a single reversible schema change
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status describes a migration and whether it has been applied
+type Status struct {
+	Version          int        `json:"version"`
+	Name             string     `json:"name"`
+	Applied          bool       `json:"applied"`
+	AppliedAt        *time.Time `json:"applied_at,omitempty"`
+	Dirty            bool       `json:"dirty"`
+	ChecksumMismatch bool       `json:"checksum_mismatch"`
+}
+
+// appliedRecord is a row of the schema_migrations table
+type appliedRecord struct {
+	Name      string
+	Checksum  string
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+// Migrator runs migrations for one database connection
+type Migrator struct {
+	conn       *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// New creates a migrator for the driver ("postgres" or "sqlite") using the embedded migrations
+func New(conn *sql.DB, driver string) (*Migrator, error) {
+	sub, err := fs.Sub(migrations.FS, driver)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for driver %q: %w", driver, err)
+	}
+
+	list, err := Load(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{conn: conn, driver: driver, migrations: list}, nil
+}
+
+// fileRe matches migration files: 001_description.up.sql / 001_description.down.sql
+var fileRe = regexp.MustCompile(`^(\d+)_([a-zA-Z0-9_]+)\.(up|down)\.sql$`)
+
+// Load reads NNN_name.up.sql/.down.sql pairs from fsys, sorted by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := fileRe.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			// Not a migration file, skip
+			continue
+		}
+
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in file %s: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s must have both .up.sql and .down.sql files", m.Version, m.Name)
+		}
+		sum := sha256.Sum256([]byte(m.Up + "\x00" + m.Down))
+		m.Checksum = hex.EncodeToString(sum[:])
+		list = append(list, *m)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+
+	return list, nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up() error {
+	applied, err := m.verify()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		log.Printf("Applying migration %03d_%s\n", migration.Version, migration.Name)
+		if err := m.run(migration, migration.Up, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Down reverts the n most recently applied migrations
+func (m *Migrator) Down(n int) error {
+	if n < 1 {
+		return fmt.Errorf("number of migrations to revert must be positive, got %d", n)
+	}
+
+	applied, err := m.verify()
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && n > 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		log.Printf("Reverting migration %03d_%s\n", migration.Version, migration.Name)
+		if err := m.run(migration, migration.Down, false); err != nil {
+			return err
+		}
+		n--
+	}
+
+	return nil
+}
+
+// Status reports every known migration along with its applied state
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = !record.Dirty
+			status.AppliedAt = &appliedAt
+			status.Dirty = record.Dirty
+			status.ChecksumMismatch = !record.Dirty && record.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Force records the schema as being exactly at version, clearing any dirty state.
+// It does not execute any SQL; it is meant for recovering after a manual fix.
+func (m *Migrator) Force(version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("failed to reset migration history: %w", err)
+	}
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+		if err := m.record(tx, migration, false); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit forced version: %w", err)
+	}
+	return nil
+}
+
+// verify loads the applied migrations and refuses to continue when the history is
+// dirty, references unknown versions or was applied from a different file
+func (m *Migrator) verify() (map[int]appliedRecord, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	for version, record := range applied {
+		if record.Dirty {
+			return nil, fmt.Errorf("migration %d: %w", version, ErrDirty)
+		}
+		migration := m.find(version)
+		if migration == nil {
+			return nil, fmt.Errorf("applied migration %d is missing from the migration files", version)
+		}
+		if record.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("migration %03d_%s: %w", version, migration.Name, ErrChecksumMismatch)
+		}
+	}
+
+	return applied, nil
+}
+
+// run executes one direction of a migration in a transaction and updates the history.
+// On failure the version is marked dirty so that later runs stop until it is forced.
+func (m *Migrator) run(migration Migration, statements string, up bool) error {
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", migration.Version, err)
+	}
+
+	if _, err := tx.Exec(statements); err != nil {
+		tx.Rollback()
+		m.markDirty(migration)
+		return fmt.Errorf("migration %03d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if up {
+		err = m.record(tx, migration, false)
+	} else {
+		_, err = tx.Exec(m.rebind(`DELETE FROM schema_migrations WHERE version = $1`), migration.Version)
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update migration history for %d: %w", migration.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// markDirty flags a failed migration; errors are logged because the original failure is more useful
+func (m *Migrator) markDirty(migration Migration) {
+	if _, err := m.conn.Exec(m.rebind(`DELETE FROM schema_migrations WHERE version = $1`), migration.Version); err != nil {
+		log.Printf("Failed to clear history for migration %d: %v\n", migration.Version, err)
+		return
+	}
+	if _, err := m.conn.Exec(
+		m.rebind(`INSERT INTO schema_migrations (version, name, checksum, dirty) VALUES ($1, $2, $3, $4)`),
+		migration.Version, migration.Name, migration.Checksum, true,
+	); err != nil {
+		log.Printf("Failed to mark migration %d as dirty: %v\n", migration.Version, err)
+	}
+}
+
+func (m *Migrator) record(tx *sql.Tx, migration Migration, dirty bool) error {
+	_, err := tx.Exec(
+		m.rebind(`INSERT INTO schema_migrations (version, name, checksum, dirty) VALUES ($1, $2, $3, $4)`),
+		migration.Version, migration.Name, migration.Checksum, dirty,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied() (map[int]appliedRecord, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.conn.Query(`SELECT version, name, checksum, dirty, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedRecord)
+	for rows.Next() {
+		var version int
+		var record appliedRecord
+		if err := rows.Scan(&version, &record.Name, &record.Checksum, &record.Dirty, &record.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = record
+	}
+
+	return applied, rows.Err()
+}
+
+// createTable creates the migration history table
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	checksum VARCHAR(64) NOT NULL,
+	dirty BOOLEAN NOT NULL DEFAULT false,
+	applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+)`
+
+func (m *Migrator) ensureTable() error {
+	if err := m.convertLegacyTable(); err != nil {
+		return err
+	}
+
+	_, err := m.conn.Exec(createTable)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	return nil
+}
+
+// legacyTables are the history tables of the runners that predate this package,
+// in the order they were used: the gorm migration_records table and the
+// schema_migrations table of the hand-written runner that replaced it. Both
+// recorded migration_id, name, description and executed_at.
+var legacyTables = []string{"migration_records", "schema_migrations"}
+
+// convertLegacyTable rewrites the history kept in any legacy table into the
+// current layout and drops the legacy table. The old runners kept no checksums;
+// the versions they applied are recorded with the checksums of the current
+// files, whose up scripts are unchanged.
+func (m *Migrator) convertLegacyTable() error {
+	for _, table := range legacyTables {
+		query := `SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = $1 AND column_name = 'migration_id'`
+		if m.driver == "sqlite" {
+			query = `SELECT COUNT(*) FROM pragma_table_info($1) WHERE name = 'migration_id'`
+		}
+		var legacy int
+		if err := m.conn.QueryRow(m.rebind(query), table).Scan(&legacy); err != nil {
+			return fmt.Errorf("failed to inspect %s table: %w", table, err)
+		}
+		if legacy == 0 {
+			continue
+		}
+
+		if err := m.convertLegacy(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertLegacy copies the history of one legacy table into schema_migrations
+// and drops it, in a single transaction
+func (m *Migrator) convertLegacy(table string) error {
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT migration_id FROM ` + table + ` ORDER BY migration_id`)
+	if err != nil {
+		return fmt.Errorf("failed to fetch legacy migrations: %w", err)
+	}
+	legacyMigrations := []*Migration{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan legacy migration: %w", err)
+		}
+		migration := m.find(version)
+		if migration == nil {
+			rows.Close()
+			return fmt.Errorf("legacy migration %d is missing from the migration files", version)
+		}
+		legacyMigrations = append(legacyMigrations, migration)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to fetch legacy migrations: %w", err)
+	}
+
+	log.Printf("Converting legacy %s table\n", table)
+	source := table
+	if table == "schema_migrations" {
+		// The legacy table has the name of the new one, move it out of the way first
+		source = "schema_migrations_legacy"
+		if _, err := tx.Exec(`ALTER TABLE schema_migrations RENAME TO ` + source); err != nil {
+			return fmt.Errorf("failed to rename legacy migrations table: %w", err)
+		}
+	}
+	if _, err := tx.Exec(createTable); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	for _, migration := range legacyMigrations {
+		_, err := tx.Exec(
+			m.rebind(`INSERT INTO schema_migrations (version, name, checksum, dirty, applied_at)
+			 SELECT $1, $2, $3, $4, executed_at FROM `+source+` WHERE migration_id = $1
+			 ON CONFLICT (version) DO NOTHING`),
+			migration.Version, migration.Name, migration.Checksum, false,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+		}
+	}
+	if _, err := tx.Exec(`DROP TABLE ` + source); err != nil {
+		return fmt.Errorf("failed to drop legacy migrations table: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit converted migrations table: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// rebind rewrites $N placeholders for SQLite
+func (m *Migrator) rebind(query string) string {
+	if m.driver == "sqlite" {
+		return placeholderRe.ReplaceAllString(query, "?$1")
+	}
+	return query
+}
+
+var placeholderRe = regexp.MustCompile(`\$(\d+)`)
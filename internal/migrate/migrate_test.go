@@ -0,0 +1,259 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+)
+
+var testFiles = fstest.MapFS{
+	"001_widgets.up.sql":   {Data: []byte(`CREATE TABLE widgets (id INTEGER PRIMARY KEY)`)},
+	"001_widgets.down.sql": {Data: []byte(`DROP TABLE widgets`)},
+	"002_gadgets.up.sql":   {Data: []byte(`CREATE TABLE gadgets (id INTEGER PRIMARY KEY)`)},
+	"002_gadgets.down.sql": {Data: []byte(`DROP TABLE gadgets`)},
+	"README.md":            {Data: []byte(`not a migration`)},
+}
+
+func newTestMigrator(t *testing.T, files fstest.MapFS) *Migrator {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+
+	list, err := Load(files)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	return &Migrator{conn: conn, driver: "sqlite", migrations: list}
+}
+
+func appliedVersions(t *testing.T, m *Migrator) []int {
+	t.Helper()
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status() error: %v", err)
+	}
+	versions := []int{}
+	for _, s := range statuses {
+		if s.Applied {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+func TestLoad(t *testing.T) {
+	list, err := Load(testFiles)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(list) != 2 || list[0].Version != 1 || list[1].Name != "gadgets" {
+		t.Fatalf("Load() = %+v", list)
+	}
+	if list[0].Checksum == list[1].Checksum || len(list[0].Checksum) != 64 {
+		t.Errorf("checksums %q and %q should be distinct sha256 digests", list[0].Checksum, list[1].Checksum)
+	}
+
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"missing down", fstest.MapFS{"001_a.up.sql": {Data: []byte(`SELECT 1`)}}},
+		{"conflicting names", fstest.MapFS{
+			"001_a.up.sql":   {Data: []byte(`SELECT 1`)},
+			"001_b.down.sql": {Data: []byte(`SELECT 1`)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.files); err == nil {
+				t.Error("Load() should fail")
+			}
+		})
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	m := newTestMigrator(t, testFiles)
+
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() error: %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 2 {
+		t.Fatalf("applied after Up() = %v, want [1 2]", got)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("second Up() error: %v", err)
+	}
+
+	if err := m.Down(1); err != nil {
+		t.Fatalf("Down(1) error: %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("applied after Down(1) = %v, want [1]", got)
+	}
+	if _, err := m.conn.Exec(`SELECT 1 FROM gadgets`); err == nil {
+		t.Error("gadgets table should have been dropped")
+	}
+
+	if err := m.Down(0); err == nil {
+		t.Error("Down(0) should fail")
+	}
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	m := newTestMigrator(t, testFiles)
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() error: %v", err)
+	}
+
+	m.migrations[0].Checksum = "edited"
+	if err := m.Up(); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Up() error = %v, want %v", err, ErrChecksumMismatch)
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status() error: %v", err)
+	}
+	if !statuses[0].ChecksumMismatch || statuses[1].ChecksumMismatch {
+		t.Errorf("Status() = %+v, want mismatch on version 1 only", statuses)
+	}
+}
+
+func TestDirtyAndForce(t *testing.T) {
+	files := fstest.MapFS{
+		"001_widgets.up.sql":   testFiles["001_widgets.up.sql"],
+		"001_widgets.down.sql": testFiles["001_widgets.down.sql"],
+		"002_broken.up.sql":    {Data: []byte(`CREATE TABLE broken (`)},
+		"002_broken.down.sql":  {Data: []byte(`SELECT 1`)},
+	}
+	m := newTestMigrator(t, files)
+
+	if err := m.Up(); err == nil {
+		t.Fatal("Up() should fail on the broken migration")
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status() error: %v", err)
+	}
+	if !statuses[0].Applied || !statuses[1].Dirty || statuses[1].Applied {
+		t.Fatalf("Status() = %+v, want 1 applied and 2 dirty", statuses)
+	}
+	if err := m.Up(); !errors.Is(err, ErrDirty) {
+		t.Fatalf("Up() while dirty error = %v, want %v", err, ErrDirty)
+	}
+
+	if err := m.Force(1); err != nil {
+		t.Fatalf("Force(1) error: %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 1 || got[0] != 1 {
+		t.Errorf("applied after Force(1) = %v, want [1]", got)
+	}
+	if err := m.Force(3); err == nil {
+		t.Error("Force(3) should reject an unknown version")
+	}
+	if err := m.Force(0); err != nil {
+		t.Fatalf("Force(0) error: %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Errorf("applied after Force(0) = %v, want none", got)
+	}
+}
+
+// legacyTableDDL holds the DDL of the legacy history tables: migration_records as
+// created by gorm's AutoMigrate(&MigrationRecord{}) and the interim schema_migrations
+var legacyTableDDL = map[string]string{
+	"migration_records": "CREATE TABLE `migration_records` (`id` integer PRIMARY KEY AUTOINCREMENT," +
+		"`migration_id` integer,`name` text NOT NULL,`description` text,`executed_at` datetime);" +
+		"CREATE UNIQUE INDEX `idx_migration_records_migration_id` ON `migration_records`(`migration_id`)",
+	"schema_migrations": `CREATE TABLE schema_migrations (
+		migration_id INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		description VARCHAR(255),
+		executed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	)`,
+}
+
+func TestConvertLegacyTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		legacy  []int
+		wantErr bool
+		want    []int
+	}{
+		{"gorm records", "migration_records", []int{1}, false, []int{1, 2}},
+		{"empty gorm records", "migration_records", nil, false, []int{1, 2}},
+		{"unknown gorm version", "migration_records", []int{1, 7}, true, nil},
+		{"interim history", "schema_migrations", []int{1}, false, []int{1, 2}},
+		{"unknown interim version", "schema_migrations", []int{1, 7}, true, nil},
+	}
+
+	executedAt := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMigrator(t, testFiles)
+			if _, err := m.conn.Exec(legacyTableDDL[tt.table]); err != nil {
+				t.Fatalf("failed to create legacy table: %v", err)
+			}
+			for _, version := range tt.legacy {
+				if version == 1 {
+					if _, err := m.conn.Exec(m.migrations[0].Up); err != nil {
+						t.Fatalf("failed to apply legacy migration: %v", err)
+					}
+				}
+				// gorm stores times in SQLite as text with the zone offset
+				if _, err := m.conn.Exec(
+					`INSERT INTO `+tt.table+` (migration_id, name, description, executed_at) VALUES (?, ?, ?, ?)`,
+					version, "initial_schema", "Initial schema", executedAt.Format("2006-01-02 15:04:05.999999999-07:00"),
+				); err != nil {
+					t.Fatalf("failed to record legacy migration: %v", err)
+				}
+			}
+
+			err := m.Up()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Up() should fail")
+				}
+				var count int
+				if err := m.conn.QueryRow(`SELECT COUNT(*) FROM ` + tt.table + ` WHERE migration_id > 0`).Scan(&count); err != nil || count != len(tt.legacy) {
+					t.Errorf("legacy table should be left untouched, got %d rows: %v", count, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Up() error: %v", err)
+			}
+
+			statuses, err := m.Status()
+			if err != nil {
+				t.Fatalf("Status() error: %v", err)
+			}
+			if got := appliedVersions(t, m); len(got) != len(tt.want) {
+				t.Errorf("applied = %v, want %v", got, tt.want)
+			}
+			if len(tt.legacy) > 0 && !statuses[0].AppliedAt.Equal(executedAt) {
+				t.Errorf("applied_at = %v, want the legacy executed_at %v", statuses[0].AppliedAt, executedAt)
+			}
+
+			var tables int
+			if err := m.conn.QueryRow(
+				`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('migration_records', 'schema_migrations_legacy')`,
+			).Scan(&tables); err != nil {
+				t.Fatalf("failed to list tables: %v", err)
+			}
+			if tables != 0 {
+				t.Errorf("legacy table should be dropped after conversion")
+			}
+		})
+	}
+}
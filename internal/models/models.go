@@ -1,6 +1,12 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -13,23 +19,220 @@ type User struct {
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 }
 
+// RoomStatus is the lifecycle state of a room
+type RoomStatus string
+
+const (
+	RoomStatusDraft    RoomStatus = "draft"    // Being prepared, not yet accepting feedback
+	RoomStatusOpen     RoomStatus = "open"     // Accepting feedback
+	RoomStatusClosed   RoomStatus = "closed"   // Read-only, can be reopened
+	RoomStatusArchived RoomStatus = "archived" // Read-only and hidden from active use
+)
+
+// roomTransitions lists the statuses each status may move to
+var roomTransitions = map[RoomStatus][]RoomStatus{
+	RoomStatusDraft:    {RoomStatusOpen, RoomStatusArchived},
+	RoomStatusOpen:     {RoomStatusClosed, RoomStatusArchived},
+	RoomStatusClosed:   {RoomStatusOpen, RoomStatusArchived},
+	RoomStatusArchived: {RoomStatusClosed},
+}
+
+// Valid reports whether s is a known room status
+func (s RoomStatus) Valid() bool {
+	_, ok := roomTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a room may move from s to next
+func (s RoomStatus) CanTransitionTo(next RoomStatus) bool {
+	for _, allowed := range roomTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 // Room represents a feedback collection room
 type Room struct {
-	ID                  string    `json:"id" db:"id"`
-	Name                string    `json:"name" db:"name"`
-	Password            string    `json:"-" db:"password"` // Never expose in JSON responses
-	CreatorID           int       `json:"creator_id" db:"creator_id"`
-	IsPasswordProtected bool      `json:"is_password_protected" db:"is_password_protected"`
-	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	ID                  string     `json:"id" db:"id"`
+	Name                string     `json:"name" db:"name"`
+	Password            string     `json:"-" db:"password"` // Never expose in JSON responses
+	CreatorID           int        `json:"creator_id" db:"creator_id"`
+	IsPasswordProtected bool       `json:"is_password_protected" db:"is_password_protected"`
+	Status              RoomStatus `json:"status" db:"status"`
+	Type                RoomType   `json:"type" db:"type"`
+	Phase               RetroPhase `json:"phase,omitempty" db:"phase"`                                 // Retro rooms only
+	VotesPerParticipant int        `json:"votes_per_participant,omitempty" db:"votes_per_participant"` // Retro rooms only
+	Moderated           bool       `json:"moderated,omitempty" db:"moderated"`                         // Q&A rooms only
+	Reactions           []string   `json:"reactions" db:"reactions"`                                   // Emoji offered to participants; nil for the defaults
+	OpensAt             *time.Time `json:"opens_at,omitempty" db:"opens_at"`
+	ClosesAt            *time.Time `json:"closes_at,omitempty" db:"closes_at"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Public returns a copy of the room without its password hash, for event
+// payloads and responses shared beyond the handler that loaded it
+func (r *Room) Public() Room {
+	public := *r
+	public.Password = ""
+	return public
+}
+
+// AcceptsFeedback reports whether new feedback may be submitted to the room at now.
+// The schedule is checked directly so that it holds between scheduler runs.
+func (r *Room) AcceptsFeedback(now time.Time) bool {
+	if r.Status != RoomStatusOpen {
+		return false
+	}
+	if r.OpensAt != nil && now.Before(*r.OpensAt) {
+		return false
+	}
+	if r.ClosesAt != nil && !now.Before(*r.ClosesAt) {
+		return false
+	}
+	return true
+}
+
+// ScheduledStatus returns the status the room's schedule calls for at now:
+// draft rooms open once opens_at has passed and open rooms close at closes_at
+func (r *Room) ScheduledStatus(now time.Time) RoomStatus {
+	closed := r.ClosesAt != nil && !now.Before(*r.ClosesAt)
+
+	switch r.Status {
+	case RoomStatusDraft:
+		if r.OpensAt == nil || now.Before(*r.OpensAt) {
+			return r.Status
+		}
+		if closed {
+			return RoomStatusClosed
+		}
+		return RoomStatusOpen
+	case RoomStatusOpen:
+		if closed {
+			return RoomStatusClosed
+		}
+	}
+	return r.Status
+}
+
+// ValidateSchedule checks an opening window: closes_at must be in the future and after opens_at
+func ValidateSchedule(opensAt, closesAt *time.Time, now time.Time) error {
+	if closesAt == nil {
+		return nil
+	}
+	if !closesAt.After(now) {
+		return errors.New("closes_at must be in the future")
+	}
+	if opensAt != nil && !closesAt.After(*opensAt) {
+		return errors.New("closes_at must be after opens_at")
+	}
+	return nil
+}
+
+// FeedbackStatus is the triage state of a feedback entry
+type FeedbackStatus string
+
+const (
+	FeedbackStatusNew      FeedbackStatus = "new"      // Not looked at yet
+	FeedbackStatusReviewed FeedbackStatus = "reviewed" // Read by the room owner
+	FeedbackStatusArchived FeedbackStatus = "archived" // Dealt with or dismissed
+)
+
+// Valid reports whether s is a known feedback status
+func (s FeedbackStatus) Valid() bool {
+	switch s {
+	case FeedbackStatusNew, FeedbackStatusReviewed, FeedbackStatusArchived:
+		return true
+	}
+	return false
 }
 
 // Feedback represents a piece of feedback submitted in a room
 type Feedback struct {
-	ID        int       `json:"id" db:"id"`
-	RoomID    string    `json:"room_id" db:"room_id"`
-	Content   string    `json:"content" db:"content"`
-	Sentiment string    `json:"sentiment" db:"sentiment"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID             int            `json:"id" db:"id"`
+	RoomID         string         `json:"room_id" db:"room_id"`
+	Content        string         `json:"content" db:"content"`
+	Sentiment      string         `json:"sentiment" db:"sentiment"`
+	SentimentScore *float64       `json:"sentiment_score,omitempty" db:"sentiment_score"` // Set once analysed
+	Status         FeedbackStatus `json:"status" db:"status"`
+	Tags           []string       `json:"tags" db:"-"`                        // Sorted; stored in feedback_tags
+	Answers        []Answer       `json:"answers,omitempty" db:"-"`           // Survey rooms only; stored in answers
+	ColumnID       *int           `json:"column_id,omitempty" db:"column_id"` // Retro rooms only
+	GroupID        *int           `json:"group_id,omitempty" db:"group_id"`   // Retro rooms only
+	Votes          int            `json:"votes,omitempty" db:"votes"`
+	QAState        QAState        `json:"qa_state,omitempty" db:"qa_state"` // Q&A rooms only
+	Pinned         bool           `json:"pinned,omitempty" db:"pinned"`     // Q&A rooms only
+	Reactions      map[string]int `json:"reactions,omitempty" db:"-"`       // Count per reaction; stored in reactions
+	ParticipantID  string         `json:"-" db:"participant_id"`            // Author, when submitted with a participant token
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
+
+// Feedback sort keys
+const (
+	FeedbackSortCreatedAt = "created_at"
+	FeedbackSortScore     = "score" // Entries still awaiting analysis sort below every score
+	FeedbackSortVotes     = "votes"
+)
+
+// MaxFeedbackPageSize caps the number of entries returned per page
+const MaxFeedbackPageSize = 200
+
+// FeedbackQuery selects one page of a room's feedback. Empty fields do not filter.
+type FeedbackQuery struct {
+	Sentiment string
+	Status    FeedbackStatus
+	Tag       string
+	QAState   QAState
+	From      *time.Time // Inclusive lower bound on created_at
+	To        *time.Time // Exclusive upper bound on created_at
+	Sort      string     // FeedbackSortCreatedAt (default), FeedbackSortScore or FeedbackSortVotes
+	Ascending bool
+	Limit     int
+	Cursor    string // NextCursor of the previous page
+}
+
+// FeedbackPage is one page of feedback together with the total number of
+// entries matching the filters
+type FeedbackPage struct {
+	Items      []Feedback
+	Total      int
+	NextCursor string // Empty on the last page
+}
+
+// RefreshToken is a stored refresh token; only the SHA-256 hash of the token is persisted
+type RefreshToken struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	FamilyID  string     `json:"family_id" db:"family_id"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// JobStatus is the state of a background job
+type JobStatus string
+
+const (
+	JobStatusQueued  JobStatus = "queued"  // Waiting for run_at
+	JobStatusRunning JobStatus = "running" // Leased by a worker until locked_until
+	JobStatusDone    JobStatus = "done"    // Completed successfully
+	JobStatusDead    JobStatus = "dead"    // Gave up after max_attempts; kept for inspection
+)
+
+// Job is a unit of background work stored in the jobs table
+type Job struct {
+	ID          int64           `json:"id" db:"id"`
+	Kind        string          `json:"kind" db:"kind"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	Status      JobStatus       `json:"status" db:"status"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	MaxAttempts int             `json:"max_attempts" db:"max_attempts"`
+	RunAt       time.Time       `json:"run_at" db:"run_at"`
+	LockedUntil *time.Time      `json:"locked_until,omitempty" db:"locked_until"`
+	LastError   string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
 }
 
 // Auth Request/Response types
@@ -44,20 +247,335 @@ type RegisterRequest struct {
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+	User         User      `json:"user"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // Room Request/Response types
+// CreateRoomRequest creates a room. A room with opens_at in the future starts as a
+// draft and is opened by the scheduler; closes_at closes it automatically.
+// Retro rooms start in the write phase with the given columns, or the default ones.
+// Questions submitted to moderated Q&A rooms wait for approval.
 type CreateRoomRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Password string `json:"password"`
+	Name                string     `json:"name" binding:"required"`
+	Slug                string     `json:"slug"` // Optional vanity ID used instead of a generated code
+	Password            string     `json:"password"`
+	Status              RoomStatus `json:"status" binding:"omitempty,oneof=draft open"`
+	Type                RoomType   `json:"type" binding:"omitempty,oneof=standard retro qa"`
+	Columns             []string   `json:"columns" binding:"omitempty,max=10"`                     // Retro rooms only
+	VotesPerParticipant int        `json:"votes_per_participant" binding:"omitempty,min=1,max=20"` // Retro rooms only
+	Moderated           bool       `json:"moderated"`                                              // Q&A rooms only
+	Reactions           []string   `json:"reactions"`                                              // Defaults to DefaultReactions
+	OpensAt             *time.Time `json:"opens_at"`
+	ClosesAt            *time.Time `json:"closes_at"`
+}
+
+// Validate checks the fields that binding tags cannot express
+func (r *CreateRoomRequest) Validate(now time.Time) error {
+	if r.Type != RoomTypeRetro && (len(r.Columns) > 0 || r.VotesPerParticipant != 0) {
+		return errors.New("columns and votes_per_participant apply to retro rooms only")
+	}
+	if r.Type != RoomTypeQA && r.Moderated {
+		return errors.New("moderated applies to Q&A rooms only")
+	}
+	if err := ValidateReactions(r.Reactions); err != nil {
+		return err
+	}
+	for i, title := range r.Columns {
+		normalized, err := normalizeTitle(title, MaxRetroTitle)
+		if err != nil {
+			return fmt.Errorf("column %d: %w", i+1, err)
+		}
+		r.Columns[i] = normalized
+	}
+	return ValidateSchedule(r.OpensAt, r.ClosesAt, now)
+}
+
+// UpdateRoomRequest holds the fields of a partial room update; omitted fields are unchanged.
+// An empty password removes password protection and a null opens_at or closes_at
+// removes that end of the schedule.
+type UpdateRoomRequest struct {
+	Name      *string      `json:"name" binding:"omitempty,min=1"`
+	Password  *string      `json:"password"`
+	Status    *RoomStatus  `json:"status" binding:"omitempty,oneof=draft open closed archived"`
+	Reactions *[]string    `json:"reactions"` // An empty list turns emoji reactions off
+	OpensAt   OptionalTime `json:"opens_at"`
+	ClosesAt  OptionalTime `json:"closes_at"`
+}
+
+// OptionalTime is a time field of a partial update that tells an omitted field
+// apart from an explicit null
+type OptionalTime struct {
+	Set  bool       // The field was present, possibly null
+	Time *time.Time // nil when the field was null
+}
+
+// UnmarshalJSON is only called for fields present in the input
+func (t *OptionalTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	return json.Unmarshal(data, &t.Time)
 }
 
 type JoinRoomRequest struct {
 	Password string `json:"password"`
 }
 
+// JoinRoomResponse carries the participant token required to submit to protected rooms
+type JoinRoomResponse struct {
+	Room      Room      `json:"room"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateFeedbackRequest submits feedback. Content is required unless the room
+// is a survey, whose submissions carry answers to its questions instead. Cards
+// of retro rooms name their column.
 type CreateFeedbackRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content  string   `json:"content"`
+	Answers  []Answer `json:"answers"`
+	ColumnID int      `json:"column_id"`
+}
+
+// tagPattern restricts tags to short lowercase slugs
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// NormalizeTag lowercases and trims a tag and checks its format
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(tag) {
+		return "", fmt.Errorf("invalid tag %q: use up to 50 letters, digits, '-' or '_'", tag)
+	}
+	return tag, nil
+}
+
+// NormalizeTags normalises a tag list, dropping duplicates and sorting it
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := []string{}
+	for _, tag := range tags {
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// UpdateFeedbackRequest triages a feedback entry; omitted fields are unchanged.
+// Tags replace the current set. Questions of Q&A rooms are also moderated here.
+type UpdateFeedbackRequest struct {
+	Status  *FeedbackStatus `json:"status" binding:"omitempty,oneof=new reviewed archived"`
+	Tags    *[]string       `json:"tags" binding:"omitempty,max=20"`
+	QAState *QAState        `json:"qa_state" binding:"omitempty,oneof=pending approved answered dismissed"` // Q&A rooms only
+	Pinned  *bool           `json:"pinned"`                                                                 // Q&A rooms only
+}
+
+// MaxSearchResults caps the number of search results returned per page
+const MaxSearchResults = 100
+
+// FeedbackSearchResult is a feedback entry matching a search, with a snippet of
+// its content in which matching terms are wrapped in <mark> tags. The snippet is
+// HTML-escaped apart from those tags.
+type FeedbackSearchResult struct {
+	Feedback Feedback `json:"feedback"`
+	RoomName string   `json:"room_name"`
+	Snippet  string   `json:"snippet"`
+	Rank     float64  `json:"rank"` // Higher is more relevant
+}
+
+// FeedbackSearchPage is one page of search results, best match first
+type FeedbackSearchPage struct {
+	Items []FeedbackSearchResult
+	Total int
+}
+
+// MaxImportRows caps the number of rows in one feedback import
+const MaxImportRows = 10000
+
+// FeedbackImport is a validated entry of a feedback import
+type FeedbackImport struct {
+	Content        string
+	Status         FeedbackStatus
+	Tags           []string
+	Sentiment      string   // "pending" unless the source was already analysed
+	SentimentScore *float64 // Set together with Sentiment
+	CreatedAt      time.Time
+}
+
+// ImportRowError describes a problem with one field of an imported row. Rows are
+// numbered as in a spreadsheet for CSV (the header is row 1) and from 1 for JSON.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportResult reports the outcome of a feedback import. Imports are all or
+// nothing: when any row has errors, nothing is imported.
+type ImportResult struct {
+	DryRun   bool             `json:"dry_run"`
+	Rows     int              `json:"rows"`     // Rows read from the file
+	Imported int              `json:"imported"` // Entries inserted, or that would be on a dry run
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// Analytics intervals
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+	IntervalWeek = "week" // Weeks start on Monday, as in ISO 8601
+)
+
+// MaxAnalyticsBuckets caps the number of intervals in an analytics series
+const MaxAnalyticsBuckets = 1000
+
+// IntervalStart returns the start of the interval containing t, in UTC
+func IntervalStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	switch interval {
+	case IntervalHour:
+		return t.Truncate(time.Hour)
+	case IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextInterval returns the start of the interval following the one starting at t
+func NextInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalHour:
+		return t.Add(time.Hour)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// AnalyticsQuery selects the period and granularity of room analytics
+type AnalyticsQuery struct {
+	Interval string
+	From     time.Time // Rounded down to the start of its interval
+	To       time.Time // Exclusive
+}
+
+// SentimentCounts counts feedback entries by sentiment label
+type SentimentCounts struct {
+	Positive int `json:"positive"`
+	Neutral  int `json:"neutral"`
+	Negative int `json:"negative"`
+	Pending  int `json:"pending"`
+}
+
+// AnalyticsBucket summarises the feedback submitted in one interval
+type AnalyticsBucket struct {
+	Start        time.Time       `json:"start"`
+	Count        int             `json:"count"`
+	AverageScore *float64        `json:"average_score"` // Null without analysed entries
+	Sentiment    SentimentCounts `json:"sentiment"`
+	NPS          *NPSScore       `json:"nps,omitempty"`  // Rooms with NPS questions only
+	CSAT         *CSATScore      `json:"csat,omitempty"` // Rooms with rating questions only
+}
+
+// SubmissionWindow counts the feedback submitted in one hour of the week, in UTC
+type SubmissionWindow struct {
+	Weekday string `json:"weekday"`
+	Hour    int    `json:"hour"`
+	Count   int    `json:"count"`
+}
+
+// RoomAnalytics summarises a room's feedback over a period
+type RoomAnalytics struct {
+	Interval     string             `json:"interval"`
+	From         time.Time          `json:"from"`
+	To           time.Time          `json:"to"`
+	Total        int                `json:"total"`
+	Sentiment    SentimentCounts    `json:"sentiment"`
+	AverageScore *float64           `json:"average_score"`
+	Series       []AnalyticsBucket  `json:"series"`              // Every interval of the period, including empty ones
+	PeakWindows  []SubmissionWindow `json:"peak_windows"`        // Busiest hours of the week, busiest first
+	NPS          *NPSScore          `json:"nps,omitempty"`       // Over all NPS questions of a survey room
+	CSAT         *CSATScore         `json:"csat,omitempty"`      // Over all rating questions of a survey room
+	Questions    []QuestionScore    `json:"questions,omitempty"` // Per NPS and rating question
+}
+
+// Sentiment trend directions
+const (
+	TrendUp      = "up"
+	TrendDown    = "down"
+	TrendFlat    = "flat"
+	TrendUnknown = "unknown" // One of the weeks has no analysed feedback
+)
+
+// trendThreshold is the change in average score below which a trend is flat
+const trendThreshold = 0.05
+
+// SentimentTrend compares the average sentiment score of the last seven days
+// with that of the seven days before
+type SentimentTrend struct {
+	Current   *float64 `json:"current"`
+	Previous  *float64 `json:"previous"`
+	Direction string   `json:"direction"`
+}
+
+// NewSentimentTrend derives the direction of a trend from its averages
+func NewSentimentTrend(current, previous *float64) SentimentTrend {
+	trend := SentimentTrend{Current: current, Previous: previous, Direction: TrendUnknown}
+	if current != nil && previous != nil {
+		switch change := *current - *previous; {
+		case change >= trendThreshold:
+			trend.Direction = TrendUp
+		case change <= -trendThreshold:
+			trend.Direction = TrendDown
+		default:
+			trend.Direction = TrendFlat
+		}
+	}
+	return trend
+}
+
+// RoomActivity summarises the feedback of one room for its owner
+type RoomActivity struct {
+	Room           Room            `json:"room"`
+	FeedbackCount  int             `json:"feedback_count"`
+	UnreadCount    int             `json:"unread_count"` // Submitted since the owner last viewed the feedback
+	LastFeedbackAt *time.Time      `json:"last_feedback_at"`
+	LastVisitedAt  *time.Time      `json:"last_visited_at"`
+	Sentiment      SentimentCounts `json:"sentiment"`
+	Trend          SentimentTrend  `json:"sentiment_trend"`
+}
+
+// DashboardTotals sums the activity of all of an owner's rooms
+type DashboardTotals struct {
+	Rooms          int             `json:"rooms"`
+	OpenRooms      int             `json:"open_rooms"`
+	Feedback       int             `json:"feedback"`
+	Unread         int             `json:"unread"`
+	LastFeedbackAt *time.Time      `json:"last_feedback_at"`
+	Sentiment      SentimentCounts `json:"sentiment"`
+	Trend          SentimentTrend  `json:"sentiment_trend"`
+}
+
+// Dashboard is the activity of every room of an owner, newest room first
+type Dashboard struct {
+	Totals DashboardTotals `json:"totals"`
+	Rooms  []RoomActivity  `json:"rooms"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestRoomStatusCanTransitionTo(t *testing.T) {
+	statuses := []RoomStatus{RoomStatusDraft, RoomStatusOpen, RoomStatusClosed, RoomStatusArchived}
+	allowed := map[[2]RoomStatus]bool{
+		{RoomStatusDraft, RoomStatusOpen}:      true,
+		{RoomStatusDraft, RoomStatusArchived}:  true,
+		{RoomStatusOpen, RoomStatusClosed}:     true,
+		{RoomStatusOpen, RoomStatusArchived}:   true,
+		{RoomStatusClosed, RoomStatusOpen}:     true,
+		{RoomStatusClosed, RoomStatusArchived}: true,
+		{RoomStatusArchived, RoomStatusClosed}: true,
+	}
+
+	for _, from := range statuses {
+		if !from.Valid() {
+			t.Errorf("%s is not valid", from)
+		}
+		for _, to := range append(statuses, "deleted") {
+			if got, want := from.CanTransitionTo(to), allowed[[2]RoomStatus{from, to}]; got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %t, want %t", from, to, got, want)
+			}
+		}
+	}
+	if RoomStatus("deleted").Valid() || RoomStatus("deleted").CanTransitionTo(RoomStatusOpen) {
+		t.Error("unknown status is valid or may transition")
+	}
+}
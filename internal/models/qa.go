@@ -0,0 +1,46 @@
+package models
+
+// QAState is the moderation state of a question in a Q&A room
+type QAState string
+
+const (
+	QAStatePending   QAState = "pending"   // Waiting for approval in a moderated room
+	QAStateApproved  QAState = "approved"  // Shown on the public feed and open for votes
+	QAStateAnswered  QAState = "answered"  // Still shown, below the open questions; closed for votes
+	QAStateDismissed QAState = "dismissed" // Removed from the public feed
+)
+
+// Valid reports whether s is a known Q&A state
+func (s QAState) Valid() bool {
+	switch s {
+	case QAStatePending, QAStateApproved, QAStateAnswered, QAStateDismissed:
+		return true
+	}
+	return false
+}
+
+// Public reports whether questions in state s are shown on the public feed
+func (s QAState) Public() bool {
+	return s == QAStateApproved || s == QAStateAnswered
+}
+
+// QAFeed is the public feed of a Q&A room: its approved and answered questions,
+// pinned ones first, then open ones by votes, then answered ones by votes
+type QAFeed struct {
+	Questions []Feedback `json:"questions"`
+	Voted     []int      `json:"voted"` // Questions upvoted by the participant viewing the feed
+}
+
+// RankQuestion reports whether question a ranks above question b on the feed
+func RankQuestion(a, b *Feedback) bool {
+	if a.Pinned != b.Pinned {
+		return a.Pinned
+	}
+	if open := a.QAState == QAStateApproved; open != (b.QAState == QAStateApproved) {
+		return open
+	}
+	if a.Votes != b.Votes {
+		return a.Votes > b.Votes
+	}
+	return a.ID < b.ID
+}
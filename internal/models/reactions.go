@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ReactionUpvote is the plain agreement reaction offered next to a room's
+// emoji. Q&A rooms leave it out since their questions are upvoted with votes.
+const ReactionUpvote = "upvote"
+
+// MaxRoomReactions caps the emoji configured for a room
+const MaxRoomReactions = 8
+
+// maxReactionRunes caps the length of one emoji, enough for ZWJ sequences
+// and skin tone modifiers
+const maxReactionRunes = 8
+
+// DefaultReactions are offered in rooms without an emoji set of their own
+var DefaultReactions = []string{"👍", "❤️", "😂", "🎉", "🤔"}
+
+// AvailableReactions lists the reactions participants may leave in the room
+func (r *Room) AvailableReactions() []string {
+	emoji := r.Reactions
+	if emoji == nil {
+		emoji = DefaultReactions
+	}
+	if r.Type == RoomTypeQA {
+		return append([]string{}, emoji...)
+	}
+	return append([]string{ReactionUpvote}, emoji...)
+}
+
+// AcceptsReaction reports whether reaction is offered in the room
+func (r *Room) AcceptsReaction(reaction string) bool {
+	for _, available := range r.AvailableReactions() {
+		if available == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateReactions checks the emoji set of a room: up to MaxRoomReactions
+// distinct emoji. An empty set turns emoji reactions off.
+func ValidateReactions(reactions []string) error {
+	if len(reactions) > MaxRoomReactions {
+		return fmt.Errorf("at most %d reactions are allowed", MaxRoomReactions)
+	}
+	seen := make(map[string]bool, len(reactions))
+	for i, reaction := range reactions {
+		if err := validateEmoji(reaction); err != nil {
+			return fmt.Errorf("reaction %d: %w", i+1, err)
+		}
+		if seen[reaction] {
+			return fmt.Errorf("reaction %d: duplicate %s", i+1, reaction)
+		}
+		seen[reaction] = true
+	}
+	return nil
+}
+
+// validateEmoji accepts a single emoji, including modifiers and ZWJ sequences
+func validateEmoji(s string) error {
+	if s == "" {
+		return errors.New("must not be empty")
+	}
+	if utf8.RuneCountInString(s) > maxReactionRunes {
+		return fmt.Errorf("must be at most %d characters", maxReactionRunes)
+	}
+	symbol := false
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.So, r):
+			symbol = true
+		case r == '\u200d' || r == '\ufe0f' || unicode.Is(unicode.Sk, r) || unicode.Is(unicode.Mn, r):
+		default:
+			return errors.New("must be an emoji")
+		}
+	}
+	if !symbol {
+		return errors.New("must be an emoji")
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateReactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		reactions []string
+		wantErr   bool
+	}{
+		{"empty set", []string{}, false},
+		{"defaults", DefaultReactions, false},
+		{"skin tone", []string{"\U0001F44D\U0001F3FD"}, false},
+		{"zwj sequence", []string{"\U0001F469\u200d\U0001F4BB"}, false},
+		{"variation selector", []string{"\u2764\ufe0f"}, false},
+		{"flag", []string{"\U0001F1F7\U0001F1F4"}, false},
+		{"text", []string{"ok"}, true},
+		{"emoji with text", []string{"\U0001F44Dok"}, true},
+		{"blank", []string{""}, true},
+		{"duplicate", []string{"\U0001F389", "\U0001F389"}, true},
+		{"too long", []string{"\U0001F44D\U0001F44D\U0001F44D\U0001F44D\U0001F44D\U0001F44D\U0001F44D\U0001F44D\U0001F44D"}, true},
+		{"too many", []string{"\U0001F600", "\U0001F601", "\U0001F602", "\U0001F603", "\U0001F604", "\U0001F605", "\U0001F606", "\U0001F607", "\U0001F608"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateReactions(tt.reactions); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateReactions(%q) error = %v, want error %v", tt.reactions, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAvailableReactions(t *testing.T) {
+	custom := []string{"\U0001F680"}
+	tests := []struct {
+		name string
+		room Room
+		want []string
+	}{
+		{"defaults", Room{Type: RoomTypeStandard}, append([]string{ReactionUpvote}, DefaultReactions...)},
+		{"custom", Room{Type: RoomTypeRetro, Reactions: custom}, []string{ReactionUpvote, "\U0001F680"}},
+		{"emoji turned off", Room{Type: RoomTypeStandard, Reactions: []string{}}, []string{ReactionUpvote}},
+		{"q&a without upvote", Room{Type: RoomTypeQA, Reactions: custom}, custom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.room.AvailableReactions()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AvailableReactions() = %q, want %q", got, tt.want)
+			}
+			for _, reaction := range tt.want {
+				if !tt.room.AcceptsReaction(reaction) {
+					t.Errorf("AcceptsReaction(%q) = false", reaction)
+				}
+			}
+			if tt.room.AcceptsReaction("\U0001F92F") {
+				t.Error("AcceptsReaction() should reject emoji outside the set")
+			}
+		})
+	}
+}
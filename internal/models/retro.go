@@ -0,0 +1,252 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// RoomType selects how a room collects feedback
+type RoomType string
+
+const (
+	RoomTypeStandard RoomType = "standard" // A flat list of feedback, optionally a survey
+	RoomTypeRetro    RoomType = "retro"    // A retrospective board of cards in columns
+	RoomTypeQA       RoomType = "qa"       // Questions from the audience, ranked by upvotes
+)
+
+// Valid reports whether t is a known room type
+func (t RoomType) Valid() bool {
+	return t == RoomTypeStandard || t == RoomTypeRetro || t == RoomTypeQA
+}
+
+// RetroPhase is the stage a retro board is in, moved on by the facilitator
+type RetroPhase string
+
+const (
+	RetroPhaseWrite   RetroPhase = "write"   // Participants add cards; cards of others are hidden
+	RetroPhaseGroup   RetroPhase = "group"   // Cards are revealed and grouped by the facilitator
+	RetroPhaseVote    RetroPhase = "vote"    // Participants spend their dots; totals are hidden
+	RetroPhaseDiscuss RetroPhase = "discuss" // Totals are revealed and action items agreed on
+)
+
+// Valid reports whether p is a known retro phase
+func (p RetroPhase) Valid() bool {
+	switch p {
+	case RetroPhaseWrite, RetroPhaseGroup, RetroPhaseVote, RetroPhaseDiscuss:
+		return true
+	}
+	return false
+}
+
+// CardsHidden reports whether participants only see their own cards in phase p
+func (p RetroPhase) CardsHidden() bool {
+	return p == RetroPhaseWrite
+}
+
+// VotesHidden reports whether vote totals are withheld from participants in phase p
+func (p RetroPhase) VotesHidden() bool {
+	return p != RetroPhaseDiscuss
+}
+
+// Retro limits
+const (
+	DefaultRetroVotes  = 3
+	MaxRetroVotes      = 20
+	MaxRetroColumns    = 10
+	MaxRetroTitle      = 100 // Characters, of columns and groups
+	MaxActionItemTitle = 500 // Characters
+	MaxActionItemOwner = 100 // Characters
+)
+
+// DefaultRetroColumns are the columns of a retro room created without any
+var DefaultRetroColumns = []string{"Went well", "To improve", "Ideas"}
+
+// RetroColumn is one column of a retro board
+type RetroColumn struct {
+	ID       int    `json:"id" db:"id"`
+	RoomID   string `json:"room_id" db:"room_id"`
+	Position int    `json:"position" db:"position"` // From 1, left to right
+	Title    string `json:"title" db:"title"`
+}
+
+// ColumnInput defines a column when setting a retro room's columns
+type ColumnInput struct {
+	ID    int    `json:"id"` // An existing column to keep, with its cards; 0 adds one
+	Title string `json:"title" binding:"required"`
+}
+
+// SetColumnsRequest replaces the columns of a retro room. Columns left out
+// must not hold any cards.
+type SetColumnsRequest struct {
+	Columns []ColumnInput `json:"columns" binding:"required,min=1,max=10"`
+}
+
+// Validate checks the columns against the room's current ones and returns them
+// numbered in order
+func (r *SetColumnsRequest) Validate(existing []RetroColumn) ([]RetroColumn, error) {
+	known := make(map[int]bool, len(existing))
+	for _, c := range existing {
+		known[c.ID] = true
+	}
+
+	seen := make(map[int]bool, len(r.Columns))
+	columns := make([]RetroColumn, 0, len(r.Columns))
+	for i, input := range r.Columns {
+		n := i + 1
+		if input.ID != 0 {
+			switch {
+			case !known[input.ID]:
+				return nil, fmt.Errorf("column %d: unknown column id %d", n, input.ID)
+			case seen[input.ID]:
+				return nil, fmt.Errorf("column %d: column id %d is listed twice", n, input.ID)
+			}
+			seen[input.ID] = true
+		}
+		title, err := normalizeTitle(input.Title, MaxRetroTitle)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %w", n, err)
+		}
+		columns = append(columns, RetroColumn{ID: input.ID, Position: n, Title: title})
+	}
+	return columns, nil
+}
+
+// RetroPhaseRequest moves a retro board to another phase and optionally
+// changes the number of dots each participant may spend
+type RetroPhaseRequest struct {
+	Phase               RetroPhase `json:"phase" binding:"required,oneof=write group vote discuss"`
+	VotesPerParticipant *int       `json:"votes_per_participant" binding:"omitempty,min=1,max=20"`
+}
+
+// CardGroup gathers similar cards of a retro board under a title. Votes is the
+// sum of the votes of its cards.
+type CardGroup struct {
+	ID          int       `json:"id" db:"id"`
+	RoomID      string    `json:"room_id" db:"room_id"`
+	Title       string    `json:"title" db:"title"`
+	FeedbackIDs []int     `json:"feedback_ids" db:"-"` // In ID order
+	Votes       int       `json:"votes,omitempty" db:"-"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// CardGroupRequest creates a group, or replaces the title and cards of one.
+// Cards move out of any group they were in before.
+type CardGroupRequest struct {
+	Title       string `json:"title" binding:"required"`
+	FeedbackIDs []int  `json:"feedback_ids" binding:"required,min=1,max=100"`
+}
+
+// Validate trims the title and drops repeated cards
+func (r *CardGroupRequest) Validate() error {
+	title, err := normalizeTitle(r.Title, MaxRetroTitle)
+	if err != nil {
+		return err
+	}
+	r.Title = title
+
+	seen := make(map[int]bool, len(r.FeedbackIDs))
+	ids := []int{}
+	for _, id := range r.FeedbackIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	r.FeedbackIDs = ids
+	return nil
+}
+
+// ActionItem is a follow-up agreed on in a retro, optionally linked to the card
+// it addresses
+type ActionItem struct {
+	ID         int       `json:"id" db:"id"`
+	RoomID     string    `json:"room_id" db:"room_id"`
+	FeedbackID *int      `json:"feedback_id,omitempty" db:"feedback_id"`
+	Title      string    `json:"title" db:"title"`
+	Owner      string    `json:"owner" db:"owner"` // Free text, e.g. a name; empty when unassigned
+	Done       bool      `json:"done" db:"done"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ActionItemRequest creates an action item
+type ActionItemRequest struct {
+	Title      string `json:"title" binding:"required"`
+	Owner      string `json:"owner"`
+	FeedbackID *int   `json:"feedback_id"`
+}
+
+// Validate trims the title and owner and checks their length
+func (r *ActionItemRequest) Validate() error {
+	title, err := normalizeTitle(r.Title, MaxActionItemTitle)
+	if err != nil {
+		return err
+	}
+	r.Title = title
+	r.Owner = strings.TrimSpace(r.Owner)
+	if utf8.RuneCountInString(r.Owner) > MaxActionItemOwner {
+		return fmt.Errorf("owner must be at most %d characters", MaxActionItemOwner)
+	}
+	return nil
+}
+
+// UpdateActionItemRequest changes an action item; omitted fields are unchanged
+type UpdateActionItemRequest struct {
+	Title *string `json:"title"`
+	Owner *string `json:"owner"`
+	Done  *bool   `json:"done"`
+}
+
+// Apply validates the changes and writes them to item
+func (r *UpdateActionItemRequest) Apply(item *ActionItem) error {
+	update := ActionItemRequest{Title: item.Title, Owner: item.Owner}
+	if r.Title != nil {
+		update.Title = *r.Title
+	}
+	if r.Owner != nil {
+		update.Owner = *r.Owner
+	}
+	if err := update.Validate(); err != nil {
+		return err
+	}
+	item.Title, item.Owner = update.Title, update.Owner
+	if r.Done != nil {
+		item.Done = *r.Done
+	}
+	return nil
+}
+
+// BoardColumn is a column of a retro board with its visible cards
+type BoardColumn struct {
+	RetroColumn
+	Cards  []Feedback `json:"cards"`
+	Hidden int        `json:"hidden"` // Cards of other participants while cards are hidden
+}
+
+// RetroBoard is the state of a retro room as shown to the facilitator or to one
+// participant
+type RetroBoard struct {
+	Phase               RetroPhase    `json:"phase"`
+	VotesPerParticipant int           `json:"votes_per_participant"`
+	VotesUsed           int           `json:"votes_used"` // By the participant viewing the board
+	MyVotes             map[int]int   `json:"my_votes"`   // Dots of the viewing participant per card
+	Columns             []BoardColumn `json:"columns"`
+	Groups              []CardGroup   `json:"groups"`
+	ActionItems         []ActionItem  `json:"action_items"`
+}
+
+// VoteLimits bounds the votes of one participant; zero means unlimited
+type VoteLimits struct {
+	PerEntry int // Votes on a single entry
+	PerRoom  int // Votes across all entries of the room
+}
+
+// normalizeTitle trims a title and checks its length
+func normalizeTitle(title string, max int) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > max {
+		return "", fmt.Errorf("title must be 1 to %d characters", max)
+	}
+	return title, nil
+}
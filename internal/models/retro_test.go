@@ -0,0 +1,37 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetColumnsRequestValidate(t *testing.T) {
+	existing := []RetroColumn{{ID: 1, Position: 1, Title: "Went well"}, {ID: 2, Position: 2, Title: "To improve"}}
+
+	tests := []struct {
+		name    string
+		columns []ColumnInput
+		want    []RetroColumn
+		wantErr bool
+	}{
+		{"reorder and add", []ColumnInput{{ID: 2, Title: " To improve "}, {Title: "Ideas"}, {ID: 1, Title: "Went well"}},
+			[]RetroColumn{{ID: 2, Position: 1, Title: "To improve"}, {Position: 2, Title: "Ideas"}, {ID: 1, Position: 3, Title: "Went well"}}, false},
+		{"unknown column", []ColumnInput{{ID: 3, Title: "Other"}}, nil, true},
+		{"column listed twice", []ColumnInput{{ID: 1, Title: "A"}, {ID: 1, Title: "B"}}, nil, true},
+		{"blank title", []ColumnInput{{Title: "  "}}, nil, true},
+		{"long title", []ColumnInput{{Title: strings.Repeat("x", MaxRetroTitle+1)}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := SetColumnsRequest{Columns: tt.columns}
+			got, err := req.Validate(existing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
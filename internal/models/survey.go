@@ -0,0 +1,467 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// QuestionType is the kind of answer a survey question takes
+type QuestionType string
+
+const (
+	QuestionText           QuestionType = "text"            // Free text
+	QuestionRating         QuestionType = "rating"          // Integer from 1 to 5
+	QuestionNPS            QuestionType = "nps"             // Integer from 0 to 10
+	QuestionSingleChoice   QuestionType = "single_choice"   // One of the options
+	QuestionMultipleChoice QuestionType = "multiple_choice" // One or more of the options
+	QuestionYesNo          QuestionType = "yes_no"          // true or false
+)
+
+// Survey limits
+const (
+	MaxQuestions        = 50
+	MaxQuestionOptions  = 20
+	MaxQuestionPrompt   = 500  // Characters
+	MaxQuestionOption   = 200  // Characters
+	MaxTextAnswerLength = 5000 // Characters
+	MinQuestionOptions  = 2
+)
+
+// Valid reports whether t is a known question type
+func (t QuestionType) Valid() bool {
+	switch t {
+	case QuestionText, QuestionRating, QuestionNPS, QuestionSingleChoice, QuestionMultipleChoice, QuestionYesNo:
+		return true
+	}
+	return false
+}
+
+// HasOptions reports whether questions of type t choose from a list of options
+func (t QuestionType) HasOptions() bool {
+	return t == QuestionSingleChoice || t == QuestionMultipleChoice
+}
+
+// Question is one question of a survey room
+type Question struct {
+	ID       int          `json:"id" db:"id"`
+	RoomID   string       `json:"room_id" db:"room_id"`
+	Position int          `json:"position" db:"position"` // From 1, in the order questions are asked
+	Type     QuestionType `json:"type" db:"type"`
+	Prompt   string       `json:"prompt" db:"prompt"`
+	Required bool         `json:"required" db:"required"`
+	Options  []string     `json:"options,omitempty" db:"options"`
+}
+
+// Answer is the value given to one question: a string for text and single
+// choice, an integer for rating and NPS, an array of strings for multiple
+// choice and a boolean for yes/no questions
+type Answer struct {
+	QuestionID int             `json:"question_id" db:"question_id"`
+	Value      json.RawMessage `json:"value" db:"value"`
+}
+
+// QuestionInput defines a question when setting a room's questions
+type QuestionInput struct {
+	ID       int          `json:"id"` // An existing question to keep, with its answers; 0 adds one
+	Type     QuestionType `json:"type" binding:"required"`
+	Prompt   string       `json:"prompt" binding:"required"`
+	Required bool         `json:"required"`
+	Options  []string     `json:"options"`
+}
+
+// SetQuestionsRequest replaces the questions of a room. Questions left out are
+// deleted; those that have answers only with force, which deletes the answers
+// too. An empty list turns the survey off.
+type SetQuestionsRequest struct {
+	Questions []QuestionInput `json:"questions" binding:"max=50"`
+	Force     bool            `json:"force"`
+}
+
+// QuestionUsage counts the stored answers to a question and, for choice
+// questions, the answers picking each option
+type QuestionUsage struct {
+	Answers int
+	Options map[string]int
+}
+
+// Add counts n stored answers of value to a question of type t
+func (u *QuestionUsage) Add(t QuestionType, value json.RawMessage, n int) {
+	u.Answers += n
+	var picked []string
+	switch t {
+	case QuestionSingleChoice:
+		var choice string
+		if json.Unmarshal(value, &choice) == nil {
+			picked = []string{choice}
+		}
+	case QuestionMultipleChoice:
+		json.Unmarshal(value, &picked)
+	}
+	for _, option := range picked {
+		if u.Options == nil {
+			u.Options = make(map[string]int)
+		}
+		u.Options[option] += n
+	}
+}
+
+// AnswersInUseError is returned by SetQuestionsRequest.Validate for changes
+// that would delete or orphan stored answers
+type AnswersInUseError struct {
+	message string
+}
+
+func (e *AnswersInUseError) Error() string {
+	return e.message
+}
+
+// Validate checks the questions against the room's current ones and the
+// answers stored for them, and returns them numbered in order. Kept questions
+// cannot change type, since their answers were validated against it, nor drop
+// or rename options that answers picked.
+func (r *SetQuestionsRequest) Validate(existing []Question, usage map[int]QuestionUsage) ([]Question, error) {
+	types := make(map[int]QuestionType, len(existing))
+	for _, q := range existing {
+		types[q.ID] = q.Type
+	}
+
+	seen := make(map[int]bool, len(r.Questions))
+	questions := make([]Question, 0, len(r.Questions))
+	for i, input := range r.Questions {
+		n := i + 1
+		q := Question{
+			ID:       input.ID,
+			Position: n,
+			Type:     input.Type,
+			Prompt:   strings.TrimSpace(input.Prompt),
+			Required: input.Required,
+		}
+
+		if q.ID != 0 {
+			current, ok := types[q.ID]
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("question %d: unknown question id %d", n, q.ID)
+			case seen[q.ID]:
+				return nil, fmt.Errorf("question %d: question id %d is listed twice", n, q.ID)
+			case current != q.Type:
+				return nil, fmt.Errorf("question %d: the type of an existing question cannot change", n)
+			}
+			seen[q.ID] = true
+		}
+		if !q.Type.Valid() {
+			return nil, fmt.Errorf("question %d: type must be text, rating, nps, single_choice, multiple_choice or yes_no", n)
+		}
+		if q.Prompt == "" || utf8.RuneCountInString(q.Prompt) > MaxQuestionPrompt {
+			return nil, fmt.Errorf("question %d: prompt must be 1 to %d characters", n, MaxQuestionPrompt)
+		}
+
+		options, err := normalizeOptions(q.Type, input.Options)
+		if err != nil {
+			return nil, fmt.Errorf("question %d: %w", n, err)
+		}
+		q.Options = options
+		if err := q.checkPickedOptions(usage[q.ID]); err != nil {
+			return nil, fmt.Errorf("question %d: %w", n, err)
+		}
+		questions = append(questions, q)
+	}
+
+	if !r.Force {
+		for _, q := range existing {
+			if n := usage[q.ID].Answers; !seen[q.ID] && n > 0 {
+				return nil, &AnswersInUseError{fmt.Sprintf(
+					"question %q has %d answers; set force to delete it with its answers", q.Prompt, n)}
+			}
+		}
+	}
+	return questions, nil
+}
+
+// checkPickedOptions checks that q still offers every option picked in the
+// stored answers
+func (q *Question) checkPickedOptions(usage QuestionUsage) error {
+	missing := []string{}
+	for option, n := range usage.Options {
+		if n > 0 && !q.hasOption(option) {
+			missing = append(missing, option)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return &AnswersInUseError{fmt.Sprintf(
+		"option %q is picked in %d answers and cannot be removed or renamed", missing[0], usage.Options[missing[0]])}
+}
+
+// normalizeOptions trims the options of a choice question and checks them
+func normalizeOptions(t QuestionType, options []string) ([]string, error) {
+	if !t.HasOptions() {
+		if len(options) > 0 {
+			return nil, errors.New("only choice questions have options")
+		}
+		return nil, nil
+	}
+
+	if len(options) < MinQuestionOptions || len(options) > MaxQuestionOptions {
+		return nil, fmt.Errorf("choice questions need %d to %d options", MinQuestionOptions, MaxQuestionOptions)
+	}
+	seen := make(map[string]bool, len(options))
+	normalized := make([]string, 0, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || utf8.RuneCountInString(option) > MaxQuestionOption {
+			return nil, fmt.Errorf("options must be 1 to %d characters", MaxQuestionOption)
+		}
+		if seen[option] {
+			return nil, fmt.Errorf("option %q is listed twice", option)
+		}
+		seen[option] = true
+		normalized = append(normalized, option)
+	}
+	return normalized, nil
+}
+
+// ValidateAnswers checks answers against a room's questions and returns them
+// normalised, in question order. Null values count as unanswered.
+func ValidateAnswers(questions []Question, answers []Answer) ([]Answer, error) {
+	given := make(map[int]json.RawMessage, len(answers))
+	for _, a := range answers {
+		if _, ok := given[a.QuestionID]; ok {
+			return nil, fmt.Errorf("question id %d is answered twice", a.QuestionID)
+		}
+		given[a.QuestionID] = a.Value
+	}
+
+	validated := []Answer{}
+	for _, q := range questions {
+		raw, ok := given[q.ID]
+		delete(given, q.ID)
+		if !ok || isNull(raw) {
+			if q.Required {
+				return nil, fmt.Errorf("question %d is required", q.Position)
+			}
+			continue
+		}
+
+		value, err := q.normalizeAnswer(raw)
+		if err != nil {
+			return nil, fmt.Errorf("question %d: %w", q.Position, err)
+		}
+		if value == nil {
+			if q.Required {
+				return nil, fmt.Errorf("question %d is required", q.Position)
+			}
+			continue
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		validated = append(validated, Answer{QuestionID: q.ID, Value: encoded})
+	}
+
+	for id := range given {
+		return nil, fmt.Errorf("question id %d does not belong to this room", id)
+	}
+	return validated, nil
+}
+
+// normalizeAnswer decodes and checks an answer to q. It returns nil for empty
+// text and empty selections, which count as unanswered.
+func (q *Question) normalizeAnswer(raw json.RawMessage) (interface{}, error) {
+	switch q.Type {
+	case QuestionText:
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, errors.New("answer must be a string")
+		}
+		text = strings.TrimSpace(text)
+		if utf8.RuneCountInString(text) > MaxTextAnswerLength {
+			return nil, fmt.Errorf("answer must be at most %d characters", MaxTextAnswerLength)
+		}
+		if text == "" {
+			return nil, nil
+		}
+		return text, nil
+
+	case QuestionRating, QuestionNPS:
+		low, high := 1, 5
+		if q.Type == QuestionNPS {
+			low, high = 0, 10
+		}
+		var number float64
+		if err := json.Unmarshal(raw, &number); err != nil || number != math.Trunc(number) ||
+			number < float64(low) || number > float64(high) {
+			return nil, fmt.Errorf("answer must be a whole number from %d to %d", low, high)
+		}
+		return int(number), nil
+
+	case QuestionSingleChoice:
+		var choice string
+		if err := json.Unmarshal(raw, &choice); err != nil || !q.hasOption(choice) {
+			return nil, errors.New("answer must be one of the options")
+		}
+		return choice, nil
+
+	case QuestionMultipleChoice:
+		var choices []string
+		if err := json.Unmarshal(raw, &choices); err != nil {
+			return nil, errors.New("answer must be an array of options")
+		}
+		picked := make(map[string]bool, len(choices))
+		for _, choice := range choices {
+			if !q.hasOption(choice) {
+				return nil, fmt.Errorf("%q is not one of the options", choice)
+			}
+			picked[choice] = true
+		}
+		if len(picked) == 0 {
+			return nil, nil
+		}
+		// Keep the order of the options and drop repeats
+		ordered := make([]string, 0, len(picked))
+		for _, option := range q.Options {
+			if picked[option] {
+				ordered = append(ordered, option)
+			}
+		}
+		return ordered, nil
+
+	case QuestionYesNo:
+		var yes bool
+		if err := json.Unmarshal(raw, &yes); err != nil {
+			return nil, errors.New("answer must be true or false")
+		}
+		return yes, nil
+	}
+	return nil, fmt.Errorf("unsupported question type %q", q.Type)
+}
+
+// hasOption reports whether option is one of the question's options
+func (q *Question) hasOption(option string) bool {
+	for _, o := range q.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// SurveyContent joins the free-text answers of a submission, in question order,
+// for use as the content of survey feedback submitted without a comment
+func SurveyContent(questions []Question, answers []Answer) string {
+	text := make(map[int]string, len(answers))
+	for _, a := range answers {
+		var s string
+		if json.Unmarshal(a.Value, &s) == nil {
+			text[a.QuestionID] = s
+		}
+	}
+
+	parts := []string{}
+	for _, q := range questions {
+		if s, ok := text[q.ID]; ok && q.Type == QuestionText {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// isNull reports whether raw is missing or the JSON null literal
+func isNull(raw json.RawMessage) bool {
+	trimmed := strings.TrimSpace(string(raw))
+	return trimmed == "" || trimmed == "null"
+}
+
+// NPS groups of 0–10 answers
+const (
+	NPSPromoter  = "promoter"  // 9 or 10
+	NPSPassive   = "passive"   // 7 or 8
+	NPSDetractor = "detractor" // 0 to 6
+)
+
+// NPSGroup classifies an answer to an NPS question
+func NPSGroup(value int) string {
+	switch {
+	case value >= 9:
+		return NPSPromoter
+	case value >= 7:
+		return NPSPassive
+	default:
+		return NPSDetractor
+	}
+}
+
+// CSATSatisfied reports whether a 1–5 rating counts as satisfied for CSAT
+func CSATSatisfied(rating int) bool {
+	return rating >= 4
+}
+
+// NPSScore is the Net Promoter Score of answers to NPS questions: the
+// percentage of promoters minus the percentage of detractors
+type NPSScore struct {
+	Responses  int      `json:"responses"`
+	Promoters  int      `json:"promoters"`
+	Passives   int      `json:"passives"`
+	Detractors int      `json:"detractors"`
+	Score      *float64 `json:"score"` // From -100 to 100; null without responses
+}
+
+// Add counts n answers of value
+func (s *NPSScore) Add(value, n int) {
+	s.Responses += n
+	switch NPSGroup(value) {
+	case NPSPromoter:
+		s.Promoters += n
+	case NPSPassive:
+		s.Passives += n
+	default:
+		s.Detractors += n
+	}
+	score := roundTenth(float64(s.Promoters-s.Detractors) * 100 / float64(s.Responses))
+	s.Score = &score
+}
+
+// CSATScore is the customer satisfaction of answers to rating questions: the
+// percentage of ratings of 4 or 5
+type CSATScore struct {
+	Responses     int      `json:"responses"`
+	Satisfied     int      `json:"satisfied"`
+	Score         *float64 `json:"score"` // Percentage; null without responses
+	AverageRating *float64 `json:"average_rating"`
+	ratingSum     int
+}
+
+// Add counts n ratings of value
+func (s *CSATScore) Add(rating, n int) {
+	s.Responses += n
+	s.ratingSum += rating * n
+	if CSATSatisfied(rating) {
+		s.Satisfied += n
+	}
+	score := roundTenth(float64(s.Satisfied) * 100 / float64(s.Responses))
+	average := math.Round(float64(s.ratingSum)/float64(s.Responses)*100) / 100
+	s.Score, s.AverageRating = &score, &average
+}
+
+// QuestionScore is the NPS or CSAT of a single question
+type QuestionScore struct {
+	QuestionID int          `json:"question_id"`
+	Prompt     string       `json:"prompt"`
+	Type       QuestionType `json:"type"`
+	NPS        *NPSScore    `json:"nps,omitempty"`
+	CSAT       *CSATScore   `json:"csat,omitempty"`
+}
+
+// roundTenth rounds x to one decimal place
+func roundTenth(x float64) float64 {
+	return math.Round(x*10) / 10
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSetQuestionsRequestValidateKeepsAnswers(t *testing.T) {
+	existing := []Question{
+		{ID: 1, Position: 1, Type: QuestionText, Prompt: "Why?"},
+		{ID: 2, Position: 2, Type: QuestionSingleChoice, Prompt: "Role", Options: []string{"Dev", "PM"}},
+		{ID: 3, Position: 3, Type: QuestionMultipleChoice, Prompt: "Topics", Options: []string{"API", "UI", "Docs"}},
+		{ID: 4, Position: 4, Type: QuestionText, Prompt: "Unused"},
+	}
+	usage := map[int]QuestionUsage{}
+	for _, a := range []struct {
+		id    int
+		value string
+	}{{1, `"x"`}, {2, `"PM"`}, {3, `["UI","API"]`}} {
+		u := usage[a.id]
+		u.Add(existing[a.id-1].Type, json.RawMessage(a.value), 1)
+		usage[a.id] = u
+	}
+
+	kept := []QuestionInput{
+		{ID: 1, Type: QuestionText, Prompt: "Why?"},
+		{ID: 2, Type: QuestionSingleChoice, Prompt: "Role", Options: []string{"Dev", "PM", "QA"}},
+		{ID: 3, Type: QuestionMultipleChoice, Prompt: "Topics", Options: []string{"API", "UI"}},
+	}
+	with := func(i int, input QuestionInput) []QuestionInput {
+		questions := append([]QuestionInput{}, kept...)
+		questions[i] = input
+		return questions
+	}
+
+	tests := []struct {
+		name      string
+		req       SetQuestionsRequest
+		wantInUse bool
+	}{
+		{"unanswered question and unpicked option dropped", SetQuestionsRequest{Questions: kept}, false},
+		{"answered question dropped", SetQuestionsRequest{Questions: kept[1:]}, true},
+		{"answered question dropped with force", SetQuestionsRequest{Questions: kept[1:], Force: true}, false},
+		{"picked option renamed", SetQuestionsRequest{Questions: with(1, QuestionInput{
+			ID: 2, Type: QuestionSingleChoice, Prompt: "Role", Options: []string{"Dev", "Product"},
+		})}, true},
+		{"picked option dropped with force", SetQuestionsRequest{Force: true, Questions: with(2, QuestionInput{
+			ID: 3, Type: QuestionMultipleChoice, Prompt: "Topics", Options: []string{"API", "Docs"},
+		})}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.req.Validate(existing, usage)
+			var inUse *AnswersInUseError
+			if got := errors.As(err, &inUse); got != tt.wantInUse {
+				t.Errorf("Validate() error = %v, want in-use error %v", err, tt.wantInUse)
+			}
+			if !tt.wantInUse && err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
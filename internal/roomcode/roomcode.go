@@ -0,0 +1,110 @@
+// Package roomcode generates short, human-friendly room codes and validates
+// owner-chosen vanity slugs.
+package roomcode
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// DefaultAlphabet leaves out characters that are easily confused when read aloud
+// or copied by hand: 0/O, 1/I/l and lower-case letters. Generated codes are
+// upper case whatever the alphabet; slugs are lower case.
+const DefaultAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// DefaultLength gives 31^6 (~887 million) possible codes with the default alphabet
+const DefaultLength = 6
+
+const (
+	minLength  = 4
+	maxSlugLen = 50
+)
+
+// Generator creates random room codes from an alphabet
+type Generator struct {
+	alphabet []rune
+	length   int
+}
+
+// NewGenerator validates the alphabet and length and returns a generator.
+// Letters are upper-cased, so "a" and "A" count as the same character.
+func NewGenerator(alphabet string, length int) (*Generator, error) {
+	runes := []rune(strings.ToUpper(alphabet))
+	if len(runes) < 2 {
+		return nil, errors.New("room code alphabet needs at least two characters")
+	}
+
+	seen := make(map[rune]bool, len(runes))
+	for _, r := range runes {
+		if seen[r] {
+			return nil, fmt.Errorf("room code alphabet contains %q more than once", r)
+		}
+		if !strings.ContainsRune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", r) {
+			return nil, fmt.Errorf("room code alphabet may only contain letters and digits, got %q", r)
+		}
+		seen[r] = true
+	}
+
+	if length < minLength || length > maxSlugLen {
+		return nil, fmt.Errorf("room code length must be between %d and %d", minLength, maxSlugLen)
+	}
+
+	return &Generator{alphabet: runes, length: length}, nil
+}
+
+// Generate returns a new random code
+func (g *Generator) Generate() (string, error) {
+	max := big.NewInt(int64(len(g.alphabet)))
+	code := make([]rune, g.length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate room code: %w", err)
+		}
+		code[i] = g.alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// Variants returns the IDs a room typed as id may be stored under, exact match
+// first: as a generated code in upper case and as a slug in lower case
+func Variants(id string) []string {
+	variants := []string{id}
+	if upper := strings.ToUpper(id); upper != id {
+		variants = append(variants, upper)
+	}
+	if lower := strings.ToLower(id); lower != id {
+		variants = append(variants, lower)
+	}
+	return variants
+}
+
+var slugRe = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reserved slugs would shadow routes or mislead participants
+var reserved = map[string]bool{
+	"admin": true, "api": true, "app": true, "auth": true, "dashboard": true,
+	"export": true, "feedback": true, "health": true, "help": true, "import": true,
+	"join": true, "login": true, "logout": true, "me": true, "new": true,
+	"public": true, "register": true, "room": true, "rooms": true, "search": true,
+	"settings": true, "static": true, "stream": true, "support": true, "www": true,
+}
+
+// ValidateSlug checks an owner-chosen room slug: 3-50 lower-case letters, digits and
+// single hyphens, not starting or ending with a hyphen, and not a reserved word
+func ValidateSlug(slug string) error {
+	if len(slug) < 3 || len(slug) > maxSlugLen {
+		return fmt.Errorf("slug must be between 3 and %d characters", maxSlugLen)
+	}
+	if !slugRe.MatchString(slug) {
+		return errors.New("slug may only contain lower-case letters, digits and single hyphens")
+	}
+	if reserved[slug] {
+		return fmt.Errorf("slug %q is reserved", slug)
+	}
+	return nil
+}
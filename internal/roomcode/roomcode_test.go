@@ -0,0 +1,42 @@
+package roomcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVariants(t *testing.T) {
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"ABC234", []string{"ABC234", "abc234"}},
+		{"abc234", []string{"abc234", "ABC234"}},
+		{"Team-Retro", []string{"Team-Retro", "TEAM-RETRO", "team-retro"}},
+		{"2345", []string{"2345"}},
+	}
+	for _, tt := range tests {
+		if got := Variants(tt.id); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Variants(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestGeneratorUpperCasesAlphabet(t *testing.T) {
+	g, err := NewGenerator("abc234", DefaultLength)
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	code, err := g.Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if code != strings.ToUpper(code) {
+		t.Errorf("Generate() = %q, want upper case", code)
+	}
+
+	if _, err := NewGenerator("aA23", DefaultLength); err == nil {
+		t.Error("NewGenerator accepted an alphabet with a letter in both cases")
+	}
+}
@@ -0,0 +1,100 @@
+// Package scheduler moves rooms through their scheduled lifecycle, opening draft
+// rooms at opens_at and closing open rooms at closes_at.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// Scheduler periodically applies room schedules and publishes an event per transition
+type Scheduler struct {
+	store     db.RoomStore
+	publisher events.Publisher
+	interval  time.Duration
+}
+
+// New creates a scheduler that checks for due rooms every interval
+func New(store db.RoomStore, publisher events.Publisher, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		publisher: publisher,
+		interval:  interval,
+	}
+}
+
+// Run applies schedules immediately and then on every tick until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Tick(time.Now()); err != nil {
+			log.Printf("Room scheduler failed: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick transitions every room whose schedule is due at now
+func (s *Scheduler) Tick(now time.Time) error {
+	rooms, err := s.store.GetRoomsDueForTransition(now)
+	if err != nil {
+		return err
+	}
+
+	for i := range rooms {
+		s.apply(&rooms[i], now)
+	}
+	return nil
+}
+
+// apply walks a room to its scheduled status one transition at a time, so that a
+// draft room whose whole window has already passed is reported as opened and then closed
+func (s *Scheduler) apply(room *models.Room, now time.Time) {
+	for {
+		target := room.ScheduledStatus(now)
+		if target == room.Status {
+			return
+		}
+
+		next := target
+		if room.Status == models.RoomStatusDraft {
+			next = models.RoomStatusOpen
+		}
+
+		// The conditional update loses to concurrent owner edits and to other replicas
+		moved, err := s.store.TransitionRoomStatus(room.ID, room.Status, next)
+		if err != nil {
+			log.Printf("Failed to move room %s from %s to %s: %v\n", room.ID, room.Status, next, err)
+			return
+		}
+		if !moved {
+			return
+		}
+
+		room.Status = next
+		s.publisher.Publish(events.Event{
+			Type:   eventType(next),
+			RoomID: room.ID,
+			Data:   room.Public(),
+		})
+	}
+}
+
+func eventType(status models.RoomStatus) string {
+	if status == models.RoomStatusOpen {
+		return events.RoomOpened
+	}
+	return events.RoomClosed
+}
@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// forEachStore runs test against a fresh SQLite store and a fresh in-memory store
+func forEachStore(t *testing.T, test func(t *testing.T, store db.Store)) {
+	t.Helper()
+
+	t.Run(db.DriverSQLite, func(t *testing.T) {
+		store, err := db.NewSQLite("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("NewSQLite() error: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		test(t, store)
+	})
+	t.Run(db.DriverMemory, func(t *testing.T) {
+		store := db.NewMemory()
+		t.Cleanup(func() { store.Close() })
+		test(t, store)
+	})
+}
+
+// recorder collects published events
+type recorder struct {
+	events []events.Event
+}
+
+func (r *recorder) Publish(event events.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestTick(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		ts := now.Add(d)
+		return &ts
+	}
+
+	tests := []struct {
+		name       string
+		status     models.RoomStatus
+		opensAt    *time.Time
+		closesAt   *time.Time
+		wantStatus models.RoomStatus
+		wantEvents []string
+	}{
+		{"draft due to open", models.RoomStatusDraft, at(-time.Minute), at(time.Hour), models.RoomStatusOpen, []string{events.RoomOpened}},
+		{"draft opening exactly now", models.RoomStatusDraft, at(0), nil, models.RoomStatusOpen, []string{events.RoomOpened}},
+		{"draft not due yet", models.RoomStatusDraft, at(time.Minute), nil, models.RoomStatusDraft, nil},
+		{"draft without opens_at", models.RoomStatusDraft, nil, at(-time.Minute), models.RoomStatusDraft, nil},
+		{"open due to close", models.RoomStatusOpen, nil, at(-time.Minute), models.RoomStatusClosed, []string{events.RoomClosed}},
+		{"open not due yet", models.RoomStatusOpen, at(-time.Hour), at(time.Minute), models.RoomStatusOpen, nil},
+		{"draft whose window has passed", models.RoomStatusDraft, at(-2 * time.Hour), at(-time.Hour), models.RoomStatusClosed,
+			[]string{events.RoomOpened, events.RoomClosed}},
+		{"closed room stays closed", models.RoomStatusClosed, at(-2 * time.Hour), at(time.Hour), models.RoomStatusClosed, nil},
+		{"archived room stays archived", models.RoomStatusArchived, nil, at(-time.Hour), models.RoomStatusArchived, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachStore(t, func(t *testing.T, store db.Store) {
+				user, err := store.CreateUser("owner@example.com", "password")
+				if err != nil {
+					t.Fatalf("CreateUser() error: %v", err)
+				}
+				room := &models.Room{
+					ID:        "SCHEDULED",
+					Name:      "Scheduled",
+					Password:  "hash",
+					CreatorID: user.ID,
+					Status:    tt.status,
+					OpensAt:   tt.opensAt,
+					ClosesAt:  tt.closesAt,
+				}
+				if err := store.CreateRoom(room); err != nil {
+					t.Fatalf("CreateRoom() error: %v", err)
+				}
+
+				published := &recorder{}
+				if err := New(store, published, time.Minute).Tick(now); err != nil {
+					t.Fatalf("Tick() error: %v", err)
+				}
+
+				got, err := store.GetRoomByID(room.ID)
+				if err != nil {
+					t.Fatalf("GetRoomByID() error: %v", err)
+				}
+				if got.Status != tt.wantStatus {
+					t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+				}
+
+				types := []string{}
+				for _, event := range published.events {
+					types = append(types, event.Type)
+					summary, ok := event.Data.(models.Room)
+					if event.RoomID != room.ID || !ok || summary.Password != "" {
+						t.Errorf("%s event = %+v, want the room without its password", event.Type, event)
+					}
+				}
+				if len(tt.wantEvents) == 0 {
+					tt.wantEvents = []string{}
+				}
+				if !reflect.DeepEqual(types, tt.wantEvents) {
+					t.Errorf("events = %v, want %v", types, tt.wantEvents)
+				}
+
+				// Applying the same schedule again changes nothing
+				published.events = nil
+				if err := New(store, published, time.Minute).Tick(now); err != nil {
+					t.Fatalf("second Tick() error: %v", err)
+				}
+				if len(published.events) != 0 {
+					t.Errorf("second tick published %d events, want none", len(published.events))
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package sentiment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/panaalexandrucristian/feedback-collector/internal/db"
+	"github.com/panaalexandrucristian/feedback-collector/internal/events"
+	"github.com/panaalexandrucristian/feedback-collector/internal/jobs"
+	"github.com/panaalexandrucristian/feedback-collector/internal/models"
+)
+
+// JobKind is the job enqueued for every new feedback entry
+const JobKind = "sentiment.analyze"
+
+// JobPayload identifies the feedback entry to analyse
+type JobPayload struct {
+	FeedbackID int `json:"feedback_id"`
+}
+
+// Store loads feedback and persists analysis results
+type Store interface {
+	GetFeedbackByID(id int) (*models.Feedback, error)
+	UpdateFeedbackSentiment(id int, sentiment string, score float64) error
+}
+
+// NewJobHandler returns the handler for JobKind jobs, publishing the analysed
+// feedback as a FeedbackSentiment event. Feedback deleted in the meantime is
+// skipped rather than retried.
+func NewJobHandler(analyzer Analyzer, store Store, publisher events.Publisher) jobs.Handler {
+	return func(ctx context.Context, job *models.Job) error {
+		var payload JobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+
+		feedback, err := store.GetFeedbackByID(payload.FeedbackID)
+		if errors.Is(err, db.ErrFeedbackNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err := analyzer.Analyze(feedback.Content)
+		if err != nil {
+			return err
+		}
+		if err := store.UpdateFeedbackSentiment(feedback.ID, result.Label, result.Score); err != nil {
+			return err
+		}
+
+		feedback.Sentiment = result.Label
+		feedback.SentimentScore = &result.Score
+		publisher.Publish(events.Event{Type: events.FeedbackSentiment, RoomID: feedback.RoomID, Data: feedback})
+		return nil
+	}
+}
+
+// Process analyses one feedback entry and stores the result
+func Process(analyzer Analyzer, store Store, feedbackID int, content string) error {
+	result, err := analyzer.Analyze(content)
+	if err != nil {
+		return err
+	}
+	return store.UpdateFeedbackSentiment(feedbackID, result.Label, result.Score)
+}
@@ -0,0 +1,74 @@
+package sentiment
+
+// negations flip the polarity of the next few words
+var negations = map[string]bool{
+	"not": true, "no": true, "never": true, "none": true, "nothing": true,
+	"neither": true, "nor": true, "without": true, "hardly": true, "barely": true,
+	"isn't": true, "isnt": true, "wasn't": true, "wasnt": true, "aren't": true,
+	"arent": true, "weren't": true, "werent": true, "don't": true, "dont": true,
+	"doesn't": true, "doesnt": true, "didn't": true, "didnt": true, "can't": true,
+	"cant": true, "cannot": true, "couldn't": true, "couldnt": true, "won't": true,
+	"wont": true, "wouldn't": true, "wouldnt": true, "shouldn't": true, "shouldnt": true,
+	"haven't": true, "havent": true, "hasn't": true, "hasnt": true, "ain't": true,
+}
+
+// boosters strengthen (positive factor) or soften (negative factor) the next word
+var boosters = map[string]float64{
+	"very": 0.3, "really": 0.3, "extremely": 0.5, "super": 0.4, "so": 0.2,
+	"incredibly": 0.5, "absolutely": 0.5, "totally": 0.4, "highly": 0.4, "truly": 0.3,
+	"especially": 0.3, "particularly": 0.3, "quite": 0.2, "too": 0.2, "most": 0.3,
+	"slightly": -0.3, "somewhat": -0.3, "kinda": -0.3, "bit": -0.3, "marginally": -0.4,
+	"partly": -0.3, "little": -0.2,
+}
+
+// defaultLexicon maps words to a weight between -4 (very negative) and 4 (very positive)
+var defaultLexicon = map[string]float64{
+	// Positive
+	"amazing": 3.1, "awesome": 3.1, "excellent": 3.2, "fantastic": 3.2, "outstanding": 3.3,
+	"superb": 3.1, "brilliant": 2.9, "wonderful": 3.0, "perfect": 3.0, "incredible": 2.9,
+	"love": 3.2, "loved": 2.9, "loving": 2.8, "loves": 2.7, "great": 3.1,
+	"good": 1.9, "nice": 1.8, "fine": 0.8, "ok": 0.6, "okay": 0.6,
+	"happy": 2.7, "glad": 2.0, "pleased": 1.9, "satisfied": 1.8, "delighted": 3.0,
+	"enjoy": 2.2, "enjoyed": 2.3, "enjoyable": 2.1, "fun": 2.3, "exciting": 2.2,
+	"helpful": 1.9, "useful": 1.9, "valuable": 2.1, "effective": 1.8, "efficient": 1.8,
+	"easy": 1.6, "simple": 1.0, "smooth": 1.6, "intuitive": 1.9, "clear": 1.3,
+	"fast": 1.4, "quick": 1.2, "reliable": 1.9, "stable": 1.3, "solid": 1.6,
+	"impressive": 2.4, "impressed": 2.4, "beautiful": 2.9, "clean": 1.5, "elegant": 2.1,
+	"friendly": 2.2, "kind": 1.9, "supportive": 2.1, "welcoming": 2.0, "responsive": 1.6,
+	"thanks": 1.9, "thank": 1.5, "thankful": 2.3, "grateful": 2.6, "appreciate": 2.2,
+	"appreciated": 2.2, "recommend": 1.8, "recommended": 1.8, "best": 3.2, "better": 1.9,
+	"improved": 2.0, "improvement": 1.8, "improving": 1.6, "progress": 1.5, "success": 2.7,
+	"successful": 2.6, "win": 2.8, "wins": 2.6, "works": 1.0, "worked": 1.0,
+	"productive": 1.9, "motivated": 1.9, "motivating": 1.9, "inspiring": 2.5, "inspired": 2.3,
+	"engaging": 2.0, "engaged": 1.6, "insightful": 2.3, "informative": 1.7, "interesting": 1.7,
+	"comfortable": 1.5, "safe": 1.5, "secure": 1.5, "confident": 2.0, "calm": 1.3,
+	"collaborative": 1.8, "transparent": 1.6, "organized": 1.5, "organised": 1.5, "fair": 1.3,
+	"positive": 2.3, "favorite": 2.0, "favourite": 2.0, "cool": 1.3, "neat": 1.6,
+	"liked": 1.8, "likes": 1.6, "wow": 2.8,
+	"yay": 2.4, "congrats": 2.4, "congratulations": 2.9, "kudos": 2.6, "bravo": 2.6,
+
+	// Negative
+	"terrible": -3.1, "horrible": -3.1, "awful": -3.1, "worst": -3.4, "hate": -2.7,
+	"hated": -3.2, "hates": -2.7, "disgusting": -2.9, "dreadful": -3.0, "atrocious": -3.2,
+	"bad": -2.5, "poor": -2.1, "worse": -2.1, "wrong": -2.1, "broken": -2.3,
+	"sad": -2.1, "unhappy": -1.8, "angry": -2.3, "annoyed": -1.6, "annoying": -1.7,
+	"frustrated": -2.4, "frustrating": -2.2, "frustration": -2.1, "disappointed": -1.9, "disappointing": -2.2,
+	"confusing": -1.3, "confused": -1.3, "unclear": -1.2, "complicated": -1.1, "difficult": -1.5,
+	"hard": -0.4, "slow": -1.3, "sluggish": -1.6, "laggy": -1.6, "buggy": -2.0,
+	"bug": -1.4, "bugs": -1.4, "crash": -2.0, "crashes": -2.0, "crashed": -2.0,
+	"error": -1.5, "errors": -1.5, "fail": -2.5, "failed": -2.3, "failure": -2.3,
+	"fails": -2.3, "failing": -2.3, "problem": -1.7, "problems": -1.7, "issue": -1.0,
+	"issues": -1.0, "useless": -1.8, "pointless": -1.9, "waste": -1.8, "wasted": -2.2,
+	"boring": -1.3, "bored": -1.1, "tedious": -1.6, "tired": -1.2, "exhausted": -1.9,
+	"stressful": -2.0, "stressed": -1.9, "stress": -1.8, "overwhelmed": -1.5, "overwhelming": -1.5,
+	"ugly": -2.3, "messy": -1.5, "mess": -1.5, "chaotic": -1.6, "chaos": -1.7,
+	"unfair": -2.1, "rude": -2.0, "toxic": -2.7, "hostile": -2.4, "unprofessional": -2.0,
+	"unreliable": -1.9, "unstable": -1.6, "unusable": -2.4, "unhelpful": -1.7, "lacking": -1.0,
+	"missing": -1.2, "lack": -1.0, "worried": -1.6, "worry": -1.6, "afraid": -2.0,
+	"fear": -2.2, "concern": -1.0, "concerns": -1.0, "concerned": -1.4, "risk": -1.1,
+	"blocked": -1.3, "blocker": -1.5, "blockers": -1.5, "delay": -1.3, "delayed": -1.4,
+	"late": -0.8, "expensive": -1.1, "overpriced": -1.9, "confusion": -1.2, "ridiculous": -2.0,
+	"sucks": -2.4, "suck": -2.4, "meh": -0.8, "ugh": -1.8, "lame": -1.8,
+	"painful": -2.2, "pain": -2.0, "hurt": -2.1, "sorry": -0.3, "negative": -2.3,
+	"ignored": -1.6, "unanswered": -1.2, "outdated": -1.2, "dislike": -1.6, "disliked": -1.7,
+}
@@ -0,0 +1,119 @@
+// Package sentiment classifies feedback text as positive, neutral or negative.
+// The built-in analyzer is lexicon based and runs entirely offline.
+package sentiment
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Labels stored in feedback.sentiment
+const (
+	Pending  = "pending"
+	Positive = "positive"
+	Neutral  = "neutral"
+	Negative = "negative"
+)
+
+// Result is the outcome of analysing a piece of text.
+// Score ranges from -1 (most negative) to 1 (most positive).
+type Result struct {
+	Label string  `json:"label"`
+	Score float64 `json:"score"`
+}
+
+// Analyzer classifies text
+type Analyzer interface {
+	Analyze(text string) (Result, error)
+}
+
+const (
+	// neutralThreshold is the absolute score under which text is considered neutral
+	neutralThreshold = 0.05
+	// normalizationAlpha controls how quickly the summed word weights approach +/-1
+	normalizationAlpha = 15.0
+	// negationWindow is how many following words a negation flips
+	negationWindow = 3
+	// negationFactor scales the weight of a negated word ("not great" is mildly negative)
+	negationFactor = -0.74
+	// exclamationBoost is added per exclamation mark, up to four of them
+	exclamationBoost = 0.3
+)
+
+// LexiconAnalyzer scores text by summing the weights of known words, taking
+// negations ("not good"), intensifiers ("very good") and exclamation marks into account
+type LexiconAnalyzer struct {
+	lexicon map[string]float64
+}
+
+// NewLexiconAnalyzer returns an analyzer using the built-in English lexicon
+func NewLexiconAnalyzer() *LexiconAnalyzer {
+	return &LexiconAnalyzer{lexicon: defaultLexicon}
+}
+
+// Analyze classifies text; it never returns an error
+func (a *LexiconAnalyzer) Analyze(text string) (Result, error) {
+	words := tokenize(text)
+
+	sum := 0.0
+	negateFor := 0
+	boost := 0.0
+	for _, word := range words {
+		if negations[word] {
+			negateFor = negationWindow
+			continue
+		}
+		if factor, ok := boosters[word]; ok {
+			boost = factor
+			continue
+		}
+
+		weight, ok := a.lexicon[word]
+		if ok {
+			if boost != 0 {
+				weight += math.Copysign(1, weight) * boost
+			}
+			if negateFor > 0 {
+				weight *= negationFactor
+			}
+			sum += weight
+		}
+
+		boost = 0
+		if negateFor > 0 {
+			negateFor--
+		}
+	}
+
+	if sum != 0 {
+		exclamations := math.Min(float64(strings.Count(text, "!")), 4)
+		sum += math.Copysign(exclamations*exclamationBoost, sum)
+	}
+
+	score := sum / math.Sqrt(sum*sum+normalizationAlpha)
+	score = math.Round(score*1000) / 1000
+
+	return Result{Label: label(score), Score: score}, nil
+}
+
+// label maps a normalised score to a sentiment label
+func label(score float64) string {
+	switch {
+	case score >= neutralThreshold:
+		return Positive
+	case score <= -neutralThreshold:
+		return Negative
+	default:
+		return Neutral
+	}
+}
+
+// tokenize lower-cases text and splits it into words, keeping apostrophes
+// so that contractions such as "don't" match the negation list
+func tokenize(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "’", "'")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
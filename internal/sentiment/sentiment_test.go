@@ -0,0 +1,63 @@
+package sentiment
+
+import "testing"
+
+func TestLexiconAnalyzer(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		label string
+		score float64
+	}{
+		{"empty", "", Neutral, 0},
+		{"unknown words", "the meeting", Neutral, 0},
+		{"plain positive", "good", Positive, 0.44},
+		{"plain negative", "bad", Negative, -0.542},
+		{"boosted positive", "very good", Positive, 0.494},
+		{"boosted negative", "very bad", Negative, -0.586},
+		{"softened positive", "slightly good", Positive, 0.382},
+		{"softened negative", "slightly bad", Negative, -0.494},
+		{"negated positive", "not good", Negative, -0.341},
+		{"negated negative", "not bad", Positive, 0.431},
+		{"negated boosted", "never really good", Negative, -0.388},
+		{"exclamation", "good!", Positive, 0.494},
+		{"exclamations capped", "good!!!!!!", Positive, 0.625},
+		{"exclamation negative", "bad!", Negative, -0.586},
+	}
+
+	a := NewLexiconAnalyzer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.Analyze(tt.text)
+			if err != nil {
+				t.Fatalf("Analyze(%q) error: %v", tt.text, err)
+			}
+			if got.Label != tt.label || got.Score != tt.score {
+				t.Errorf("Analyze(%q) = %s %v, want %s %v", tt.text, got.Label, got.Score, tt.label, tt.score)
+			}
+		})
+	}
+}
+
+func TestBoostersScaleMagnitude(t *testing.T) {
+	a := NewLexiconAnalyzer()
+	for _, word := range []string{"good", "bad"} {
+		plain, _ := a.Analyze(word)
+		boosted, _ := a.Analyze("very " + word)
+		softened, _ := a.Analyze("slightly " + word)
+
+		if abs(boosted.Score) <= abs(plain.Score) {
+			t.Errorf("very %s: |%v| should exceed |%v|", word, boosted.Score, plain.Score)
+		}
+		if abs(softened.Score) >= abs(plain.Score) {
+			t.Errorf("slightly %s: |%v| should be below |%v|", word, softened.Score, plain.Score)
+		}
+	}
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
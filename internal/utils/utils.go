@@ -17,9 +17,10 @@ func SanitizeInput(input string) string {
 	return sanitized
 }
 
-// ValidateRoomID checks if a room ID follows the expected format
+var roomIDRe = regexp.MustCompile("^[a-zA-Z0-9][a-zA-Z0-9-]{2,49}$")
+
+// ValidateRoomID checks if a room ID follows the expected format: a generated
+// alphanumeric code or a vanity slug, 3 to 50 characters long
 func ValidateRoomID(roomID string) bool {
-	// Room IDs are 6 characters long, alphanumeric
-	match, _ := regexp.MatchString("^[a-zA-Z0-9]{6}$", roomID)
-	return match
+	return roomIDRe.MatchString(roomID)
 }
@@ -0,0 +1,11 @@
+// Package migrations embeds the versioned SQL schema migrations for each
+// supported database driver. Files are named NNN_name.up.sql and
+// NNN_name.down.sql and live in a directory named after the driver.
+package migrations
+
+import "embed"
+
+// FS holds the migration files for every driver, rooted at the driver name
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
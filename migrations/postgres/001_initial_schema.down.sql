@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_feedback_sentiment;
+DROP INDEX IF EXISTS idx_feedback_room_id;
+DROP INDEX IF EXISTS idx_rooms_creator_id;
+DROP INDEX IF EXISTS idx_users_email;
+
+DROP TABLE IF EXISTS feedback;
+DROP TABLE IF EXISTS rooms;
+DROP TABLE IF EXISTS users;
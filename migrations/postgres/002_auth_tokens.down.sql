@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_revoked_tokens_expires_at;
+DROP INDEX IF EXISTS idx_refresh_tokens_expires_at;
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
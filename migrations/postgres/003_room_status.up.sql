@@ -0,0 +1,5 @@
+-- Room lifecycle: draft -> open <-> closed -> archived
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'open'
+    CHECK (status IN ('draft', 'open', 'closed', 'archived'));
+
+CREATE INDEX IF NOT EXISTS idx_rooms_status ON rooms(status);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_rooms_closes_at;
+DROP INDEX IF EXISTS idx_rooms_opens_at;
+
+ALTER TABLE rooms DROP COLUMN IF EXISTS closes_at;
+ALTER TABLE rooms DROP COLUMN IF EXISTS opens_at;
@@ -0,0 +1,7 @@
+-- Optional time window during which a room accepts feedback
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS opens_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS closes_at TIMESTAMP WITH TIME ZONE;
+
+-- The scheduler only looks at rooms that are waiting to open or close
+CREATE INDEX IF NOT EXISTS idx_rooms_opens_at ON rooms(opens_at) WHERE status = 'draft' AND opens_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rooms_closes_at ON rooms(closes_at) WHERE status = 'open' AND closes_at IS NOT NULL;
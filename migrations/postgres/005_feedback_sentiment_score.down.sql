@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_feedback_pending;
+
+ALTER TABLE feedback DROP COLUMN IF EXISTS sentiment_score;
@@ -0,0 +1,5 @@
+-- Score from -1 (negative) to 1 (positive) produced by the sentiment analyzer
+ALTER TABLE feedback ADD COLUMN IF NOT EXISTS sentiment_score DOUBLE PRECISION;
+
+-- Backfill scans pending rows in id order
+CREATE INDEX IF NOT EXISTS idx_feedback_pending ON feedback(id) WHERE sentiment = 'pending';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_jobs_finished;
+DROP INDEX IF EXISTS idx_jobs_lease;
+DROP INDEX IF EXISTS idx_jobs_ready;
+
+DROP TABLE IF EXISTS jobs;
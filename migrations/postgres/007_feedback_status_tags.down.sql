@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_feedback_tags_tag;
+DROP INDEX IF EXISTS idx_feedback_room_status;
+DROP INDEX IF EXISTS idx_feedback_room_score;
+DROP INDEX IF EXISTS idx_feedback_room_created;
+
+DROP TABLE IF EXISTS feedback_tags;
+
+ALTER TABLE feedback DROP COLUMN IF EXISTS status;
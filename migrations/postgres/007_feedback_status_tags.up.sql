@@ -0,0 +1,16 @@
+-- Triage status of each feedback entry
+ALTER TABLE feedback ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'new'
+    CHECK (status IN ('new', 'reviewed', 'archived'));
+
+-- Free-form labels attached by the room owner
+CREATE TABLE IF NOT EXISTS feedback_tags (
+    feedback_id INTEGER NOT NULL REFERENCES feedback(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (feedback_id, tag)
+);
+
+-- Keyset pagination of a room's feedback by creation time or sentiment score
+CREATE INDEX IF NOT EXISTS idx_feedback_room_created ON feedback(room_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_feedback_room_score ON feedback(room_id, (COALESCE(sentiment_score, -2)), id);
+CREATE INDEX IF NOT EXISTS idx_feedback_room_status ON feedback(room_id, status);
+CREATE INDEX IF NOT EXISTS idx_feedback_tags_tag ON feedback_tags(tag, feedback_id);
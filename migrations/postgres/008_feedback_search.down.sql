@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_feedback_search;
+
+ALTER TABLE feedback DROP COLUMN IF EXISTS search_vector;
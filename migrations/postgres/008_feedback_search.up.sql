@@ -0,0 +1,5 @@
+-- Full-text search over feedback content, maintained by PostgreSQL
+ALTER TABLE feedback ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', content)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_feedback_search ON feedback USING GIN (search_vector);
@@ -0,0 +1,7 @@
+-- When each user last looked at a room's feedback, for unread counts
+CREATE TABLE IF NOT EXISTS room_visits (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    room_id VARCHAR(50) NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    visited_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, room_id)
+);
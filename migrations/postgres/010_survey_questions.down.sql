@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_answers_question;
+DROP INDEX IF EXISTS idx_questions_room;
+
+DROP TABLE IF EXISTS answers;
+DROP TABLE IF EXISTS questions;
@@ -0,0 +1,21 @@
+DROP INDEX IF EXISTS idx_action_items_room;
+DROP INDEX IF EXISTS idx_votes_participant;
+DROP INDEX IF EXISTS idx_feedback_group;
+DROP INDEX IF EXISTS idx_feedback_column;
+DROP INDEX IF EXISTS idx_card_groups_room;
+DROP INDEX IF EXISTS idx_retro_columns_room;
+
+DROP TABLE IF EXISTS action_items;
+DROP TABLE IF EXISTS votes;
+
+ALTER TABLE feedback DROP COLUMN IF EXISTS votes;
+ALTER TABLE feedback DROP COLUMN IF EXISTS participant_id;
+ALTER TABLE feedback DROP COLUMN IF EXISTS group_id;
+ALTER TABLE feedback DROP COLUMN IF EXISTS column_id;
+
+DROP TABLE IF EXISTS card_groups;
+DROP TABLE IF EXISTS retro_columns;
+
+ALTER TABLE rooms DROP COLUMN IF EXISTS votes_per_participant;
+ALTER TABLE rooms DROP COLUMN IF EXISTS phase;
+ALTER TABLE rooms DROP COLUMN IF EXISTS type;
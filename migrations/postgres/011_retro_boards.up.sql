@@ -0,0 +1,57 @@
+-- Room types: standard feedback rooms and retro boards. The type is checked by
+-- the application so that new types do not need the constraint rebuilt.
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT 'standard';
+-- Retro rooms only: the facilitator-controlled phase and each participant's dot budget
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS phase VARCHAR(20);
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS votes_per_participant INTEGER;
+
+-- Ordered columns of a retro board
+CREATE TABLE IF NOT EXISTS retro_columns (
+    id SERIAL PRIMARY KEY,
+    room_id VARCHAR(50) NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Groups of similar cards formed by the facilitator
+CREATE TABLE IF NOT EXISTS card_groups (
+    id SERIAL PRIMARY KEY,
+    room_id VARCHAR(50) NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    title VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Cards of retro boards: their column, group and author. Votes caches the sum
+-- of the entry's votes.
+ALTER TABLE feedback ADD COLUMN IF NOT EXISTS column_id INTEGER REFERENCES retro_columns(id) ON DELETE SET NULL;
+ALTER TABLE feedback ADD COLUMN IF NOT EXISTS group_id INTEGER REFERENCES card_groups(id) ON DELETE SET NULL;
+ALTER TABLE feedback ADD COLUMN IF NOT EXISTS participant_id VARCHAR(64);
+ALTER TABLE feedback ADD COLUMN IF NOT EXISTS votes INTEGER NOT NULL DEFAULT 0;
+
+-- Votes of participants on feedback entries; count is the number of dots
+CREATE TABLE IF NOT EXISTS votes (
+    feedback_id INTEGER NOT NULL REFERENCES feedback(id) ON DELETE CASCADE,
+    participant_id VARCHAR(64) NOT NULL,
+    count INTEGER NOT NULL CHECK (count > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (feedback_id, participant_id)
+);
+
+-- Follow-ups agreed on in a retro, optionally linked to the card they address
+CREATE TABLE IF NOT EXISTS action_items (
+    id SERIAL PRIMARY KEY,
+    room_id VARCHAR(50) NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    feedback_id INTEGER REFERENCES feedback(id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    owner VARCHAR(100) NOT NULL DEFAULT '',
+    done BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_retro_columns_room ON retro_columns(room_id, position);
+CREATE INDEX IF NOT EXISTS idx_card_groups_room ON card_groups(room_id);
+CREATE INDEX IF NOT EXISTS idx_feedback_column ON feedback(column_id);
+CREATE INDEX IF NOT EXISTS idx_feedback_group ON feedback(group_id);
+CREATE INDEX IF NOT EXISTS idx_votes_participant ON votes(participant_id, feedback_id);
+CREATE INDEX IF NOT EXISTS idx_action_items_room ON action_items(room_id, id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_feedback_room_votes;
+
+ALTER TABLE feedback DROP COLUMN IF EXISTS pinned;
+ALTER TABLE feedback DROP COLUMN IF EXISTS qa_state;
+
+ALTER TABLE rooms DROP COLUMN IF EXISTS moderated;
@@ -0,0 +1,9 @@
+-- Q&A rooms only: whether new questions wait for the owner's approval
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS moderated BOOLEAN NOT NULL DEFAULT false;
+
+-- Questions of Q&A rooms: their moderation state and whether the owner pinned
+-- them to the top of the feed. Other feedback has no state.
+ALTER TABLE feedback ADD COLUMN IF NOT EXISTS qa_state VARCHAR(20);
+ALTER TABLE feedback ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_feedback_room_votes ON feedback(room_id, votes, id);
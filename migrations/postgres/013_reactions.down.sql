@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_reactions_participant;
+
+DROP TABLE IF EXISTS reactions;
+
+ALTER TABLE rooms DROP COLUMN IF EXISTS reactions;
@@ -0,0 +1,33 @@
+-- User table
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    subscription_type VARCHAR(50) DEFAULT 'free' NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Room table
+CREATE TABLE IF NOT EXISTS rooms (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    password VARCHAR(255),
+    creator_id INTEGER REFERENCES users(id),
+    is_password_protected BOOLEAN DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Feedback table
+CREATE TABLE IF NOT EXISTS feedback (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id VARCHAR(50) REFERENCES rooms(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    sentiment VARCHAR(50) DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_rooms_creator_id ON rooms(creator_id);
+CREATE INDEX IF NOT EXISTS idx_feedback_room_id ON feedback(room_id);
+CREATE INDEX IF NOT EXISTS idx_feedback_sentiment ON feedback(sentiment);
@@ -0,0 +1,7 @@
+-- Optional time window during which a room accepts feedback
+ALTER TABLE rooms ADD COLUMN opens_at TIMESTAMP;
+ALTER TABLE rooms ADD COLUMN closes_at TIMESTAMP;
+
+-- The scheduler only looks at rooms that are waiting to open or close
+CREATE INDEX IF NOT EXISTS idx_rooms_opens_at ON rooms(opens_at) WHERE status = 'draft' AND opens_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rooms_closes_at ON rooms(closes_at) WHERE status = 'open' AND closes_at IS NOT NULL;
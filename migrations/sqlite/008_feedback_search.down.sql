@@ -0,0 +1,2 @@
+-- Nothing to undo; see 008_feedback_search.up.sql
+SELECT 1;
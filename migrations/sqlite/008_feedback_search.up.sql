@@ -0,0 +1,5 @@
+-- Full-text search uses an FTS5 index, which is only available in binaries built
+-- with `-tags sqlite_fts5`. The index is therefore created by the store when it
+-- is opened by such a binary (see internal/db/search_fts5.go) rather than here;
+-- other builds fall back to scanning. This migration keeps versions aligned.
+SELECT 1;
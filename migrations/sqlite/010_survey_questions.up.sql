@@ -0,0 +1,24 @@
+-- Optional ordered questions of survey rooms. Options lists the choices of
+-- single_choice and multiple_choice questions as a JSON array.
+CREATE TABLE IF NOT EXISTS questions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id VARCHAR(50) NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    type VARCHAR(20) NOT NULL
+        CHECK (type IN ('text', 'rating', 'nps', 'single_choice', 'multiple_choice', 'yes_no')),
+    prompt TEXT NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT false,
+    options TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Typed answers of a submission, stored as JSON values validated against the question
+CREATE TABLE IF NOT EXISTS answers (
+    feedback_id INTEGER NOT NULL REFERENCES feedback(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    PRIMARY KEY (feedback_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_questions_room ON questions(room_id, position);
+CREATE INDEX IF NOT EXISTS idx_answers_question ON answers(question_id);
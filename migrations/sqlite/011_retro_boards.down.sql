@@ -0,0 +1,21 @@
+DROP INDEX IF EXISTS idx_action_items_room;
+DROP INDEX IF EXISTS idx_votes_participant;
+DROP INDEX IF EXISTS idx_feedback_group;
+DROP INDEX IF EXISTS idx_feedback_column;
+DROP INDEX IF EXISTS idx_card_groups_room;
+DROP INDEX IF EXISTS idx_retro_columns_room;
+
+DROP TABLE IF EXISTS action_items;
+DROP TABLE IF EXISTS votes;
+
+ALTER TABLE feedback DROP COLUMN votes;
+ALTER TABLE feedback DROP COLUMN participant_id;
+ALTER TABLE feedback DROP COLUMN group_id;
+ALTER TABLE feedback DROP COLUMN column_id;
+
+DROP TABLE IF EXISTS card_groups;
+DROP TABLE IF EXISTS retro_columns;
+
+ALTER TABLE rooms DROP COLUMN votes_per_participant;
+ALTER TABLE rooms DROP COLUMN phase;
+ALTER TABLE rooms DROP COLUMN type;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_feedback_room_votes;
+
+ALTER TABLE feedback DROP COLUMN pinned;
+ALTER TABLE feedback DROP COLUMN qa_state;
+
+ALTER TABLE rooms DROP COLUMN moderated;
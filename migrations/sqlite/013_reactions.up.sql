@@ -0,0 +1,14 @@
+-- Emoji offered for reactions, comma-separated; NULL offers the default set
+ALTER TABLE rooms ADD COLUMN reactions TEXT;
+
+-- Reactions of participants to feedback entries, one row per participant and
+-- reaction so that each participant counts once
+CREATE TABLE IF NOT EXISTS reactions (
+    feedback_id INTEGER NOT NULL REFERENCES feedback(id) ON DELETE CASCADE,
+    participant_id VARCHAR(64) NOT NULL,
+    reaction VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (feedback_id, participant_id, reaction)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reactions_participant ON reactions(participant_id, feedback_id);
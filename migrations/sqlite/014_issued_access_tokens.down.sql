@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_issued_access_tokens_expires_at;
+DROP INDEX IF EXISTS idx_issued_access_tokens_family_id;
+
+DROP TABLE IF EXISTS issued_access_tokens;
@@ -0,0 +1,10 @@
+-- Access token IDs (jti) issued to each refresh token family, so that revoking
+-- a family after refresh token reuse also denylists its access tokens
+CREATE TABLE IF NOT EXISTS issued_access_tokens (
+    jti VARCHAR(36) PRIMARY KEY,
+    family_id VARCHAR(36) NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_issued_access_tokens_family_id ON issued_access_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_issued_access_tokens_expires_at ON issued_access_tokens(expires_at);